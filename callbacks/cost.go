@@ -0,0 +1,358 @@
+package callbacks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// ErrBudgetExceeded is returned, and is the cause of the cancellation of the
+// context of a run, when the run costs more than its budget.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+const (
+	_tokensPerPrice = 1000
+	_unknownModel   = "unknown"
+)
+
+// ModelPrice is the price of the tokens of a model in USD.
+type ModelPrice struct {
+	InputPer1KTokens  float64 `json:"input_per_1k_tokens"`
+	OutputPer1KTokens float64 `json:"output_per_1k_tokens"`
+}
+
+// PricingTable maps model names to their prices.
+type PricingTable map[string]ModelPrice
+
+// Price returns the price of the model, or of the longest model name of the
+// table it starts with. Models not in the table are priced with the costs
+// registered with llms.RegisterModel.
+func (t PricingTable) Price(model string) (ModelPrice, bool) {
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+	var (
+		match  ModelPrice
+		length int
+	)
+	for prefix, price := range t {
+		if prefix != "" && strings.HasPrefix(model, prefix) && len(prefix) > length {
+			match, length = price, len(prefix)
+		}
+	}
+	if length > 0 {
+		return match, true
+	}
+	info, ok := llms.LookupModel(model)
+	if !ok || (info.InputCostPer1KTokens == 0 && info.OutputCostPer1KTokens == 0) {
+		return ModelPrice{}, false
+	}
+	return ModelPrice{
+		InputPer1KTokens:  info.InputCostPer1KTokens,
+		OutputPer1KTokens: info.OutputCostPer1KTokens,
+	}, true
+}
+
+// LoadPricingTable reads a JSON object mapping model names to their prices.
+func LoadPricingTable(r io.Reader) (PricingTable, error) {
+	var t PricingTable
+	if err := json.NewDecoder(r).Decode(&t); err != nil {
+		return nil, fmt.Errorf("decode pricing table: %w", err)
+	}
+	return t, nil
+}
+
+// Cost is the token usage of LLM calls and its cost.
+type Cost struct {
+	Calls            int `json:"calls"`
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+	// USD is the cost of the tokens of the priced models.
+	USD float64 `json:"usd"`
+	// UnpricedTokens is the number of tokens of models without a price.
+	UnpricedTokens int `json:"unpriced_tokens,omitempty"`
+	// Estimated is true if any token count was estimated.
+	Estimated bool `json:"estimated,omitempty"`
+}
+
+func (c Cost) add(other Cost) Cost {
+	return Cost{
+		Calls:            c.Calls + other.Calls,
+		PromptTokens:     c.PromptTokens + other.PromptTokens,
+		CompletionTokens: c.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      c.TotalTokens + other.TotalTokens,
+		USD:              c.USD + other.USD,
+		UnpricedTokens:   c.UnpricedTokens + other.UnpricedTokens,
+		Estimated:        c.Estimated || other.Estimated,
+	}
+}
+
+// CostReport is the cost of the LLM calls in total, per model, per run and
+// per tag.
+type CostReport struct {
+	Total  Cost            `json:"total"`
+	Models map[string]Cost `json:"models"`
+	Runs   map[string]Cost `json:"runs"`
+	Tags   map[string]Cost `json:"tags"`
+}
+
+func newCostReport() CostReport {
+	return CostReport{
+		Models: make(map[string]Cost),
+		Runs:   make(map[string]Cost),
+		Tags:   make(map[string]Cost),
+	}
+}
+
+func (r CostReport) clone() CostReport {
+	c := newCostReport()
+	c.Total = r.Total
+	for k, v := range r.Models {
+		c.Models[k] = v
+	}
+	for k, v := range r.Runs {
+		c.Runs[k] = v
+	}
+	for k, v := range r.Tags {
+		c.Tags[k] = v
+	}
+	return c
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r CostReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+type runIDKey struct{}
+
+type tagsKey struct{}
+
+// WithRunID returns a context whose LLM calls are accounted to the run.
+func WithRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runIDKey{}, runID)
+}
+
+// RunIDFromContext returns the run of the context, if any.
+func RunIDFromContext(ctx context.Context) (string, bool) {
+	runID, ok := ctx.Value(runIDKey{}).(string)
+	return runID, ok
+}
+
+// WithTags returns a context whose LLM calls are accounted to the tags, such
+// as a tenant ID, in addition to the tags of the parent context.
+func WithTags(ctx context.Context, tags ...string) context.Context {
+	parent := TagsFromContext(ctx)
+	merged := make([]string, 0, len(parent)+len(tags))
+	merged = append(merged, parent...)
+	merged = append(merged, tags...)
+	return context.WithValue(ctx, tagsKey{}, merged)
+}
+
+// TagsFromContext returns the tags of the context.
+func TagsFromContext(ctx context.Context) []string {
+	tags, _ := ctx.Value(tagsKey{}).([]string)
+	return tags
+}
+
+type budget struct {
+	limit  float64
+	cancel context.CancelCauseFunc
+	err    error
+}
+
+// CostHandler is a callback handler that accounts for the tokens and the cost
+// of the LLM calls, per model, per run and per tag of their context. It can
+// enforce a budget per run. It is safe for concurrent use.
+type CostHandler struct {
+	pricing PricingTable
+
+	mu      sync.Mutex
+	report  CostReport
+	budgets map[string]*budget
+}
+
+var _ Handler = (*CostHandler)(nil)
+
+// CostHandlerOption is a function that configures a CostHandler.
+type CostHandlerOption func(*CostHandler)
+
+// WithPricingTable sets the prices of the models, which take precedence over
+// the costs registered with llms.RegisterModel.
+func WithPricingTable(t PricingTable) CostHandlerOption {
+	return func(h *CostHandler) {
+		h.pricing = t
+	}
+}
+
+// NewCostHandler returns a new cost handler.
+func NewCostHandler(opts ...CostHandlerOption) *CostHandler {
+	h := &CostHandler{
+		report:  newCostReport(),
+		budgets: make(map[string]*budget),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// WithBudget returns a context of the run that is canceled, with
+// ErrBudgetExceeded as its cause, once the LLM calls of the run cost more
+// than limit USD. The cancel function must be called to release the budget.
+func (h *CostHandler) WithBudget(ctx context.Context, runID string, limit float64) (context.Context, context.CancelFunc) { // nolint:lll
+	ctx, cancel := context.WithCancelCause(WithRunID(ctx, runID))
+	b := &budget{limit: limit, cancel: cancel}
+
+	h.mu.Lock()
+	h.budgets[runID] = b
+	h.checkBudget(runID, b)
+	h.mu.Unlock()
+
+	return ctx, func() {
+		h.mu.Lock()
+		if h.budgets[runID] == b {
+			delete(h.budgets, runID)
+		}
+		h.mu.Unlock()
+		cancel(context.Canceled)
+	}
+}
+
+// CheckBudget returns an error wrapping ErrBudgetExceeded if the run of the
+// context costs more than its budget.
+func (h *CostHandler) CheckBudget(ctx context.Context) error {
+	runID, ok := RunIDFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if b, ok := h.budgets[runID]; ok {
+		return b.err
+	}
+	return nil
+}
+
+// checkBudget cancels the run if it costs more than its budget. It must be
+// called with the lock held.
+func (h *CostHandler) checkBudget(runID string, b *budget) {
+	spent := h.report.Runs[runID].USD
+	if b.err != nil || spent <= b.limit {
+		return
+	}
+	b.err = fmt.Errorf("%w: run %q cost $%.6f of $%.6f", ErrBudgetExceeded, runID, spent, b.limit)
+	b.cancel(b.err)
+}
+
+// Report returns the costs accounted so far.
+func (h *CostHandler) Report() CostReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.report.clone()
+}
+
+// WriteJSON writes the costs accounted so far as indented JSON.
+func (h *CostHandler) WriteJSON(w io.Writer) error {
+	return h.Report().WriteJSON(w)
+}
+
+// Reset discards the costs accounted so far. Budgets are kept, and apply to
+// the costs accounted after the reset.
+func (h *CostHandler) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.report = newCostReport()
+}
+
+// cost returns the cost of the usage, and the model it is accounted to.
+func (h *CostHandler) cost(u llms.Usage) (string, Cost) {
+	c := Cost{
+		Calls:            1,
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+		Estimated:        u.Estimated,
+	}
+	model := u.Model
+	if model == "" {
+		model = _unknownModel
+	}
+	price, ok := h.pricing.Price(u.Model)
+	if !ok {
+		c.UnpricedTokens = u.TotalTokens
+		return model, c
+	}
+	c.USD = (float64(u.PromptTokens)*price.InputPer1KTokens +
+		float64(u.CompletionTokens)*price.OutputPer1KTokens) / _tokensPerPrice
+	return model, c
+}
+
+// usages returns the usages of the generations of the result, or its total
+// usage if the generations have none.
+func usages(result llms.LLMResult) []llms.Usage {
+	var us []llms.Usage
+	for _, generations := range result.Generations {
+		for _, g := range generations {
+			if u, ok := llms.GetUsage(g); ok {
+				us = append(us, u)
+			}
+		}
+	}
+	if len(us) == 0 {
+		if u, ok := result.LLMOutput[llms.UsageKey].(llms.Usage); ok {
+			us = append(us, u)
+		}
+	}
+	return us
+}
+
+func (h *CostHandler) HandleLLMEnd(ctx context.Context, result llms.LLMResult) {
+	runID, hasRun := RunIDFromContext(ctx)
+	tags := TagsFromContext(ctx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, u := range usages(result) {
+		model, c := h.cost(u)
+		h.report.Total = h.report.Total.add(c)
+		h.report.Models[model] = h.report.Models[model].add(c)
+		if hasRun {
+			h.report.Runs[runID] = h.report.Runs[runID].add(c)
+		}
+		for _, tag := range tags {
+			h.report.Tags[tag] = h.report.Tags[tag].add(c)
+		}
+	}
+	if b, ok := h.budgets[runID]; hasRun && ok {
+		h.checkBudget(runID, b)
+	}
+}
+
+func (h *CostHandler) HandleText(context.Context, string) {}
+
+func (h *CostHandler) HandleLLMStart(context.Context, []string) {}
+
+func (h *CostHandler) HandleChainStart(context.Context, map[string]any) {}
+
+func (h *CostHandler) HandleChainEnd(context.Context, map[string]any) {}
+
+func (h *CostHandler) HandleToolStart(context.Context, string) {}
+
+func (h *CostHandler) HandleToolEnd(context.Context, string) {}
+
+func (h *CostHandler) HandleAgentAction(context.Context, schema.AgentAction) {}
+
+func (h *CostHandler) HandleRetrieverStart(context.Context, string) {}
+
+func (h *CostHandler) HandleRetrieverEnd(context.Context, string, []schema.Document) {}
@@ -0,0 +1,97 @@
+package callbacks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func usageResult(usages ...llms.Usage) llms.LLMResult {
+	generations := make([]*llms.Generation, 0, len(usages))
+	for _, u := range usages {
+		g := &llms.Generation{}
+		llms.SetUsage(g, u)
+		generations = append(generations, g)
+	}
+	return llms.NewLLMResult(generations)
+}
+
+func TestCostHandler(t *testing.T) {
+	t.Parallel()
+
+	h := NewCostHandler(WithPricingTable(PricingTable{
+		"my-model": {InputPer1KTokens: 1, OutputPer1KTokens: 2},
+	}))
+	ctx := WithTags(WithRunID(context.Background(), "run-1"), "tenant:acme")
+	ctx = WithTags(ctx, "team:search")
+
+	h.HandleLLMEnd(ctx, usageResult(
+		llms.Usage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500, Model: "my-model-v2"},
+		llms.Usage{PromptTokens: 1000, CompletionTokens: 1000, TotalTokens: 2000, Model: "gpt-4"},
+	))
+	h.HandleLLMEnd(context.Background(), usageResult(llms.Usage{TotalTokens: 10, Estimated: true}))
+
+	report := h.Report()
+	assert.InDelta(t, 2.0, report.Models["my-model-v2"].USD, 1e-9)
+	// The price of gpt-4 comes from the model registry.
+	assert.InDelta(t, 0.09, report.Models["gpt-4"].USD, 1e-9)
+	assert.Equal(t, 10, report.Models["unknown"].UnpricedTokens)
+
+	assert.Equal(t, Cost{Calls: 3, PromptTokens: 2000, CompletionTokens: 1500, TotalTokens: 3510,
+		USD: report.Total.USD, UnpricedTokens: 10, Estimated: true}, report.Total)
+	assert.InDelta(t, 2.09, report.Total.USD, 1e-9)
+	assert.Equal(t, 2, report.Runs["run-1"].Calls)
+	assert.Equal(t, 3500, report.Tags["tenant:acme"].TotalTokens)
+	assert.Equal(t, 3500, report.Tags["team:search"].TotalTokens)
+
+	var buf bytes.Buffer
+	require.NoError(t, h.WriteJSON(&buf))
+	var decoded CostReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, report, decoded)
+
+	h.Reset()
+	assert.Equal(t, 0, h.Report().Total.Calls)
+}
+
+func TestCostHandlerBudget(t *testing.T) {
+	t.Parallel()
+
+	h := NewCostHandler(WithPricingTable(PricingTable{"my-model": {InputPer1KTokens: 1}}))
+	ctx, cancel := h.WithBudget(context.Background(), "run-1", 1.5)
+	defer cancel()
+
+	h.HandleLLMEnd(ctx, usageResult(llms.Usage{PromptTokens: 1000, TotalTokens: 1000, Model: "my-model"}))
+	require.NoError(t, ctx.Err())
+	require.NoError(t, h.CheckBudget(ctx))
+
+	h.HandleLLMEnd(ctx, usageResult(llms.Usage{PromptTokens: 1000, TotalTokens: 1000, Model: "my-model"}))
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+	require.ErrorIs(t, context.Cause(ctx), ErrBudgetExceeded)
+	require.ErrorIs(t, h.CheckBudget(ctx), ErrBudgetExceeded)
+
+	// Other runs are not affected.
+	other, cancelOther := h.WithBudget(context.Background(), "run-2", 1.5)
+	defer cancelOther()
+	require.NoError(t, other.Err())
+	require.NoError(t, h.CheckBudget(other))
+}
+
+func TestLoadPricingTable(t *testing.T) {
+	t.Parallel()
+
+	table, err := LoadPricingTable(strings.NewReader(`{"my-model": {"input_per_1k_tokens": 0.5}}`))
+	require.NoError(t, err)
+	price, ok := table.Price("my-model-16k")
+	require.True(t, ok)
+	assert.InDelta(t, 0.5, price.InputPer1KTokens, 1e-9)
+
+	_, ok = table.Price("no-such-model")
+	assert.False(t, ok)
+}
@@ -1,4 +1,5 @@
 // Package callbacks includes a standard interface for hooking into various
 // stages of your LLM application. The package contains an implementation of
-// this interface that prints to the standard output.
+// this interface that prints to the standard output, and one that accounts for
+// the tokens and the cost of the LLM calls.
 package callbacks
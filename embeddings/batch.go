@@ -0,0 +1,270 @@
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"unicode"
+
+	"github.com/tmc/langchaingo/internal/parallel"
+)
+
+// ErrUnexpectedResponseLength is returned when an embedding API returns a
+// different number of vectors than it was asked for.
+var ErrUnexpectedResponseLength = errors.New("unexpected length of response")
+
+// EmbedFunc embeds a batch of texts, returning a vector for each text.
+type EmbedFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+// BatchOption is a function that configures how EmbedBatches splits the texts
+// in batches.
+type BatchOption func(*batchOptions)
+
+type batchOptions struct {
+	batchSize      int
+	maxTokens      int
+	maxRunes       int
+	maxBatchTokens int
+	maxConcurrency int
+	countTokens    func(text string) int
+}
+
+// WithBatchSize sets the maximum number of texts of a batch. If not set, the
+// number of texts of a batch is unlimited.
+func WithBatchSize(batchSize int) BatchOption {
+	return func(o *batchOptions) {
+		o.batchSize = batchSize
+	}
+}
+
+// WithMaxTokens sets the maximum number of tokens of a text, usually the
+// maximum input length of the model. Longer texts are split in parts that are
+// embedded separately, and their vector is the average of the vectors of the
+// parts, weighted by their number of tokens. If not set, texts are not split.
+func WithMaxTokens(maxTokens int) BatchOption {
+	return func(o *batchOptions) {
+		o.maxTokens = maxTokens
+	}
+}
+
+// WithMaxRunes sets the maximum number of characters of a text, counted in
+// runes. Longer texts are split as with WithMaxTokens. If not set, texts are
+// not split by their number of characters.
+func WithMaxRunes(maxRunes int) BatchOption {
+	return func(o *batchOptions) {
+		o.maxRunes = maxRunes
+	}
+}
+
+// WithMaxBatchTokens sets the maximum number of tokens of a batch. Texts
+// longer than a batch are split as with WithMaxTokens. If not set, the number
+// of tokens of a batch is unlimited.
+func WithMaxBatchTokens(maxBatchTokens int) BatchOption {
+	return func(o *batchOptions) {
+		o.maxBatchTokens = maxBatchTokens
+	}
+}
+
+// WithMaxConcurrency sets the maximum number of batches embedded
+// concurrently. The default is 1.
+func WithMaxConcurrency(maxConcurrency int) BatchOption {
+	return func(o *batchOptions) {
+		o.maxConcurrency = maxConcurrency
+	}
+}
+
+// WithTokenCounter sets the function counting the tokens of a text. The
+// default counts every rune as a token, which overestimates the tokens of
+// most tokenizers, so that texts are split too early rather than too late.
+func WithTokenCounter(countTokens func(text string) int) BatchOption {
+	return func(o *batchOptions) {
+		o.countTokens = countTokens
+	}
+}
+
+func countRunes(text string) int {
+	return len([]rune(text))
+}
+
+// part is a text, or a part of a text too long to be embedded at once.
+type part struct {
+	text   string
+	tokens int
+	// index is the index of the text the part belongs to.
+	index int
+}
+
+// EmbedBatches embeds the texts with embed, in batches of consecutive texts
+// limited by the options, and returns a vector for each text, in order.
+// Batches are embedded concurrently, up to the maximum concurrency. On the
+// first error, the context of the other batches is canceled and the error is
+// returned.
+func EmbedBatches(ctx context.Context, texts []string, embed EmbedFunc, opts ...BatchOption) ([][]float32, error) {
+	o := batchOptions{
+		maxConcurrency: 1,
+		countTokens:    countRunes,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	parts := o.split(texts)
+	batches := o.batch(parts)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		once     sync.Once
+		firstErr error
+	)
+	vectors := make([][]float32, len(parts))
+	err := parallel.Run(ctx, len(batches), o.maxConcurrency, func(ctx context.Context, b int) error {
+		batch := parts[batches[b]:]
+		if b+1 < len(batches) {
+			batch = parts[batches[b]:batches[b+1]]
+		}
+		batchTexts := make([]string, 0, len(batch))
+		for _, p := range batch {
+			batchTexts = append(batchTexts, p.text)
+		}
+
+		batchVectors, err := embed(ctx, batchTexts)
+		if err == nil && len(batchVectors) != len(batchTexts) {
+			err = ErrUnexpectedResponseLength
+		}
+		if err != nil {
+			once.Do(func() {
+				firstErr = err
+				cancel()
+			})
+			return err
+		}
+		copy(vectors[batches[b]:], batchVectors)
+		return nil
+	})
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return combineParts(len(texts), parts, vectors)
+}
+
+// limit returns the maximum number of tokens of a part, or 0 if unlimited.
+func (o batchOptions) limit() int {
+	limit := o.maxTokens
+	if o.maxBatchTokens > 0 && (limit <= 0 || o.maxBatchTokens < limit) {
+		limit = o.maxBatchTokens
+	}
+	return limit
+}
+
+// split returns the parts of the texts, splitting the texts longer than the
+// limit.
+func (o batchOptions) split(texts []string) []part {
+	limit := o.limit()
+	parts := make([]part, 0, len(texts))
+	for i, text := range texts {
+		p := part{text: text, index: i}
+		if limit <= 0 && o.maxRunes <= 0 {
+			// Tokens are only counted if limited.
+			parts = append(parts, p)
+			continue
+		}
+		p.tokens = o.countTokens(text)
+		parts = o.splitPart(parts, p, limit)
+	}
+	return parts
+}
+
+// splitPart appends the part to parts, halved until its halves fit the limit
+// and the maximum number of runes. A part of a single rune is never split.
+func (o batchOptions) splitPart(parts []part, p part, limit int) []part {
+	runes := []rune(p.text)
+	fits := (limit <= 0 || p.tokens <= limit) && (o.maxRunes <= 0 || len(runes) <= o.maxRunes)
+	if fits || len(runes) < 2 {
+		return append(parts, p)
+	}
+	cut := halve(runes)
+	left := part{text: string(runes[:cut]), index: p.index}
+	right := part{text: string(runes[cut:]), index: p.index}
+	left.tokens = o.countTokens(left.text)
+	right.tokens = o.countTokens(right.text)
+	parts = o.splitPart(parts, left, limit)
+	return o.splitPart(parts, right, limit)
+}
+
+// halve returns the index of the rune the text is cut at: after the space
+// closest to the middle, if in the middle half of the text, or else the
+// middle.
+func halve(runes []rune) int {
+	mid := len(runes) / 2
+	for d := 0; d <= len(runes)/4; d++ {
+		if i := mid + d; i < len(runes) && unicode.IsSpace(runes[i-1]) {
+			return i
+		}
+		if i := mid - d; i > 0 && unicode.IsSpace(runes[i-1]) {
+			return i
+		}
+	}
+	return mid
+}
+
+// batch returns the index of the first part of each batch.
+func (o batchOptions) batch(parts []part) []int {
+	var (
+		starts []int
+		size   int
+		tokens int
+	)
+	for i, p := range parts {
+		full := o.batchSize > 0 && size >= o.batchSize
+		if o.maxBatchTokens > 0 && tokens+p.tokens > o.maxBatchTokens {
+			full = true
+		}
+		if i == 0 || full {
+			starts = append(starts, i)
+			size, tokens = 0, 0
+		}
+		size++
+		tokens += p.tokens
+	}
+	return starts
+}
+
+// combineParts returns the vector of each text, combining the vectors of
+// the parts of the texts that were split.
+func combineParts(n int, parts []part, vectors [][]float32) ([][]float32, error) {
+	emb := make([][]float32, n)
+	for start := 0; start < len(parts); {
+		end := start + 1
+		for end < len(parts) && parts[end].index == parts[start].index {
+			end++
+		}
+		if end-start == 1 {
+			emb[parts[start].index] = vectors[start]
+			start = end
+			continue
+		}
+
+		weights := make([]int, 0, end-start)
+		for _, p := range parts[start:end] {
+			// Every part weighs at least one, so that the weights never sum
+			// to zero.
+			weight := p.tokens
+			if weight < 1 {
+				weight = 1
+			}
+			weights = append(weights, weight)
+		}
+		combined, err := CombineVectors(vectors[start:end], weights)
+		if err != nil {
+			return nil, err
+		}
+		emb[parts[start].index] = combined
+		start = end
+	}
+	return emb, nil
+}
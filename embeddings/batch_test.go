@@ -0,0 +1,110 @@
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is an EmbedFunc embedding each text as its length, recording the
+// batches.
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (r *recorder) embed(_ context.Context, texts []string) ([][]float32, error) {
+	r.mu.Lock()
+	r.batches = append(r.batches, texts)
+	r.mu.Unlock()
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		vectors = append(vectors, []float32{float32(len(text)), 1})
+	}
+	return vectors, nil
+}
+
+func TestEmbedBatches(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	r := &recorder{}
+	texts := []string{"a", "bb", "ccc", "dddd", "eeeee"}
+	vectors, err := EmbedBatches(ctx, texts, r.embed, WithBatchSize(2), WithMaxConcurrency(3))
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 1}, {2, 1}, {3, 1}, {4, 1}, {5, 1}}, vectors)
+	assert.ElementsMatch(t, [][]string{{"a", "bb"}, {"ccc", "dddd"}, {"eeeee"}}, r.batches)
+
+	r = &recorder{}
+	_, err = EmbedBatches(ctx, texts, r.embed, WithMaxBatchTokens(6))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "bb", "ccc"}, {"dddd"}, {"eeeee"}}, r.batches)
+
+	r = &recorder{}
+	vectors, err = EmbedBatches(ctx, nil, r.embed)
+	require.NoError(t, err)
+	assert.Empty(t, vectors)
+	assert.Empty(t, r.batches)
+}
+
+func TestEmbedBatchesSplitsLongTexts(t *testing.T) {
+	t.Parallel()
+
+	r := &recorder{}
+	countWords := func(text string) int { return len(strings.Fields(text)) }
+	vectors, err := EmbedBatches(context.Background(), []string{"one two three four five", "six"}, r.embed,
+		WithMaxTokens(2), WithTokenCounter(countWords))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"one two ", "three ", "four five", "six"}}, r.batches)
+	require.Len(t, vectors, 2)
+	// The vector of the long text is the normalized average of the vectors
+	// of its parts, weighted by their number of tokens.
+	want, err := CombineVectors([][]float32{{8, 1}, {6, 1}, {9, 1}}, []int{2, 1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, want, vectors[0])
+	assert.Equal(t, []float32{3, 1}, vectors[1])
+}
+
+func TestEmbedBatchesSplitsByRunes(t *testing.T) {
+	t.Parallel()
+
+	r := &recorder{}
+	vectors, err := EmbedBatches(context.Background(), []string{"ab cd ef", "g"}, r.embed,
+		WithMaxRunes(3), WithMaxTokens(100))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"ab ", "cd ", "ef", "g"}}, r.batches)
+	require.Len(t, vectors, 2)
+}
+
+func TestEmbedBatchesCancelsOnError(t *testing.T) {
+	t.Parallel()
+
+	errEmbed := errors.New("embed error")
+	started := make(chan struct{})
+	var canceled bool
+	embed := func(ctx context.Context, texts []string) ([][]float32, error) {
+		if texts[0] == "fail" {
+			<-started
+			return nil, errEmbed
+		}
+		close(started)
+		<-ctx.Done()
+		canceled = true
+		return nil, ctx.Err()
+	}
+	_, err := EmbedBatches(context.Background(), []string{"fail", "wait", "never"}, embed,
+		WithBatchSize(1), WithMaxConcurrency(2))
+	require.ErrorIs(t, err, errEmbed)
+	assert.True(t, canceled)
+
+	short := func(context.Context, []string) ([][]float32, error) {
+		return [][]float32{{1}}, nil
+	}
+	_, err = EmbedBatches(context.Background(), []string{"a", "b"}, short)
+	require.ErrorIs(t, err, ErrUnexpectedResponseLength)
+}
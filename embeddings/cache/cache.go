@@ -0,0 +1,189 @@
+// Package cache provides an embedder that caches the vectors of another
+// embedder, so that unchanged texts are not embedded again.
+//
+// Vectors are stored in a llms/cache.Cache, such as the in-memory LRU cache
+// or the file cache of that package, keyed by the name of the model and the
+// SHA-256 hash of the text.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/tmc/langchaingo/embeddings"
+	llmcache "github.com/tmc/langchaingo/llms/cache"
+)
+
+var (
+	// ErrUnexpectedResponseLength is returned when the wrapped embedder
+	// returns a different number of vectors than it was asked for.
+	ErrUnexpectedResponseLength = errors.New("unexpected length of response")
+	// ErrInvalidEntry is returned when a cached vector cannot be decoded.
+	ErrInvalidEntry = errors.New("invalid cache entry")
+)
+
+const (
+	_kindDocument = "document"
+	_kindQuery    = "query"
+)
+
+// Embedder is an embedder that caches the vectors of another embedder. It
+// is safe for concurrent use if the wrapped embedder and cache are.
+type Embedder struct {
+	embedder embeddings.Embedder
+	cache    llmcache.Cache
+	model    string
+	ttl      time.Duration
+}
+
+var _ embeddings.Embedder = (*Embedder)(nil)
+
+// Option is a function that configures an Embedder.
+type Option func(*Embedder)
+
+// WithTTL sets how long cached vectors are valid. If not set, cached vectors
+// do not expire.
+func WithTTL(ttl time.Duration) Option {
+	return func(e *Embedder) {
+		e.ttl = ttl
+	}
+}
+
+// New returns an embedder that caches the vectors of embedder in c. The
+// model is part of every key: use a different model name for every embedder
+// configuration sharing a cache, as their vectors are not interchangeable.
+func New(embedder embeddings.Embedder, c llmcache.Cache, model string, opts ...Option) *Embedder {
+	e := &Embedder{
+		embedder: embedder,
+		cache:    c,
+		model:    model,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// EmbedDocuments returns a vector for each text. Only the texts whose vector
+// is not cached are passed to the wrapped embedder, in a single call, so that
+// the embedder batches them as it would without the cache.
+func (e *Embedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	// missing maps the keys of the texts that are not cached to their indices,
+	// so that duplicated texts are embedded once.
+	missing := make(map[string][]int)
+	var missingTexts []string
+	for i, text := range texts {
+		k := e.key(_kindDocument, text)
+		if indices, ok := missing[k]; ok {
+			missing[k] = append(indices, i)
+			continue
+		}
+		vector, err := e.get(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+		if vector == nil {
+			missing[k] = []int{i}
+			missingTexts = append(missingTexts, text)
+			continue
+		}
+		vectors[i] = vector
+	}
+	if len(missingTexts) == 0 {
+		return vectors, nil
+	}
+
+	// The texts are passed as a copy, as some embedders modify them.
+	embedded, err := e.embedder.EmbedDocuments(ctx, append([]string(nil), missingTexts...))
+	if err != nil {
+		return nil, err
+	}
+	if len(embedded) != len(missingTexts) {
+		return nil, ErrUnexpectedResponseLength
+	}
+	for j, text := range missingTexts {
+		k := e.key(_kindDocument, text)
+		if err := e.set(ctx, k, embedded[j]); err != nil {
+			return nil, err
+		}
+		for _, i := range missing[k] {
+			vectors[i] = embedded[j]
+		}
+	}
+	return vectors, nil
+}
+
+// EmbedQuery embeds a single text. Queries are cached apart from documents,
+// as some embedders embed them differently.
+func (e *Embedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	k := e.key(_kindQuery, text)
+	vector, err := e.get(ctx, k)
+	if err != nil || vector != nil {
+		return vector, err
+	}
+	vector, err = e.embedder.EmbedQuery(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.set(ctx, k, vector); err != nil {
+		return nil, err
+	}
+	return vector, nil
+}
+
+// key returns the key of the vector of the text: the model, the kind of the
+// text and the hex encoded SHA-256 hash of the text.
+func (e *Embedder) key(kind, text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return e.model + ":" + kind + ":" + hex.EncodeToString(sum[:])
+}
+
+// get returns the cached vector of the key, or nil if it is not cached.
+func (e *Embedder) get(ctx context.Context, key string) ([]float32, error) {
+	b, ok, err := e.cache.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("get cached vector: %w", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	vector, err := decodeVector(b)
+	if err != nil {
+		return nil, err
+	}
+	return vector, nil
+}
+
+func (e *Embedder) set(ctx context.Context, key string, vector []float32) error {
+	if err := e.cache.Set(ctx, key, encodeVector(vector), e.ttl); err != nil {
+		return fmt.Errorf("set cached vector: %w", err)
+	}
+	return nil
+}
+
+// encodeVector encodes the vector as little endian float32 values.
+func encodeVector(vector []float32) []byte {
+	b := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(v))
+	}
+	return b
+}
+
+func decodeVector(b []byte) ([]float32, error) {
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("%w: length %d is not a multiple of 4", ErrInvalidEntry, len(b))
+	}
+	vector := make([]float32, len(b)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return vector, nil
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/embeddings"
+	llmcache "github.com/tmc/langchaingo/llms/cache"
+)
+
+// countingEmbedder embeds a text as its length and the number of its spaces,
+// and records the texts it embeds.
+type countingEmbedder struct {
+	documents [][]string
+	queries   []string
+}
+
+var _ embeddings.Embedder = (*countingEmbedder)(nil)
+
+func (e *countingEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	e.documents = append(e.documents, append([]string(nil), texts...))
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		vectors = append(vectors, []float32{float32(len(text)), float32(strings.Count(text, " "))})
+	}
+	// Some embedders modify the texts they are passed.
+	embeddings.MaybeRemoveNewLines(texts, true)
+	return vectors, nil
+}
+
+func (e *countingEmbedder) EmbedQuery(_ context.Context, text string) ([]float32, error) {
+	e.queries = append(e.queries, text)
+	return []float32{-float32(len(text))}, nil
+}
+
+func testEmbedder(t *testing.T, c llmcache.Cache) {
+	t.Helper()
+	ctx := context.Background()
+
+	inner := &countingEmbedder{}
+	e := New(inner, c, "test-model")
+
+	texts := []string{"a b", "line\nbreak", "a b"}
+	vectors, err := e.EmbedDocuments(ctx, texts)
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{3, 1}, {10, 0}, {3, 1}}, vectors)
+	assert.Equal(t, []string{"a b", "line\nbreak", "a b"}, texts)
+
+	vectors, err = e.EmbedDocuments(ctx, []string{"line\nbreak", "new text", "a b"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{10, 0}, {8, 1}, {3, 1}}, vectors)
+	// Only the texts that were not cached are embedded.
+	assert.Equal(t, [][]string{{"a b", "line\nbreak"}, {"new text"}}, inner.documents)
+
+	for i := 0; i < 2; i++ {
+		vector, err := e.EmbedQuery(ctx, "a b")
+		require.NoError(t, err)
+		assert.Equal(t, []float32{-3}, vector)
+	}
+	assert.Equal(t, []string{"a b"}, inner.queries)
+
+	// Another model does not share the cached vectors.
+	_, err = New(inner, c, "other-model").EmbedDocuments(ctx, []string{"a b"})
+	require.NoError(t, err)
+	assert.Len(t, inner.documents, 3)
+}
+
+func TestEmbedderInMemory(t *testing.T) {
+	t.Parallel()
+	testEmbedder(t, llmcache.NewInMemory(10))
+}
+
+func TestEmbedderFile(t *testing.T) {
+	t.Parallel()
+	c, err := llmcache.NewFile(t.TempDir())
+	require.NoError(t, err)
+	testEmbedder(t, c)
+}
+
+func TestEmbedderInvalidEntry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	c := llmcache.NewInMemory(10)
+	e := New(&countingEmbedder{}, c, "test-model")
+	require.NoError(t, c.Set(ctx, e.key(_kindQuery, "a"), []byte{1, 2, 3}, 0))
+	_, err := e.EmbedQuery(ctx, "a")
+	require.ErrorIs(t, err, ErrInvalidEntry)
+}
@@ -0,0 +1,196 @@
+// Package cohere provides an embedder backed by the Cohere API, using its
+// /v1/embed endpoint. See https://docs.cohere.com/reference/embed for
+// details.
+package cohere
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/llms"
+)
+
+var (
+	ErrMissingToken = errors.New("missing the COHERE_API_KEY key, set it in the COHERE_API_KEY environment variable")
+
+	// ErrUnexpectedResponseLength is returned when the API returns a
+	// different number of vectors than it was asked for.
+	ErrUnexpectedResponseLength = embeddings.ErrUnexpectedResponseLength
+)
+
+// _maxBatchSize is the maximum number of texts of a request to the API.
+const _maxBatchSize = 96
+
+// InputType is the kind of the texts embedded, which v3 models embed
+// differently.
+type InputType string
+
+const (
+	InputTypeSearchDocument InputType = "search_document"
+	InputTypeSearchQuery    InputType = "search_query"
+)
+
+// Truncate is how the API handles the texts longer than the maximum input
+// length of the model.
+type Truncate string
+
+const (
+	// TruncateNone fails the request.
+	TruncateNone Truncate = "NONE"
+	// TruncateStart discards the start of the texts.
+	TruncateStart Truncate = "START"
+	// TruncateEnd discards the end of the texts.
+	TruncateEnd Truncate = "END"
+)
+
+// APIError is returned when the API responds with an unexpected HTTP status
+// code. It unwraps to a *llms.StatusError, so that it is classified and
+// retried as the errors of the LLMs.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Message is the error message reported by the API, if any.
+	Message string
+	// Header holds the response headers, e.g. Retry-After.
+	Header http.Header
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("cohere: API returned unexpected status code: %d", e.StatusCode)
+	if e.Message == "" {
+		return msg
+	}
+	return fmt.Sprintf("%s: %s", msg, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return &llms.StatusError{
+		StatusCode: e.StatusCode,
+		Message:    e.Message,
+		Header:     e.Header,
+	}
+}
+
+// Cohere is the embedder using the Cohere API.
+type Cohere struct {
+	token      string
+	baseURL    string
+	httpClient Doer
+
+	Model          string
+	BatchSize      int
+	MaxTokens      int
+	MaxConcurrency int
+	Truncate       Truncate
+}
+
+var _ embeddings.Embedder = &Cohere{}
+
+// NewCohere creates a new Cohere embedder with options.
+func NewCohere(opts ...Option) (*Cohere, error) {
+	return applyOptions(opts...)
+}
+
+// EmbedDocuments creates one vector embedding for each of the texts, as
+// search documents. The texts are embedded in requests of BatchSize texts,
+// MaxConcurrency requests at a time.
+func (e *Cohere) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return embeddings.EmbedBatches(ctx, texts, e.embedFunc(InputTypeSearchDocument), e.batchOptions()...)
+}
+
+// EmbedQuery embeds a single text, as a search query.
+func (e *Cohere) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	emb, err := embeddings.EmbedBatches(ctx, []string{text}, e.embedFunc(InputTypeSearchQuery), e.batchOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	return emb[0], nil
+}
+
+func (e *Cohere) batchOptions() []embeddings.BatchOption {
+	batchSize := e.BatchSize
+	if batchSize < 1 || batchSize > _maxBatchSize {
+		batchSize = _maxBatchSize
+	}
+	return []embeddings.BatchOption{
+		embeddings.WithBatchSize(batchSize),
+		embeddings.WithMaxTokens(e.MaxTokens),
+		embeddings.WithMaxConcurrency(e.MaxConcurrency),
+	}
+}
+
+// embedFunc returns the function embedding texts of the input type.
+func (e *Cohere) embedFunc(inputType InputType) embeddings.EmbedFunc {
+	return func(ctx context.Context, texts []string) ([][]float32, error) {
+		return e.embed(ctx, texts, inputType)
+	}
+}
+
+type embedRequest struct {
+	Texts     []string  `json:"texts"`
+	Model     string    `json:"model,omitempty"`
+	InputType InputType `json:"input_type,omitempty"`
+	Truncate  Truncate  `json:"truncate,omitempty"`
+}
+
+type embedResponse struct {
+	ID         string      `json:"id,omitempty"`
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+type errorMessage struct {
+	Message string `json:"message"`
+}
+
+// embed sends a request to the /v1/embed endpoint.
+func (e *Cohere) embed(ctx context.Context, texts []string, inputType InputType) ([][]float32, error) {
+	payloadBytes, err := json.Marshal(embedRequest{
+		Texts:     texts,
+		Model:     e.Model,
+		InputType: inputType,
+		Truncate:  e.Truncate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/v1/embed", bytes.NewReader(payloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("authorization", "bearer "+e.token)
+
+	r, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		// No need to check the error here: if it fails, we'll just return the
+		// status code.
+		var errResp errorMessage
+		_ = json.NewDecoder(r.Body).Decode(&errResp)
+
+		return nil, &APIError{
+			StatusCode: r.StatusCode,
+			Message:    errResp.Message,
+			Header:     r.Header,
+		}
+	}
+
+	var response embedResponse
+	if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(response.Embeddings) != len(texts) {
+		return nil, ErrUnexpectedResponseLength
+	}
+	return response.Embeddings, nil
+}
@@ -0,0 +1,94 @@
+package cohere
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestCohere(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		requests []embedRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embed", r.URL.Path)
+		assert.Equal(t, "bearer token", r.Header.Get("authorization"))
+		var req embedRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		resp := embedResponse{ID: "id"}
+		for _, text := range req.Texts {
+			if text == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"message":"invalid request: texts must not be empty"}`))
+				return
+			}
+			resp.Embeddings = append(resp.Embeddings, []float32{float32(len(text))})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	e, err := NewCohere(
+		WithToken("token"),
+		WithBaseURL(server.URL+"/"),
+		WithMaxConcurrency(4),
+		WithTruncate(TruncateStart),
+	)
+	require.NoError(t, err)
+
+	texts := make([]string, 200)
+	want := make([][]float32, 200)
+	for i := range texts {
+		texts[i] = fmt.Sprint(i)
+		want[i] = []float32{float32(len(texts[i]))}
+	}
+	vectors, err := e.EmbedDocuments(context.Background(), texts)
+	require.NoError(t, err)
+	assert.Equal(t, want, vectors)
+
+	vector, err := e.EmbedQuery(context.Background(), "query")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{5}, vector)
+
+	sizes := make([]int, 0, len(requests))
+	for _, req := range requests {
+		sizes = append(sizes, len(req.Texts))
+		assert.Equal(t, "embed-english-v3.0", req.Model)
+		assert.Equal(t, TruncateStart, req.Truncate)
+		if len(req.Texts) == 1 && req.Texts[0] == "query" {
+			assert.Equal(t, InputTypeSearchQuery, req.InputType)
+		} else {
+			assert.Equal(t, InputTypeSearchDocument, req.InputType)
+		}
+	}
+	assert.ElementsMatch(t, []int{96, 96, 8, 1}, sizes)
+
+	_, err = e.EmbedDocuments(context.Background(), []string{"a", ""})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "invalid request: texts must not be empty", apiErr.Message)
+	assert.Equal(t, llms.ErrorClassClient, llms.ClassifyError(err))
+}
+
+func TestCohereMissingToken(t *testing.T) {
+	t.Setenv(tokenEnvVarName, "")
+
+	_, err := NewCohere()
+	require.ErrorIs(t, err, ErrMissingToken)
+}
@@ -0,0 +1,116 @@
+package cohere
+
+import (
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	tokenEnvVarName   = "COHERE_API_KEY"  //nolint:gosec
+	baseURLEnvVarName = "COHERE_BASE_URL" //nolint:gosec
+
+	_defaultBaseURL        = "https://api.cohere.ai"
+	_defaultModel          = "embed-english-v3.0"
+	_defaultMaxConcurrency = 1
+)
+
+// Doer performs a HTTP request.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Option is a function type that can be used to modify the client.
+type Option func(p *Cohere)
+
+// WithToken passes the Cohere API token to the client. If not set, the token
+// is read from the COHERE_API_KEY environment variable.
+func WithToken(token string) Option {
+	return func(p *Cohere) {
+		p.token = token
+	}
+}
+
+// WithBaseURL passes the Cohere base url to the client. If not set, the base
+// url is read from the COHERE_BASE_URL environment variable. If still not
+// set, then the default value https://api.cohere.ai is used.
+func WithBaseURL(baseURL string) Option {
+	return func(p *Cohere) {
+		p.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient allows setting a custom HTTP client. If not set, the default
+// value is http.DefaultClient.
+func WithHTTPClient(client Doer) Option {
+	return func(p *Cohere) {
+		p.httpClient = client
+	}
+}
+
+// WithModel is an option for specifying the embedding model. The default is
+// embed-english-v3.0.
+func WithModel(model string) Option {
+	return func(p *Cohere) {
+		p.Model = model
+	}
+}
+
+// WithBatchSize is an option for specifying the number of texts embedded by
+// a request. It is at most 96, the limit of the API, which is also the
+// default.
+func WithBatchSize(batchSize int) Option {
+	return func(p *Cohere) {
+		p.BatchSize = batchSize
+	}
+}
+
+// WithMaxTokens is an option for specifying the maximum number of tokens of a
+// text, as with embeddings.WithMaxTokens. If not set, texts are not split.
+// Tokens are counted as runes, which overestimates them.
+func WithMaxTokens(maxTokens int) Option {
+	return func(p *Cohere) {
+		p.MaxTokens = maxTokens
+	}
+}
+
+// WithMaxConcurrency is an option for specifying the maximum number of
+// requests sent concurrently.
+func WithMaxConcurrency(maxConcurrency int) Option {
+	return func(p *Cohere) {
+		p.MaxConcurrency = maxConcurrency
+	}
+}
+
+// WithTruncate is an option for specifying how the API handles the texts
+// longer than the maximum input length of the model. If not set, the API
+// default applies, which is TruncateEnd.
+func WithTruncate(truncate Truncate) Option {
+	return func(p *Cohere) {
+		p.Truncate = truncate
+	}
+}
+
+func applyOptions(opts ...Option) (*Cohere, error) {
+	o := &Cohere{
+		token:          os.Getenv(tokenEnvVarName),
+		baseURL:        os.Getenv(baseURLEnvVarName),
+		httpClient:     http.DefaultClient,
+		Model:          _defaultModel,
+		BatchSize:      _maxBatchSize,
+		MaxConcurrency: _defaultMaxConcurrency,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.token == "" {
+		return nil, ErrMissingToken
+	}
+	if o.baseURL == "" {
+		o.baseURL = _defaultBaseURL
+	}
+	o.baseURL = strings.TrimRight(o.baseURL, "/")
+	return o, nil
+}
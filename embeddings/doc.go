@@ -7,7 +7,13 @@ The main components of this package are:
 - Embedder interface: a common interface for creating vector embeddings from texts.
 - OpenAI: an Embedder implementation using the OpenAI API.
 - VertexAIPaLM: an Embedder implementation using Google PaLM (VertexAI) API.
-- Helper functions: utility functions for embedding, such as `batchTexts` and `maybeRemoveNewLines`.
+- Helper functions: utility functions for embedding, such as `EmbedBatches` and `MaybeRemoveNewLines`.
+
+EmbedBatches splits long texts, embeds their parts and averages the vectors of
+the parts. The embedders limit the parts in tokens with their WithMaxTokens
+option. The OpenAI, VertexAI, Huggingface and Ernie embedders also limit them
+in characters with their WithBatchSize option, as they always have, and set
+the number of texts of a request with their WithBatchCount option.
 
 The package provides a flexible way to handle different APIs for generating
 embeddings by using the Embedder interface as an abstraction.
@@ -26,6 +26,9 @@ func MaybeRemoveNewLines(texts []string, removeNewLines bool) []string {
 }
 
 // BatchTexts splits strings by the length batchSize.
+//
+// Deprecated: use EmbedBatches, which splits the texts by tokens only when
+// they are too long, and batches them.
 func BatchTexts(texts []string, batchSize int) [][]string {
 	batchedTexts := make([][]string, len(texts))
 	for i, text := range texts {
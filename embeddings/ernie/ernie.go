@@ -9,10 +9,11 @@ import (
 
 // Ernie Embedding-V1 doc: https://cloud.baidu.com/doc/WENXINWORKSHOP/s/alj562vvu
 type Ernie struct {
-	client        *ernie.LLM
-	batchSize     int // 每个文本长度不超过 384个token
-	batchCount    int // 文本数量不超过16
-	stripNewLines bool
+	client         *ernie.LLM
+	batchSize      int // 每个文本长度不超过 384个token
+	batchCount     int // 文本数量不超过16
+	maxConcurrency int
+	stripNewLines  bool
 }
 
 var _ embeddings.Embedder = &Ernie{}
@@ -20,9 +21,10 @@ var _ embeddings.Embedder = &Ernie{}
 // NewErnie creates a new Ernie with options. Options for client, strip new lines and batch size.
 func NewErnie(opts ...Option) (*Ernie, error) {
 	v := &Ernie{
-		stripNewLines: defaultStripNewLines,
-		batchSize:     defaultBatchSize,
-		batchCount:    defaultBatchCount,
+		stripNewLines:  defaultStripNewLines,
+		batchSize:      defaultBatchSize,
+		batchCount:     defaultBatchCount,
+		maxConcurrency: defaultMaxConcurrency,
 	}
 
 	for _, opt := range opts {
@@ -40,57 +42,15 @@ func NewErnie(opts ...Option) (*Ernie, error) {
 	return v, nil
 }
 
-// split texts with batchCount.
-func (e *Ernie) embed(ctx context.Context, texts []string) ([][]float32, error) {
-	emb := make([][]float32, 0, len(texts))
-
-	offsetLen := len(texts) / e.batchCount
-	for i := 0; i <= offsetLen; i++ {
-		start := i * e.batchCount
-		end := i*e.batchCount + e.batchCount
-
-		if end > len(texts) {
-			end = len(texts)
-		}
-
-		curTextEmbeddings, err := e.client.CreateEmbedding(ctx, texts[start:end])
-		if err != nil {
-			return nil, err
-		}
-
-		emb = append(emb, curTextEmbeddings...)
-	}
-	return emb, nil
-}
-
 // EmbedDocuments use ernie Embedding-V1.
 func (e *Ernie) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
-	batchedTexts := embeddings.BatchTexts(
+	return embeddings.EmbedBatches(ctx,
 		embeddings.MaybeRemoveNewLines(texts, e.stripNewLines),
-		e.batchSize,
+		e.client.CreateEmbedding,
+		embeddings.WithBatchSize(e.batchCount),
+		embeddings.WithMaxRunes(e.batchSize),
+		embeddings.WithMaxConcurrency(e.maxConcurrency),
 	)
-
-	emb := make([][]float32, 0, len(texts))
-	for _, texts := range batchedTexts {
-		curTextEmbeddings, err := e.embed(ctx, texts)
-		if err != nil {
-			return nil, err
-		}
-
-		textLengths := make([]int, 0, len(texts))
-		for _, text := range texts {
-			textLengths = append(textLengths, len(text))
-		}
-
-		combined, err := embeddings.CombineVectors(curTextEmbeddings, textLengths)
-		if err != nil {
-			return nil, err
-		}
-
-		emb = append(emb, combined)
-	}
-
-	return emb, nil
 }
 
 // EmbedQuery use ernie Embedding-V1.
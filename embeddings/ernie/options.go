@@ -4,9 +4,10 @@ import "github.com/tmc/langchaingo/llms/ernie"
 
 const (
 	// see: https://cloud.baidu.com/doc/WENXINWORKSHOP/s/alj562vvu#body%E5%8F%82%E6%95%B0
-	defaultBatchCount    = 16
-	defaultBatchSize     = 384
-	defaultStripNewLines = true
+	defaultBatchCount     = 16
+	defaultBatchSize      = 384
+	defaultMaxConcurrency = 1
+	defaultStripNewLines  = true
 )
 
 // Option is a function type that can be used to modify the client.
@@ -19,13 +20,31 @@ func WithClient(client ernie.LLM) Option {
 	}
 }
 
-// WithBatchSize is an option for specifying the batch size.
+// WithBatchSize is an option for specifying the maximum number of characters
+// of a text. Longer texts are split as with embeddings.WithMaxRunes. The
+// default is 384, the maximum number of tokens of a text of the API.
 func WithBatchSize(batchSize int) Option {
 	return func(e *Ernie) {
 		e.batchSize = batchSize
 	}
 }
 
+// WithBatchCount is an option for specifying the number of texts embedded by
+// a request. The default is 16, the maximum of the API.
+func WithBatchCount(batchCount int) Option {
+	return func(e *Ernie) {
+		e.batchCount = batchCount
+	}
+}
+
+// WithMaxConcurrency is an option for specifying the maximum number of
+// requests sent concurrently.
+func WithMaxConcurrency(maxConcurrency int) Option {
+	return func(e *Ernie) {
+		e.maxConcurrency = maxConcurrency
+	}
+}
+
 // WithStripNewLines is an option for specifying the should it strip new lines.
 func WithStripNewLines(stripNewLines bool) Option {
 	return func(e *Ernie) {
@@ -0,0 +1,108 @@
+// Package hashing provides an embedder that needs no model nor network:
+// it hashes the words of a text into a vector, as in the hashing trick.
+//
+// The same text always has the same vector, and texts sharing words have
+// similar vectors, which makes the embedder suitable for testing vector
+// stores and retrievers, and as a lexical baseline.
+package hashing
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"strings"
+	"unicode"
+
+	"github.com/tmc/langchaingo/embeddings"
+)
+
+var (
+	// ErrInvalidDimension is returned when the dimension is not positive.
+	ErrInvalidDimension = errors.New("dimension must be positive")
+	// ErrInvalidNGrams is returned when the n-gram sizes are invalid.
+	ErrInvalidNGrams = errors.New("invalid n-gram sizes")
+)
+
+// Hashing is the embedder hashing the features of a text, its words and
+// optionally its word and character n-grams, into a vector of Dimension
+// dimensions. Each feature adds 1 or -1, depending on its hash, to a
+// dimension chosen by its hash, and the vector is scaled to unit length, so
+// that the dot product of two vectors is their cosine similarity.
+type Hashing struct {
+	Dimension int
+	MinNGram  int
+	MaxNGram  int
+	CharNGram int
+}
+
+var _ embeddings.Embedder = &Hashing{}
+
+// NewHashing creates a new hashing embedder with options.
+func NewHashing(opts ...Option) (*Hashing, error) {
+	return applyOptions(opts...)
+}
+
+// EmbedDocuments creates one vector embedding for each of the texts.
+func (e *Hashing) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	emb := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		emb = append(emb, e.embed(text))
+	}
+	return emb, nil
+}
+
+// EmbedQuery embeds a single text.
+func (e *Hashing) EmbedQuery(_ context.Context, text string) ([]float32, error) {
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	return e.embed(text), nil
+}
+
+func (e *Hashing) embed(text string) []float32 {
+	vector := make([]float32, e.Dimension)
+	words := tokenize(text)
+	for n := e.MinNGram; n <= e.MaxNGram; n++ {
+		for i := 0; i+n <= len(words); i++ {
+			// The separator keeps the n-grams apart from the words.
+			e.add(vector, strings.Join(words[i:i+n], "\x00"))
+		}
+	}
+	if e.CharNGram > 0 {
+		for _, word := range words {
+			// The word is padded so that its first and last characters are
+			// n-grams of their own.
+			runes := []rune(" " + word + " ")
+			for i := 0; i+e.CharNGram <= len(runes); i++ {
+				e.add(vector, "\x01"+string(runes[i:i+e.CharNGram]))
+			}
+		}
+	}
+	return embeddings.Normalize(vector)
+}
+
+// add adds the feature to the vector.
+func (e *Hashing) add(vector []float32, feature string) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(feature))
+	sum := h.Sum64()
+	// The lowest bit chooses the sign, so that collisions cancel out on
+	// average, the others the dimension.
+	i := (sum >> 1) % uint64(len(vector))
+	if sum&1 == 0 {
+		vector[i]++
+	} else {
+		vector[i]--
+	}
+}
+
+// tokenize returns the lower cased words of the text: its runs of letters
+// and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
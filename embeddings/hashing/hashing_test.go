@@ -0,0 +1,93 @@
+package hashing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func TestHashing(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	e, err := NewHashing(WithDimension(64))
+	require.NoError(t, err)
+
+	vectors, err := e.EmbedDocuments(ctx, []string{
+		"The cat sat on the mat",
+		"A cat sat on a mat",
+		"Stock markets fell sharply today",
+	})
+	require.NoError(t, err)
+	require.Len(t, vectors, 3)
+	assert.Len(t, vectors[0], 64)
+	assert.InDelta(t, 1, dot(vectors[0], vectors[0]), 1e-5)
+
+	query, err := e.EmbedQuery(ctx, "the CAT sat on the mat!")
+	require.NoError(t, err)
+	assert.Equal(t, vectors[0], query)
+	assert.Greater(t, dot(query, vectors[1]), dot(query, vectors[2]))
+	assert.Greater(t, dot(query, vectors[1]), float32(0.4))
+
+	empty, err := e.EmbedQuery(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, make([]float32, 64), empty)
+}
+
+func TestHashingNGrams(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	words, err := NewHashing()
+	require.NoError(t, err)
+	bigrams, err := NewHashing(WithNGrams(1, 2))
+	require.NoError(t, err)
+
+	// Word order only matters with n-grams.
+	a, b := "dog bites man", "man bites dog"
+	va, _ := words.EmbedQuery(ctx, a)
+	vb, _ := words.EmbedQuery(ctx, b)
+	assert.InDelta(t, 1, dot(va, vb), 1e-5)
+	va, _ = bigrams.EmbedQuery(ctx, a)
+	vb, _ = bigrams.EmbedQuery(ctx, b)
+	assert.Less(t, dot(va, vb), float32(0.9))
+
+	chars, err := NewHashing(WithCharNGrams(3))
+	require.NoError(t, err)
+	va, _ = chars.EmbedQuery(ctx, "embedding")
+	vb, _ = chars.EmbedQuery(ctx, "embeddings")
+	assert.Greater(t, dot(va, vb), float32(0.5))
+}
+
+func TestHashingInvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewHashing(WithDimension(0))
+	require.ErrorIs(t, err, ErrInvalidDimension)
+	_, err = NewHashing(WithNGrams(2, 1))
+	require.ErrorIs(t, err, ErrInvalidNGrams)
+}
+
+func TestHashingZeroValue(t *testing.T) {
+	t.Parallel()
+
+	e := &Hashing{}
+	_, err := e.EmbedQuery(context.Background(), "hello")
+	require.ErrorIs(t, err, ErrInvalidDimension)
+	_, err = e.EmbedDocuments(context.Background(), []string{"hello"})
+	require.ErrorIs(t, err, ErrInvalidDimension)
+
+	e = &Hashing{Dimension: 8}
+	_, err = e.EmbedQuery(context.Background(), "hello")
+	require.ErrorIs(t, err, ErrInvalidNGrams)
+}
@@ -0,0 +1,67 @@
+package hashing
+
+const (
+	_defaultDimension = 256
+	_defaultMinNGram  = 1
+	_defaultMaxNGram  = 1
+)
+
+// Option is a function type that can be used to modify the embedder.
+type Option func(p *Hashing)
+
+// WithDimension is an option for specifying the number of dimensions of the
+// vectors.
+func WithDimension(dimension int) Option {
+	return func(p *Hashing) {
+		p.Dimension = dimension
+	}
+}
+
+// WithNGrams is an option for specifying the range of the numbers of
+// consecutive words hashed as features. The default is 1 to 1, hashing
+// single words.
+func WithNGrams(minN, maxN int) Option {
+	return func(p *Hashing) {
+		p.MinNGram = minN
+		p.MaxNGram = maxN
+	}
+}
+
+// WithCharNGrams is an option for specifying the number of characters of
+// the n-grams of each word hashed as features, in addition to the words. It
+// makes words sharing a stem, or misspelled, land near each other. Zero,
+// the default, disables the character n-grams.
+func WithCharNGrams(n int) Option {
+	return func(p *Hashing) {
+		p.CharNGram = n
+	}
+}
+
+func applyOptions(opts ...Option) (*Hashing, error) {
+	o := &Hashing{
+		Dimension: _defaultDimension,
+		MinNGram:  _defaultMinNGram,
+		MaxNGram:  _defaultMaxNGram,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// validate returns an error if the embedder is misconfigured, such as a
+// Hashing created without NewHashing.
+func (e *Hashing) validate() error {
+	if e.Dimension < 1 {
+		return ErrInvalidDimension
+	}
+	if e.MinNGram < 1 || e.MaxNGram < e.MinNGram || e.CharNGram < 0 {
+		return ErrInvalidNGrams
+	}
+	return nil
+}
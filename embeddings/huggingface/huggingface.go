@@ -14,8 +14,11 @@ type Huggingface struct {
 	Model  string
 	Task   string
 
-	StripNewLines bool
-	BatchSize     int
+	StripNewLines  bool
+	BatchSize      int
+	BatchCount     int
+	MaxTokens      int
+	MaxConcurrency int
 }
 
 var _ embeddings.Embedder = &Huggingface{}
@@ -30,32 +33,11 @@ func NewHuggingface(opts ...Option) (*Huggingface, error) {
 }
 
 func (e *Huggingface) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
-	batchedTexts := embeddings.BatchTexts(
+	return embeddings.EmbedBatches(ctx,
 		embeddings.MaybeRemoveNewLines(texts, e.StripNewLines),
-		e.BatchSize,
+		e.embed,
+		e.batchOptions()...,
 	)
-
-	emb := make([][]float32, 0, len(texts))
-	for _, texts := range batchedTexts {
-		curTextEmbeddings, err := e.client.CreateEmbedding(ctx, texts, e.Model, e.Task)
-		if err != nil {
-			return nil, err
-		}
-
-		textLengths := make([]int, 0, len(texts))
-		for _, text := range texts {
-			textLengths = append(textLengths, len(text))
-		}
-
-		combined, err := embeddings.CombineVectors(curTextEmbeddings, textLengths)
-		if err != nil {
-			return nil, err
-		}
-
-		emb = append(emb, combined)
-	}
-
-	return emb, nil
 }
 
 func (e *Huggingface) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
@@ -63,10 +45,24 @@ func (e *Huggingface) EmbedQuery(ctx context.Context, text string) ([]float32, e
 		text = strings.ReplaceAll(text, "\n", " ")
 	}
 
-	emb, err := e.client.CreateEmbedding(ctx, []string{text}, e.Model, e.Task)
+	emb, err := embeddings.EmbedBatches(ctx, []string{text}, e.embed, e.batchOptions()...)
 	if err != nil {
 		return nil, err
 	}
 
 	return emb[0], nil
 }
+
+func (e *Huggingface) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return e.client.CreateEmbedding(ctx, texts, e.Model, e.Task)
+}
+
+func (e *Huggingface) batchOptions() []embeddings.BatchOption {
+	return []embeddings.BatchOption{
+		embeddings.WithBatchSize(e.BatchCount),
+		embeddings.WithMaxTokens(e.MaxTokens),
+		embeddings.WithMaxRunes(e.BatchSize),
+		embeddings.WithMaxConcurrency(e.MaxConcurrency),
+		embeddings.WithTokenCounter(e.client.GetNumTokens),
+	}
+}
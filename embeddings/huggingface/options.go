@@ -5,10 +5,13 @@ import (
 )
 
 const (
-	_defaultBatchSize     = 512
-	_defaultStripNewLines = true
-	_defaultModel         = "sentence-transformers/all-mpnet-base-v2"
-	_defaultTask          = "feature-extraction"
+	_defaultBatchSize      = 512
+	_defaultBatchCount     = 512
+	_defaultMaxTokens      = 512
+	_defaultMaxConcurrency = 1
+	_defaultStripNewLines  = true
+	_defaultModel          = "sentence-transformers/all-mpnet-base-v2"
+	_defaultTask           = "feature-extraction"
 )
 
 // Option is a function type that can be used to modify the client.
@@ -42,19 +45,49 @@ func WithStripNewLines(stripNewLines bool) Option {
 	}
 }
 
-// WithBatchSize is an option for specifying the batch size.
+// WithBatchSize is an option for specifying the maximum number of characters
+// of a text. Longer texts are split as with embeddings.WithMaxRunes. The
+// default is 512.
 func WithBatchSize(batchSize int) Option {
 	return func(p *Huggingface) {
 		p.BatchSize = batchSize
 	}
 }
 
+// WithBatchCount is an option for specifying the number of texts embedded by
+// a request.
+func WithBatchCount(batchCount int) Option {
+	return func(p *Huggingface) {
+		p.BatchCount = batchCount
+	}
+}
+
+// WithMaxTokens is an option for specifying the maximum number of tokens of a
+// text, as with embeddings.WithMaxTokens. The default is 512, the maximum input
+// length of most sentence-transformers models.
+func WithMaxTokens(maxTokens int) Option {
+	return func(p *Huggingface) {
+		p.MaxTokens = maxTokens
+	}
+}
+
+// WithMaxConcurrency is an option for specifying the maximum number of
+// requests sent concurrently.
+func WithMaxConcurrency(maxConcurrency int) Option {
+	return func(p *Huggingface) {
+		p.MaxConcurrency = maxConcurrency
+	}
+}
+
 func applyOptions(opts ...Option) (*Huggingface, error) {
 	o := &Huggingface{
-		StripNewLines: _defaultStripNewLines,
-		BatchSize:     _defaultBatchSize,
-		Model:         _defaultModel,
-		Task:          _defaultTask,
+		StripNewLines:  _defaultStripNewLines,
+		BatchSize:      _defaultBatchSize,
+		BatchCount:     _defaultBatchCount,
+		MaxTokens:      _defaultMaxTokens,
+		MaxConcurrency: _defaultMaxConcurrency,
+		Model:          _defaultModel,
+		Task:           _defaultTask,
 	}
 
 	for _, opt := range opts {
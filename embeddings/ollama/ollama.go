@@ -0,0 +1,69 @@
+// Package ollama provides an embedder backed by a locally hosted Ollama
+// server, using its /api/embeddings endpoint.
+package ollama
+
+import (
+	"context"
+
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/llms/ollama"
+)
+
+// ErrUnexpectedResponseLength is returned when the server returns a different
+// number of vectors than it was asked for.
+var ErrUnexpectedResponseLength = embeddings.ErrUnexpectedResponseLength
+
+// Ollama is the embedder using an Ollama server.
+type Ollama struct {
+	client *ollama.LLM
+
+	BatchSize      int
+	MaxTokens      int
+	MaxConcurrency int
+	Normalize      bool
+}
+
+var _ embeddings.Embedder = &Ollama{}
+
+// NewOllama creates a new Ollama embedder with options.
+func NewOllama(opts ...Option) (*Ollama, error) {
+	return applyOptions(opts...)
+}
+
+// EmbedDocuments creates one vector embedding for each of the texts. The
+// texts are embedded in batches of BatchSize texts, MaxConcurrency batches
+// at a time.
+func (e *Ollama) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	emb, err := embeddings.EmbedBatches(ctx, texts, e.client.CreateEmbedding, e.batchOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Normalize {
+		for _, v := range emb {
+			embeddings.Normalize(v)
+		}
+	}
+	return emb, nil
+}
+
+// EmbedQuery embeds a single text.
+func (e *Ollama) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	emb, err := embeddings.EmbedBatches(ctx, []string{text}, e.client.CreateEmbedding, e.batchOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	if e.Normalize {
+		embeddings.Normalize(emb[0])
+	}
+	return emb[0], nil
+}
+
+func (e *Ollama) batchOptions() []embeddings.BatchOption {
+	return []embeddings.BatchOption{
+		embeddings.WithBatchSize(e.BatchSize),
+		embeddings.WithMaxTokens(e.MaxTokens),
+		embeddings.WithMaxConcurrency(e.MaxConcurrency),
+		embeddings.WithTokenCounter(e.client.GetNumTokens),
+	}
+}
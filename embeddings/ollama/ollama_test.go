@@ -0,0 +1,64 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms/ollama"
+)
+
+func TestOllama(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		prompts []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/embeddings", r.URL.Path)
+		var req struct {
+			Model  string `json:"model"`
+			Prompt string `json:"prompt"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "nomic-embed-text", req.Model)
+		mu.Lock()
+		prompts = append(prompts, req.Prompt)
+		mu.Unlock()
+
+		if req.Prompt == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"empty prompt"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"embedding": []float32{float32(len(req.Prompt)), 0},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	llm, err := ollama.New(ollama.WithServerURL(server.URL), ollama.WithModel("nomic-embed-text"))
+	require.NoError(t, err)
+	e, err := NewOllama(WithClient(*llm), WithBatchSize(2), WithMaxConcurrency(2))
+	require.NoError(t, err)
+
+	texts := []string{"a", "bb", "ccc", "dddd", "eeeee"}
+	vectors, err := e.EmbedDocuments(context.Background(), texts)
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {2, 0}, {3, 0}, {4, 0}, {5, 0}}, vectors)
+	assert.ElementsMatch(t, texts, prompts)
+
+	e.Normalize = true
+	vector, err := e.EmbedQuery(context.Background(), "query")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{1, 0}, vector)
+
+	_, err = e.EmbedDocuments(context.Background(), []string{"a", ""})
+	require.ErrorContains(t, err, "empty prompt")
+}
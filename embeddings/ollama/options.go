@@ -0,0 +1,74 @@
+package ollama
+
+import (
+	"github.com/tmc/langchaingo/llms/ollama"
+)
+
+const (
+	_defaultBatchSize      = 32
+	_defaultMaxConcurrency = 1
+)
+
+// Option is a function type that can be used to modify the client.
+type Option func(p *Ollama)
+
+// WithClient is an option for providing the LLM client. The model of the
+// client must be an embedding model.
+func WithClient(client ollama.LLM) Option {
+	return func(p *Ollama) {
+		p.client = &client
+	}
+}
+
+// WithBatchSize is an option for specifying the number of texts embedded by
+// a batch. The texts of a batch are embedded one after the other.
+func WithBatchSize(batchSize int) Option {
+	return func(p *Ollama) {
+		p.BatchSize = batchSize
+	}
+}
+
+// WithMaxTokens is an option for specifying the maximum number of tokens of a
+// text, as with embeddings.WithMaxTokens. If not set, texts are not split.
+func WithMaxTokens(maxTokens int) Option {
+	return func(p *Ollama) {
+		p.MaxTokens = maxTokens
+	}
+}
+
+// WithMaxConcurrency is an option for specifying the maximum number of
+// batches embedded concurrently.
+func WithMaxConcurrency(maxConcurrency int) Option {
+	return func(p *Ollama) {
+		p.MaxConcurrency = maxConcurrency
+	}
+}
+
+// WithNormalize is an option for specifying whether the vectors are scaled
+// to unit length.
+func WithNormalize(normalize bool) Option {
+	return func(p *Ollama) {
+		p.Normalize = normalize
+	}
+}
+
+func applyOptions(opts ...Option) (*Ollama, error) {
+	o := &Ollama{
+		BatchSize:      _defaultBatchSize,
+		MaxConcurrency: _defaultMaxConcurrency,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.client == nil {
+		client, err := ollama.New()
+		if err != nil {
+			return nil, err
+		}
+		o.client = client
+	}
+
+	return o, nil
+}
@@ -12,8 +12,11 @@ import (
 type OpenAI struct {
 	client *openai.LLM
 
-	StripNewLines bool
-	BatchSize     int
+	StripNewLines  bool
+	BatchSize      int
+	BatchCount     int
+	MaxTokens      int
+	MaxConcurrency int
 }
 
 var _ embeddings.Embedder = OpenAI{}
@@ -30,32 +33,11 @@ func NewOpenAI(opts ...Option) (OpenAI, error) {
 
 // EmbedDocuments creates one vector embedding for each of the texts.
 func (e OpenAI) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
-	batchedTexts := embeddings.BatchTexts(
+	return embeddings.EmbedBatches(ctx,
 		embeddings.MaybeRemoveNewLines(texts, e.StripNewLines),
-		e.BatchSize,
+		e.client.CreateEmbedding,
+		e.batchOptions()...,
 	)
-
-	emb := make([][]float32, 0, len(texts))
-	for _, texts := range batchedTexts {
-		curTextEmbeddings, err := e.client.CreateEmbedding(ctx, texts)
-		if err != nil {
-			return nil, err
-		}
-
-		textLengths := make([]int, 0, len(texts))
-		for _, text := range texts {
-			textLengths = append(textLengths, len(text))
-		}
-
-		combined, err := embeddings.CombineVectors(curTextEmbeddings, textLengths)
-		if err != nil {
-			return nil, err
-		}
-
-		emb = append(emb, combined)
-	}
-
-	return emb, nil
 }
 
 // EmbedQuery embeds a single text.
@@ -64,10 +46,20 @@ func (e OpenAI) EmbedQuery(ctx context.Context, text string) ([]float32, error)
 		text = strings.ReplaceAll(text, "\n", " ")
 	}
 
-	emb, err := e.client.CreateEmbedding(ctx, []string{text})
+	emb, err := embeddings.EmbedBatches(ctx, []string{text}, e.client.CreateEmbedding, e.batchOptions()...)
 	if err != nil {
 		return nil, err
 	}
 
 	return emb[0], nil
 }
+
+func (e OpenAI) batchOptions() []embeddings.BatchOption {
+	return []embeddings.BatchOption{
+		embeddings.WithBatchSize(e.BatchCount),
+		embeddings.WithMaxTokens(e.MaxTokens),
+		embeddings.WithMaxRunes(e.BatchSize),
+		embeddings.WithMaxConcurrency(e.MaxConcurrency),
+		embeddings.WithTokenCounter(e.client.GetNumTokens),
+	}
+}
@@ -5,8 +5,11 @@ import (
 )
 
 const (
-	_defaultBatchSize     = 512
-	_defaultStripNewLines = true
+	_defaultBatchSize      = 512
+	_defaultBatchCount     = 512
+	_defaultMaxTokens      = 8191
+	_defaultMaxConcurrency = 1
+	_defaultStripNewLines  = true
 )
 
 type ChatOption func(p *ChatOpenAI)
@@ -25,17 +28,47 @@ func WithStripNewLines(stripNewLines bool) ChatOption {
 	}
 }
 
-// WithBatchSize is an option for specifying the batch size.
+// WithBatchSize is an option for specifying the maximum number of characters
+// of a text. Longer texts are split as with embeddings.WithMaxRunes. The
+// default is 512.
 func WithBatchSize(batchSize int) ChatOption {
 	return func(p *ChatOpenAI) {
 		p.BatchSize = batchSize
 	}
 }
 
+// WithBatchCount is an option for specifying the number of texts embedded by
+// a request.
+func WithBatchCount(batchCount int) ChatOption {
+	return func(p *ChatOpenAI) {
+		p.BatchCount = batchCount
+	}
+}
+
+// WithMaxTokens is an option for specifying the maximum number of tokens of a
+// text, as with embeddings.WithMaxTokens. The default is 8191, the maximum
+// input length of text-embedding-ada-002.
+func WithMaxTokens(maxTokens int) ChatOption {
+	return func(p *ChatOpenAI) {
+		p.MaxTokens = maxTokens
+	}
+}
+
+// WithMaxConcurrency is an option for specifying the maximum number of
+// requests sent concurrently.
+func WithMaxConcurrency(maxConcurrency int) ChatOption {
+	return func(p *ChatOpenAI) {
+		p.MaxConcurrency = maxConcurrency
+	}
+}
+
 func applyChatClientOptions(opts ...ChatOption) (ChatOpenAI, error) {
 	o := &ChatOpenAI{
-		StripNewLines: _defaultStripNewLines,
-		BatchSize:     _defaultBatchSize,
+		StripNewLines:  _defaultStripNewLines,
+		BatchSize:      _defaultBatchSize,
+		BatchCount:     _defaultBatchCount,
+		MaxTokens:      _defaultMaxTokens,
+		MaxConcurrency: _defaultMaxConcurrency,
 	}
 
 	for _, opt := range opts {
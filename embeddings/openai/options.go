@@ -5,8 +5,11 @@ import (
 )
 
 const (
-	_defaultBatchSize     = 512
-	_defaultStripNewLines = true
+	_defaultBatchSize      = 512
+	_defaultBatchCount     = 512
+	_defaultMaxTokens      = 8191
+	_defaultMaxConcurrency = 1
+	_defaultStripNewLines  = true
 )
 
 // Option is a function type that can be used to modify the client.
@@ -26,17 +29,47 @@ func WithStripNewLines(stripNewLines bool) Option {
 	}
 }
 
-// WithBatchSize is an option for specifying the batch size.
+// WithBatchSize is an option for specifying the maximum number of characters
+// of a text. Longer texts are split as with embeddings.WithMaxRunes. The
+// default is 512.
 func WithBatchSize(batchSize int) Option {
 	return func(p *OpenAI) {
 		p.BatchSize = batchSize
 	}
 }
 
+// WithBatchCount is an option for specifying the number of texts embedded by
+// a request.
+func WithBatchCount(batchCount int) Option {
+	return func(p *OpenAI) {
+		p.BatchCount = batchCount
+	}
+}
+
+// WithMaxTokens is an option for specifying the maximum number of tokens of a
+// text, as with embeddings.WithMaxTokens. The default is 8191, the maximum
+// input length of text-embedding-ada-002.
+func WithMaxTokens(maxTokens int) Option {
+	return func(p *OpenAI) {
+		p.MaxTokens = maxTokens
+	}
+}
+
+// WithMaxConcurrency is an option for specifying the maximum number of
+// requests sent concurrently.
+func WithMaxConcurrency(maxConcurrency int) Option {
+	return func(p *OpenAI) {
+		p.MaxConcurrency = maxConcurrency
+	}
+}
+
 func applyClientOptions(opts ...Option) (OpenAI, error) {
 	o := &OpenAI{
-		StripNewLines: _defaultStripNewLines,
-		BatchSize:     _defaultBatchSize,
+		StripNewLines:  _defaultStripNewLines,
+		BatchSize:      _defaultBatchSize,
+		BatchCount:     _defaultBatchCount,
+		MaxTokens:      _defaultMaxTokens,
+		MaxConcurrency: _defaultMaxConcurrency,
 	}
 
 	for _, opt := range opts {
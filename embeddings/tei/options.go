@@ -0,0 +1,103 @@
+package tei
+
+import (
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	baseURLEnvVarName = "TEI_BASE_URL" //nolint:gosec
+
+	_defaultBaseURL        = "http://localhost:8080"
+	_defaultBatchSize      = 32
+	_defaultMaxConcurrency = 1
+	_defaultNormalize      = true
+)
+
+// Doer performs a HTTP request.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Option is a function type that can be used to modify the client.
+type Option func(p *TEI)
+
+// WithBaseURL is an option for providing the URL of the server. If not set,
+// the URL is read from the TEI_BASE_URL environment variable. If still not
+// set, then the default value http://localhost:8080 is used.
+func WithBaseURL(baseURL string) Option {
+	return func(p *TEI) {
+		p.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient allows setting a custom HTTP client. If not set, the default
+// value is http.DefaultClient.
+func WithHTTPClient(client Doer) Option {
+	return func(p *TEI) {
+		p.httpClient = client
+	}
+}
+
+// WithBatchSize is an option for specifying the number of texts embedded by
+// a request. It must not exceed the --max-client-batch-size of the server.
+func WithBatchSize(batchSize int) Option {
+	return func(p *TEI) {
+		p.BatchSize = batchSize
+	}
+}
+
+// WithMaxTokens is an option for specifying the maximum number of tokens of a
+// text, as with embeddings.WithMaxTokens. If not set, texts are not split.
+// Tokens are counted as runes, which overestimates them.
+func WithMaxTokens(maxTokens int) Option {
+	return func(p *TEI) {
+		p.MaxTokens = maxTokens
+	}
+}
+
+// WithMaxConcurrency is an option for specifying the maximum number of
+// requests sent concurrently.
+func WithMaxConcurrency(maxConcurrency int) Option {
+	return func(p *TEI) {
+		p.MaxConcurrency = maxConcurrency
+	}
+}
+
+// WithNormalize is an option for specifying whether the server scales the
+// vectors to unit length. The default is true.
+func WithNormalize(normalize bool) Option {
+	return func(p *TEI) {
+		p.Normalize = normalize
+	}
+}
+
+// WithTruncate is an option for specifying whether the server truncates the
+// texts longer than the maximum input length of the model, instead of
+// failing.
+func WithTruncate(truncate bool) Option {
+	return func(p *TEI) {
+		p.Truncate = truncate
+	}
+}
+
+func applyOptions(opts ...Option) *TEI {
+	o := &TEI{
+		baseURL:        os.Getenv(baseURLEnvVarName),
+		httpClient:     http.DefaultClient,
+		BatchSize:      _defaultBatchSize,
+		MaxConcurrency: _defaultMaxConcurrency,
+		Normalize:      _defaultNormalize,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.baseURL == "" {
+		o.baseURL = _defaultBaseURL
+	}
+	o.baseURL = strings.TrimRight(o.baseURL, "/")
+	return o
+}
@@ -0,0 +1,123 @@
+// Package tei provides an embedder backed by a HuggingFace
+// text-embeddings-inference server, using its /embed endpoint. See
+// https://github.com/huggingface/text-embeddings-inference for details.
+package tei
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// ErrUnexpectedResponseLength is returned when the server returns a different
+// number of vectors than it was asked for.
+var ErrUnexpectedResponseLength = embeddings.ErrUnexpectedResponseLength
+
+// TEI is the embedder using a text-embeddings-inference server.
+type TEI struct {
+	baseURL    string
+	httpClient Doer
+
+	BatchSize      int
+	MaxTokens      int
+	MaxConcurrency int
+	Normalize      bool
+	Truncate       bool
+}
+
+var _ embeddings.Embedder = &TEI{}
+
+// NewTEI creates a new TEI embedder with options.
+func NewTEI(opts ...Option) (*TEI, error) {
+	return applyOptions(opts...), nil
+}
+
+// EmbedDocuments creates one vector embedding for each of the texts. The
+// texts are embedded in requests of BatchSize texts, MaxConcurrency requests
+// at a time.
+func (e *TEI) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	emb, err := embeddings.EmbedBatches(ctx, texts, e.embed, e.batchOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	return emb, nil
+}
+
+// EmbedQuery embeds a single text.
+func (e *TEI) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	emb, err := embeddings.EmbedBatches(ctx, []string{text}, e.embed, e.batchOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	return emb[0], nil
+}
+
+func (e *TEI) batchOptions() []embeddings.BatchOption {
+	return []embeddings.BatchOption{
+		embeddings.WithBatchSize(e.BatchSize),
+		embeddings.WithMaxTokens(e.MaxTokens),
+		embeddings.WithMaxConcurrency(e.MaxConcurrency),
+	}
+}
+
+type embedRequest struct {
+	Inputs    []string `json:"inputs"`
+	Normalize bool     `json:"normalize"`
+	Truncate  bool     `json:"truncate"`
+}
+
+type errorMessage struct {
+	Error     string `json:"error"`
+	ErrorType string `json:"error_type"`
+}
+
+// embed sends a request to the /embed endpoint.
+func (e *TEI) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	payloadBytes, err := json.Marshal(embedRequest{
+		Inputs:    texts,
+		Normalize: e.Normalize,
+		Truncate:  e.Truncate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/embed", bytes.NewReader(payloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	r, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		// No need to check the error here: if it fails, we'll just return the
+		// status code.
+		var errResp errorMessage
+		_ = json.NewDecoder(r.Body).Decode(&errResp)
+
+		return nil, &llms.StatusError{
+			StatusCode: r.StatusCode,
+			Message:    errResp.Error,
+			Header:     r.Header,
+		}
+	}
+
+	var vectors [][]float32
+	if err := json.NewDecoder(r.Body).Decode(&vectors); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, ErrUnexpectedResponseLength
+	}
+	return vectors, nil
+}
@@ -0,0 +1,94 @@
+package tei
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestTEI(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		batches [][]string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embed", r.URL.Path)
+		var req embedRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Normalize)
+		assert.True(t, req.Truncate)
+		mu.Lock()
+		batches = append(batches, req.Inputs)
+		mu.Unlock()
+
+		vectors := make([][]float32, 0, len(req.Inputs))
+		for _, input := range req.Inputs {
+			if input == "" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(`{"error":"empty input","error_type":"Validation"}`))
+				return
+			}
+			vectors = append(vectors, []float32{float32(len(input))})
+		}
+		_ = json.NewEncoder(w).Encode(vectors)
+	}))
+	t.Cleanup(server.Close)
+
+	e, err := NewTEI(WithBaseURL(server.URL+"/"), WithBatchSize(2), WithMaxConcurrency(3), WithTruncate(true))
+	require.NoError(t, err)
+
+	vectors, err := e.EmbedDocuments(context.Background(), []string{"a", "bb", "ccc", "dddd", "eeeee"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1}, {2}, {3}, {4}, {5}}, vectors)
+	assert.ElementsMatch(t, [][]string{{"a", "bb"}, {"ccc", "dddd"}, {"eeeee"}}, batches)
+
+	vector, err := e.EmbedQuery(context.Background(), "query")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{5}, vector)
+
+	_, err = e.EmbedDocuments(context.Background(), []string{"a", ""})
+	var statusErr *llms.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, statusErr.StatusCode)
+	assert.Equal(t, "empty input", statusErr.Message)
+}
+
+func TestTEIMaxTokens(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		inputs []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req embedRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		inputs = append(inputs, req.Inputs...)
+		mu.Unlock()
+
+		vectors := make([][]float32, 0, len(req.Inputs))
+		for range req.Inputs {
+			vectors = append(vectors, []float32{1, 0})
+		}
+		_ = json.NewEncoder(w).Encode(vectors)
+	}))
+	t.Cleanup(server.Close)
+
+	e, err := NewTEI(WithBaseURL(server.URL), WithMaxTokens(5))
+	require.NoError(t, err)
+
+	vector, err := e.EmbedQuery(context.Background(), "long text")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{1, 0}, vector)
+	assert.Equal(t, []string{"long ", "text"}, inputs)
+}
@@ -73,6 +73,19 @@ func getAverage(vectors [][]float32, weights []int) ([]float32, error) {
 	return average, nil
 }
 
+// Normalize scales the vector to unit length in place and returns it. A zero
+// vector is returned unchanged.
+func Normalize(v []float32) []float32 {
+	norm := getNorm(v)
+	if norm == 0 {
+		return v
+	}
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
+
 func getNorm(v []float32) float32 {
 	var sum float32
 	for i := 0; i < len(v); i++ {
@@ -81,3 +81,10 @@ func TestGetNorm(t *testing.T) {
 		assert.Equal(t, tc.expected, getNorm(tc.vector))
 	}
 }
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []float32{0.6, 0.8}, Normalize([]float32{3, 4}))
+	assert.Equal(t, []float32{0, 0}, Normalize([]float32{0, 0}))
+}
@@ -5,8 +5,12 @@ import (
 )
 
 const (
-	_defaultBatchSize     = 512
-	_defaultStripNewLines = true
+	// see: https://cloud.google.com/vertex-ai/docs/generative-ai/embeddings/get-text-embeddings
+	_defaultBatchSize      = 512
+	_defaultBatchCount     = 5
+	_defaultMaxTokens      = 3072
+	_defaultMaxConcurrency = 1
+	_defaultStripNewLines  = true
 )
 
 // Option is a function type that can be used to modify the client.
@@ -26,17 +30,47 @@ func WithStripNewLines(stripNewLines bool) Option {
 	}
 }
 
-// WithBatchSize is an option for specifying the batch size.
+// WithBatchSize is an option for specifying the maximum number of characters
+// of a text. Longer texts are split as with embeddings.WithMaxRunes. The
+// default is 512.
 func WithBatchSize(batchSize int) Option {
 	return func(p *VertexAIPaLM) {
 		p.BatchSize = batchSize
 	}
 }
 
+// WithBatchCount is an option for specifying the number of texts embedded by
+// a request. The default is 5, the maximum of the API.
+func WithBatchCount(batchCount int) Option {
+	return func(p *VertexAIPaLM) {
+		p.BatchCount = batchCount
+	}
+}
+
+// WithMaxTokens is an option for specifying the maximum number of tokens of a
+// text, as with embeddings.WithMaxTokens. The default is 3072, the maximum
+// input length of textembedding-gecko.
+func WithMaxTokens(maxTokens int) Option {
+	return func(p *VertexAIPaLM) {
+		p.MaxTokens = maxTokens
+	}
+}
+
+// WithMaxConcurrency is an option for specifying the maximum number of
+// requests sent concurrently.
+func WithMaxConcurrency(maxConcurrency int) Option {
+	return func(p *VertexAIPaLM) {
+		p.MaxConcurrency = maxConcurrency
+	}
+}
+
 func applyClientOptions(opts ...Option) (*VertexAIPaLM, error) {
 	v := &VertexAIPaLM{
-		StripNewLines: _defaultStripNewLines,
-		BatchSize:     _defaultBatchSize,
+		StripNewLines:  _defaultStripNewLines,
+		BatchSize:      _defaultBatchSize,
+		BatchCount:     _defaultBatchCount,
+		MaxTokens:      _defaultMaxTokens,
+		MaxConcurrency: _defaultMaxConcurrency,
 	}
 
 	for _, opt := range opts {
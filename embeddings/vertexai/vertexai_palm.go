@@ -12,8 +12,11 @@ import (
 type VertexAIPaLM struct { //nolint:revive
 	client *vertexai.LLM
 
-	StripNewLines bool
-	BatchSize     int
+	StripNewLines  bool
+	BatchSize      int
+	BatchCount     int
+	MaxTokens      int
+	MaxConcurrency int
 }
 
 var _ embeddings.Embedder = VertexAIPaLM{}
@@ -30,32 +33,11 @@ func NewVertexAIPaLM(opts ...Option) (*VertexAIPaLM, error) {
 
 // EmbedDocuments creates one vector embedding for each of the texts.
 func (e VertexAIPaLM) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
-	batchedTexts := embeddings.BatchTexts(
+	return embeddings.EmbedBatches(ctx,
 		embeddings.MaybeRemoveNewLines(texts, e.StripNewLines),
-		e.BatchSize,
+		e.client.CreateEmbedding,
+		e.batchOptions()...,
 	)
-
-	emb := make([][]float32, 0, len(texts))
-	for _, texts := range batchedTexts {
-		curTextEmbeddings, err := e.client.CreateEmbedding(ctx, texts)
-		if err != nil {
-			return nil, err
-		}
-
-		textLengths := make([]int, 0, len(texts))
-		for _, text := range texts {
-			textLengths = append(textLengths, len(text))
-		}
-
-		combined, err := embeddings.CombineVectors(curTextEmbeddings, textLengths)
-		if err != nil {
-			return nil, err
-		}
-
-		emb = append(emb, combined)
-	}
-
-	return emb, nil
 }
 
 // EmbedQuery embeds a single text.
@@ -64,10 +46,20 @@ func (e VertexAIPaLM) EmbedQuery(ctx context.Context, text string) ([]float32, e
 		text = strings.ReplaceAll(text, "\n", " ")
 	}
 
-	emb, err := e.client.CreateEmbedding(ctx, []string{text})
+	emb, err := embeddings.EmbedBatches(ctx, []string{text}, e.client.CreateEmbedding, e.batchOptions()...)
 	if err != nil {
 		return nil, err
 	}
 
 	return emb[0], nil
 }
+
+func (e VertexAIPaLM) batchOptions() []embeddings.BatchOption {
+	return []embeddings.BatchOption{
+		embeddings.WithBatchSize(e.BatchCount),
+		embeddings.WithMaxTokens(e.MaxTokens),
+		embeddings.WithMaxRunes(e.BatchSize),
+		embeddings.WithMaxConcurrency(e.MaxConcurrency),
+		embeddings.WithTokenCounter(e.client.GetNumTokens),
+	}
+}
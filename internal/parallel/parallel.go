@@ -0,0 +1,103 @@
+// Package parallel runs indexed tasks with bounded concurrency.
+package parallel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Run calls fn for each index from 0 to n-1, with at most limit calls running
+// at once, and waits for them to return. Calls that have not started when the
+// context is done are skipped and fail with the error of the context. Calls
+// that have not started when a call fails with an error that is not
+// retryable, as classified by llms.ClassifyError, are skipped, as they would
+// most likely fail the same way.
+//
+// Run returns nil if all the calls succeed, the error of the call if only one
+// fails, or else the errors of the calls joined in the order of their index.
+func Run(ctx context.Context, n, limit int, fn func(ctx context.Context, i int) error) error {
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > n {
+		limit = n
+	}
+
+	errs := make([]error, n)
+	if limit <= 1 {
+		for i := 0; i < n; i++ {
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+				continue
+			}
+			errs[i] = fn(ctx, i)
+			if terminal(errs[i]) {
+				break
+			}
+		}
+		return join(errs)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		stopped atomic.Bool
+	)
+	sem := make(chan struct{}, limit)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+		if stopped.Load() {
+			<-sem
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			<-sem
+			errs[i] = err
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = fn(ctx, i)
+			if terminal(errs[i]) {
+				stopped.Store(true)
+			}
+		}(i)
+	}
+	wg.Wait()
+	return join(errs)
+}
+
+// terminal reports whether err is an error that is not retryable. The errors
+// of the context are not, as the calls not started fail with them anyway.
+func terminal(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return !llms.ClassifyError(err).Retryable()
+}
+
+// join returns the only error of errs, or all of them joined.
+func join(errs []error) error {
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) == 1 {
+		return failed[0]
+	}
+	return errors.Join(failed...)
+}
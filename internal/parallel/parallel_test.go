@@ -0,0 +1,102 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestRunBoundsConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var running, peak atomic.Int32
+	results := make([]int, 20)
+	err := Run(context.Background(), len(results), 4, func(_ context.Context, i int) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		results[i] = i * i
+		return nil
+	})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, peak.Load(), int32(4))
+	for i, r := range results {
+		assert.Equal(t, i*i, r)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	t.Parallel()
+
+	// Retryable errors do not stop the other calls.
+	errOdd := &llms.StatusError{StatusCode: http.StatusServiceUnavailable, Message: "odd"}
+	err := Run(context.Background(), 5, 2, func(_ context.Context, i int) error {
+		if i%2 == 1 {
+			return fmt.Errorf("item %d: %w", i, errOdd)
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errOdd)
+	assert.Equal(t, "item 1: "+errOdd.Error()+"\nitem 3: "+errOdd.Error(), err.Error())
+
+	err = Run(context.Background(), 3, 1, func(_ context.Context, i int) error {
+		if i == 2 {
+			return errOdd
+		}
+		return nil
+	})
+	assert.Equal(t, errOdd, err)
+}
+
+func TestRunStopsAfterTerminalError(t *testing.T) {
+	t.Parallel()
+
+	errBadRequest := &llms.StatusError{StatusCode: http.StatusBadRequest, Message: "bad request"}
+	for _, limit := range []int{1, 2} {
+		var calls atomic.Int32
+		err := Run(context.Background(), 10, limit, func(_ context.Context, i int) error {
+			calls.Add(1)
+			if i == 1 {
+				return errBadRequest
+			}
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		})
+		assert.Equal(t, errBadRequest, err)
+		// The calls started before the error returned still run, but no
+		// call starts after it.
+		assert.Less(t, calls.Load(), int32(10), "limit %d", limit)
+		if limit == 1 {
+			assert.Equal(t, int32(2), calls.Load())
+		}
+	}
+}
+
+func TestRunContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	err := Run(ctx, 10, 1, func(_ context.Context, i int) error {
+		calls.Add(1)
+		if i == 2 {
+			cancel()
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(3), calls.Load())
+}
@@ -0,0 +1,145 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ErrValidation is wrapped by the errors returned by Validate.
+var ErrValidation = errors.New("validation failed")
+
+// ValidationError describes why a value does not match a schema.
+type ValidationError struct {
+	// Path is the location of the invalid value, such as "$.items[0].name".
+	Path string
+	// Message describes the failure.
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// Validate checks that the JSON data matches the definition. It supports the
+// subset of JSON Schema that Definition can describe: types, enums, object
+// properties, required properties and array items.
+func (d Definition) Validate(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return &ValidationError{Path: "$", Message: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	return d.ValidateValue(v)
+}
+
+// ValidateValue checks that a value decoded by encoding/json matches the
+// definition.
+func (d Definition) ValidateValue(v any) error {
+	return d.validate("$", v)
+}
+
+func (d Definition) validate(path string, v any) error { //nolint:cyclop
+	if d.Type != "" && !hasType(d.Type, v) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("expected %s, got %s", d.Type, typeOf(v))}
+	}
+	if len(d.Enum) > 0 {
+		s, ok := v.(string)
+		if !ok || !contains(d.Enum, s) {
+			return &ValidationError{
+				Path:    path,
+				Message: fmt.Sprintf("expected one of %s", strings.Join(d.Enum, ", ")),
+			}
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, name := range d.Required {
+			if _, ok := v[name]; !ok {
+				return &ValidationError{Path: path, Message: fmt.Sprintf("missing required property %q", name)}
+			}
+		}
+		// Validate the properties in a stable order to report the same error
+		// for the same value.
+		names := make([]string, 0, len(d.Properties))
+		for name := range d.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if value, ok := v[name]; ok {
+				if err := d.Properties[name].validate(path+"."+name, value); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		if d.Items == nil {
+			return nil
+		}
+		for i, item := range v {
+			if err := d.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func hasType(t DataType, v any) bool {
+	switch t {
+	case Object:
+		_, ok := v.(map[string]any)
+		return ok
+	case Array:
+		_, ok := v.([]any)
+		return ok
+	case String:
+		_, ok := v.(string)
+		return ok
+	case Number:
+		_, ok := v.(float64)
+		return ok
+	case Integer:
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case Boolean:
+		_, ok := v.(bool)
+		return ok
+	case Null:
+		return v == nil
+	}
+	return true
+}
+
+func typeOf(v any) DataType {
+	switch v.(type) {
+	case map[string]any:
+		return Object
+	case []any:
+		return Array
+	case string:
+		return String
+	case float64:
+		return Number
+	case bool:
+		return Boolean
+	}
+	return Null
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package jsonschema_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/tmc/langchaingo/jsonschema"
+)
+
+func TestDefinition_Validate(t *testing.T) {
+	t.Parallel()
+
+	def := Definition{
+		Type: Object,
+		Properties: map[string]Definition{
+			"name":  {Type: String},
+			"age":   {Type: Integer},
+			"color": {Type: String, Enum: []string{"red", "blue"}},
+			"tags":  {Type: Array, Items: &Definition{Type: String}},
+		},
+		Required: []string{"name"},
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		wantPath string
+	}{
+		{"valid", `{"name":"Ann","age":3,"color":"red","tags":["a"]}`, ""},
+		{"invalid JSON", `{"name":`, "$"},
+		{"wrong type", `[]`, "$"},
+		{"missing required", `{"age":3}`, "$"},
+		{"not an integer", `{"name":"Ann","age":3.5}`, "$.age"},
+		{"not in enum", `{"name":"Ann","color":"green"}`, "$.color"},
+		{"wrong item", `{"name":"Ann","tags":["a",1]}`, "$.tags[1]"},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := def.Validate([]byte(tc.data))
+			if tc.wantPath == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+			if verr.Path != tc.wantPath {
+				t.Errorf("got path %q, want %q", verr.Path, tc.wantPath)
+			}
+			if !errors.Is(err, ErrValidation) {
+				t.Errorf("error does not wrap ErrValidation")
+			}
+		})
+	}
+}
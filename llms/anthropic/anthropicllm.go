@@ -3,9 +3,11 @@ package anthropic
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 
 	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/internal/parallel"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/anthropic/internal/anthropicclient"
 	"github.com/tmc/langchaingo/schema"
@@ -49,7 +51,14 @@ func newClient(opts ...Option) (*anthropicclient.Client, error) {
 		return nil, ErrMissingToken
 	}
 
-	return anthropicclient.New(options.token, options.model)
+	var clientOpts []anthropicclient.Option
+	if options.baseURL != "" {
+		clientOpts = append(clientOpts, anthropicclient.WithBaseURL(options.baseURL))
+	}
+	if options.httpClient != nil {
+		clientOpts = append(clientOpts, anthropicclient.WithHTTPClient(options.httpClient))
+	}
+	return anthropicclient.New(options.token, options.model, clientOpts...)
 }
 
 // Call requests a completion for the given prompt.
@@ -74,31 +83,58 @@ func (o *LLM) Generate(ctx context.Context, prompts []string, options ...llms.Ca
 		opt(&opts)
 	}
 
-	generations := make([]*llms.Generation, 0, len(prompts))
-	for _, prompt := range prompts {
-		result, err := o.client.CreateCompletion(ctx, &anthropicclient.CompletionRequest{
-			Model:         opts.Model,
-			Prompt:        prompt,
-			MaxTokens:     opts.MaxTokens,
-			StopWords:     opts.StopWords,
-			Temperature:   opts.Temperature,
-			TopP:          opts.TopP,
-			StreamingFunc: opts.StreamingFunc,
-		})
+	generations := make([]*llms.Generation, len(prompts))
+	err := parallel.Run(ctx, len(prompts), opts.Concurrency(), func(ctx context.Context, i int) error {
+		generation, err := o.generate(ctx, prompts[i], opts)
 		if err != nil {
-			return nil, err
+			if len(prompts) > 1 {
+				err = fmt.Errorf("prompt %d: %w", i, err)
+			}
+			return err
 		}
-		generations = append(generations, &llms.Generation{
-			Text: result.Text,
-		})
+		generations[i] = generation
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	if o.CallbacksHandler != nil {
-		o.CallbacksHandler.HandleLLMEnd(ctx, llms.LLMResult{Generations: [][]*llms.Generation{generations}})
+		o.CallbacksHandler.HandleLLMEnd(ctx, llms.NewLLMResult(generations))
 	}
 	return generations, nil
 }
 
+// generate generates the completion of a prompt.
+func (o *LLM) generate(ctx context.Context, prompt string, opts llms.CallOptions) (*llms.Generation, error) {
+	prompt = llms.PromptWithResponseFormat(prompt, opts.ResponseFormat)
+	result, err := o.client.CreateCompletion(ctx, &anthropicclient.CompletionRequest{
+		Model:         opts.Model,
+		Prompt:        prompt,
+		MaxTokens:     opts.MaxTokens,
+		StopWords:     opts.StopWords,
+		Temperature:   opts.Temperature,
+		TopP:          opts.TopP,
+		StreamingFunc: opts.StreamingFunc,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Text, err = llms.ParseResponse(opts.ResponseFormat, result.Text)
+	if err != nil {
+		return nil, err
+	}
+	// The Anthropic completion API does not report the token usage.
+	usage := llms.EstimateUsage(result.Model, prompt, result.Text)
+	usage.FinishReason = result.StopReason
+	return &llms.Generation{
+		Text: result.Text,
+		GenerationInfo: map[string]any{
+			llms.UsageKey: usage,
+		},
+	}, nil
+}
+
 func (o *LLM) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
 	return llms.GeneratePrompt(ctx, o, promptValues, options...)
 }
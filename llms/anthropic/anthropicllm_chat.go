@@ -0,0 +1,179 @@
+package anthropic
+
+import (
+	"context"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/anthropic/internal/anthropicclient"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// Chat is an Anthropic chat LLM using the Messages API.
+type Chat struct {
+	CallbacksHandler callbacks.Handler
+	client           *anthropicclient.Client
+}
+
+var (
+	_ llms.ChatLLM       = (*Chat)(nil)
+	_ llms.LanguageModel = (*Chat)(nil)
+)
+
+// NewChat returns a new Anthropic chat LLM.
+func NewChat(opts ...Option) (*Chat, error) {
+	c, err := newClient(opts...)
+	return &Chat{
+		client: c,
+	}, err
+}
+
+// Call requests a chat response for the given messages.
+func (o *Chat) Call(ctx context.Context, messages []schema.ChatMessage, options ...llms.CallOption) (*schema.AIChatMessage, error) { // nolint: lll
+	r, err := o.Generate(ctx, [][]schema.ChatMessage{messages}, options...)
+	if err != nil {
+		return nil, err
+	}
+	if len(r) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	return r[0].Message, nil
+}
+
+// Generate requests a chat response for each of the sets of messages.
+func (o *Chat) Generate(ctx context.Context, messageSets [][]schema.ChatMessage, options ...llms.CallOption) ([]*llms.Generation, error) { // nolint:lll
+	if o.CallbacksHandler != nil {
+		o.CallbacksHandler.HandleLLMStart(ctx, getPromptsFromMessageSets(messageSets))
+	}
+
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	generations := make([]*llms.Generation, 0, len(messageSets))
+	for _, messageSet := range messageSets {
+		messageSet = llms.MessagesWithResponseFormat(messageSet, opts.ResponseFormat)
+		system, msgs := messagesToClientMessages(messageSet)
+		result, err := o.client.CreateMessage(ctx, &anthropicclient.MessageRequest{
+			Model:         opts.Model,
+			Messages:      msgs,
+			System:        system,
+			Temperature:   opts.Temperature,
+			MaxTokens:     opts.MaxTokens,
+			StopWords:     opts.StopWords,
+			TopP:          opts.TopP,
+			TopK:          opts.TopK,
+			StreamingFunc: opts.StreamingFunc,
+		})
+		if err != nil {
+			return nil, err
+		}
+		text, err := llms.ParseResponse(opts.ResponseFormat, result.Text())
+		if err != nil {
+			return nil, err
+		}
+		msg := &schema.AIChatMessage{
+			Content: text,
+		}
+		usage := llms.Usage{
+			PromptTokens:     result.Usage.InputTokens,
+			CompletionTokens: result.Usage.OutputTokens,
+			FinishReason:     result.StopReason,
+		}
+		usage = usage.OrEstimateChat(result.Model, messageSet, msg.Content)
+		generations = append(generations, &llms.Generation{
+			Message: msg,
+			Text:    msg.Content,
+			GenerationInfo: map[string]any{
+				"PromptTokens":     usage.PromptTokens,
+				"CompletionTokens": usage.CompletionTokens,
+				"TotalTokens":      usage.TotalTokens,
+				"StopReason":       result.StopReason,
+				llms.UsageKey:      usage,
+			},
+		})
+	}
+
+	if o.CallbacksHandler != nil {
+		o.CallbacksHandler.HandleLLMEnd(ctx, llms.NewLLMResult(generations))
+	}
+
+	return generations, nil
+}
+
+func (o *Chat) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
+	return llms.GenerateChatPrompt(ctx, o, promptValues, options...)
+}
+
+func (o *Chat) GetNumTokens(text string) int {
+	return llms.CountTokens(o.client.Model, text)
+}
+
+// _placeholderUserMessage is the user message sent before the history when it
+// starts with an assistant message, such as a conversation loaded from memory,
+// or when there are only system messages, as the API expects the first message
+// to be from the user.
+const _placeholderUserMessage = "(The conversation starts.)"
+
+// messagesToClientMessages converts the messages to the format of the
+// Messages API. System messages are returned as the system prompt. As the API
+// expects the roles to alternate, starting with the user, consecutive
+// messages of the same role are merged, and a placeholder user message is
+// prepended to a history starting with an assistant message or sent alone if
+// there are only system messages.
+func messagesToClientMessages(messages []schema.ChatMessage) (string, []*anthropicclient.ChatMessage) {
+	system := ""
+	msgs := make([]*anthropicclient.ChatMessage, 0, len(messages))
+	for _, m := range messages {
+		var role string
+		switch m.GetType() {
+		case schema.ChatMessageTypeSystem:
+			if system != "" {
+				system += "\n"
+			}
+			system += m.GetContent()
+			continue
+		case schema.ChatMessageTypeAI:
+			role = anthropicclient.RoleAssistant
+		case schema.ChatMessageTypeHuman, schema.ChatMessageTypeGeneric, schema.ChatMessageTypeFunction,
+			schema.ChatMessageTypeTool:
+			role = anthropicclient.RoleUser
+		}
+
+		if len(msgs) == 0 && role == anthropicclient.RoleAssistant {
+			msgs = append(msgs, &anthropicclient.ChatMessage{
+				Role:    anthropicclient.RoleUser,
+				Content: _placeholderUserMessage,
+			})
+		}
+		if len(msgs) > 0 && msgs[len(msgs)-1].Role == role {
+			msgs[len(msgs)-1].Content += "\n\n" + m.GetContent()
+			continue
+		}
+		msgs = append(msgs, &anthropicclient.ChatMessage{
+			Role:    role,
+			Content: m.GetContent(),
+		})
+	}
+	if len(msgs) == 0 {
+		msgs = append(msgs, &anthropicclient.ChatMessage{
+			Role:    anthropicclient.RoleUser,
+			Content: _placeholderUserMessage,
+		})
+	}
+	return system, msgs
+}
+
+func getPromptsFromMessageSets(messageSets [][]schema.ChatMessage) []string {
+	prompts := make([]string, 0, len(messageSets))
+	for i := 0; i < len(messageSets); i++ {
+		curPrompt := ""
+		for j := 0; j < len(messageSets[i]); j++ {
+			curPrompt += messageSets[i][j].GetContent()
+		}
+		prompts = append(prompts, curPrompt)
+	}
+
+	return prompts
+}
@@ -0,0 +1,171 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+const testStream = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","content":[],"model":"claude-2.1","stop_reason":null,"usage":{"input_tokens":10,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: ping
+data: {"type":"ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" world"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":2}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+
+func newTestChat(t *testing.T) (*Chat, *map[string]any) {
+	t.Helper()
+
+	var lastRequest map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/messages", r.URL.Path)
+		assert.Equal(t, "test-token", r.Header.Get("x-api-key"))
+
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		lastRequest = req
+
+		if stream, _ := req["stream"].(bool); stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte(testStream))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":          "msg_1",
+			"type":        "message",
+			"role":        "assistant",
+			"content":     []any{map[string]any{"type": "text", "text": "Hi there"}},
+			"model":       req["model"],
+			"stop_reason": "end_turn",
+			"usage":       map[string]any{"input_tokens": 12, "output_tokens": 3},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	chat, err := NewChat(WithToken("test-token"), WithBaseURL(server.URL))
+	require.NoError(t, err)
+	return chat, &lastRequest
+}
+
+func TestChatCall(t *testing.T) {
+	t.Parallel()
+	chat, lastRequest := newTestChat(t)
+
+	generations, err := chat.Generate(context.Background(), [][]schema.ChatMessage{{
+		schema.SystemChatMessage{Content: "Be brief."},
+		schema.HumanChatMessage{Content: "Hello"},
+		schema.AIChatMessage{Content: "Hi"},
+		schema.HumanChatMessage{Content: "How are you?"},
+		schema.HumanChatMessage{Content: "Answer please."},
+	}})
+	require.NoError(t, err)
+	require.Len(t, generations, 1)
+	assert.Equal(t, "Hi there", generations[0].Message.Content)
+	assert.Equal(t, "end_turn", generations[0].GenerationInfo["StopReason"])
+	assert.Equal(t, llms.Usage{
+		PromptTokens:     12,
+		CompletionTokens: 3,
+		TotalTokens:      15,
+		FinishReason:     "end_turn",
+		Model:            "claude-2.1",
+	}, generations[0].GenerationInfo[llms.UsageKey])
+
+	req := *lastRequest
+	assert.Equal(t, "Be brief.", req["system"])
+	assert.Equal(t, []any{
+		map[string]any{"role": "user", "content": "Hello"},
+		map[string]any{"role": "assistant", "content": "Hi"},
+		map[string]any{"role": "user", "content": "How are you?\n\nAnswer please."},
+	}, req["messages"])
+}
+
+func TestChatHistoryStartingWithAssistant(t *testing.T) {
+	t.Parallel()
+	chat, lastRequest := newTestChat(t)
+
+	_, err := chat.Generate(context.Background(), [][]schema.ChatMessage{{
+		schema.SystemChatMessage{Content: "Be brief."},
+		schema.AIChatMessage{Content: "How can I help?"},
+		schema.HumanChatMessage{Content: "Hello"},
+	}})
+	require.NoError(t, err)
+
+	req := *lastRequest
+	assert.Equal(t, []any{
+		map[string]any{"role": "user", "content": _placeholderUserMessage},
+		map[string]any{"role": "assistant", "content": "How can I help?"},
+		map[string]any{"role": "user", "content": "Hello"},
+	}, req["messages"])
+}
+
+func TestChatOnlySystemMessages(t *testing.T) {
+	t.Parallel()
+	chat, lastRequest := newTestChat(t)
+
+	_, err := chat.Call(context.Background(), []schema.ChatMessage{
+		schema.SystemChatMessage{Content: "Tell a joke."},
+	})
+	require.NoError(t, err)
+
+	req := *lastRequest
+	assert.Equal(t, "Tell a joke.", req["system"])
+	assert.Equal(t, []any{
+		map[string]any{"role": "user", "content": _placeholderUserMessage},
+	}, req["messages"])
+}
+
+func TestChatStreaming(t *testing.T) {
+	t.Parallel()
+	chat, _ := newTestChat(t)
+
+	var chunks []string
+	msg, err := chat.Call(context.Background(), []schema.ChatMessage{
+		schema.HumanChatMessage{Content: "Hello"},
+	}, llms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+		chunks = append(chunks, string(chunk))
+		return nil
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello world", msg.Content)
+	assert.Equal(t, []string{"Hello", " world"}, chunks)
+
+	generations, err := chat.Generate(context.Background(), [][]schema.ChatMessage{{
+		schema.HumanChatMessage{Content: "Hello"},
+	}}, llms.WithStreamingFunc(func(context.Context, []byte) error { return nil }))
+	require.NoError(t, err)
+	usage, ok := llms.GetUsage(generations[0])
+	require.True(t, ok)
+	assert.Equal(t, llms.Usage{
+		PromptTokens:     10,
+		CompletionTokens: 2,
+		TotalTokens:      12,
+		FinishReason:     "end_turn",
+		Model:            "claude-2.1",
+	}, usage)
+}
@@ -39,6 +39,15 @@ func WithHTTPClient(client Doer) Option {
 	}
 }
 
+// WithBaseURL allows setting the base URL of the Anthropic API.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) error {
+		c.baseURL = baseURL
+
+		return nil
+	}
+}
+
 // New returns a new Anthropic client.
 func New(token string, model string, opts ...Option) (*Client, error) {
 	c := &Client{
@@ -53,6 +62,9 @@ func New(token string, model string, opts ...Option) (*Client, error) {
 			return nil, err
 		}
 	}
+	if c.baseURL == "" {
+		c.baseURL = defaultBaseURL
+	}
 
 	return c, nil
 }
@@ -74,7 +86,9 @@ type CompletionRequest struct {
 
 // Completion is a completion.
 type Completion struct {
-	Text string `json:"text"`
+	Text       string `json:"text"`
+	StopReason string `json:"stop_reason"`
+	Model      string `json:"model"`
 }
 
 // CreateCompletion creates a completion.
@@ -93,7 +107,9 @@ func (c *Client) CreateCompletion(ctx context.Context, r *CompletionRequest) (*C
 		return nil, err
 	}
 	return &Completion{
-		Text: resp.Completion,
+		Text:       resp.Completion,
+		StopReason: resp.StopReason,
+		Model:      resp.Model,
 	}, nil
 }
 
@@ -5,11 +5,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strings"
+
+	"github.com/tmc/langchaingo/llms"
 )
 
 const (
@@ -79,10 +80,6 @@ func (c *Client) createCompletion(ctx context.Context, payload *completionPayloa
 		return nil, fmt.Errorf("marshal payload: %w", err)
 	}
 
-	if c.baseURL == "" {
-		c.baseURL = defaultBaseURL
-	}
-
 	url := fmt.Sprintf("%s/complete", c.baseURL)
 	// Build request
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payloadBytes))
@@ -100,16 +97,16 @@ func (c *Client) createCompletion(ctx context.Context, payload *completionPayloa
 	defer r.Body.Close()
 
 	if r.StatusCode != http.StatusOK {
-		msg := fmt.Sprintf("API returned unexpected status code: %d", r.StatusCode)
-
 		// No need to check the error here: if it fails, we'll just return the
 		// status code.
 		var errResp errorMessage
-		if err := json.NewDecoder(r.Body).Decode(&errResp); err != nil {
-			return nil, errors.New(msg) // nolint:goerr113
-		}
+		_ = json.NewDecoder(r.Body).Decode(&errResp)
 
-		return nil, fmt.Errorf("%s: %s", msg, errResp.Error.Message) // nolint:goerr113
+		return nil, &llms.StatusError{
+			StatusCode: r.StatusCode,
+			Message:    errResp.Error.Message,
+			Header:     r.Header,
+		}
 	}
 	if payload.StreamingFunc != nil {
 		// Read chunks
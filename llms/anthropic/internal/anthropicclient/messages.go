@@ -0,0 +1,212 @@
+package anthropicclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+const (
+	defaultChatModel = "claude-2.1"
+
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// ErrStream is returned when the stream of a response reports an error.
+var ErrStream = errors.New("stream error")
+
+// ChatMessage is a message of the Messages API.
+type ChatMessage struct {
+	// Role is the role of the author of the message, RoleUser or RoleAssistant.
+	Role string `json:"role"`
+	// Content is the text of the message.
+	Content string `json:"content"`
+}
+
+// MessageRequest is a request to create a message.
+type MessageRequest struct {
+	Model       string         `json:"model"`
+	Messages    []*ChatMessage `json:"messages"`
+	System      string         `json:"system,omitempty"`
+	Temperature float64        `json:"temperature,omitempty"`
+	MaxTokens   int            `json:"max_tokens"`
+	StopWords   []string       `json:"stop_sequences,omitempty"`
+	TopP        float64        `json:"top_p,omitempty"`
+	TopK        int            `json:"top_k,omitempty"`
+	Stream      bool           `json:"stream,omitempty"`
+
+	// StreamingFunc is a function to be called for each chunk of a streaming response.
+	// Return an error to stop streaming early.
+	StreamingFunc func(ctx context.Context, chunk []byte) error `json:"-"`
+}
+
+// MessageUsage is the token usage of a message.
+type MessageUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// ContentBlock is a block of the content of a message.
+type ContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// MessageResponse is a message created by the Messages API.
+type MessageResponse struct {
+	ID           string         `json:"id"`
+	Type         string         `json:"type"`
+	Role         string         `json:"role"`
+	Content      []ContentBlock `json:"content"`
+	Model        string         `json:"model"`
+	StopReason   string         `json:"stop_reason"`
+	StopSequence string         `json:"stop_sequence"`
+	Usage        MessageUsage   `json:"usage"`
+}
+
+// Text returns the concatenated text of the content blocks of the message.
+func (r *MessageResponse) Text() string {
+	var sb strings.Builder
+	for _, c := range r.Content {
+		if c.Type == "text" {
+			sb.WriteString(c.Text)
+		}
+	}
+	return sb.String()
+}
+
+// streamEvent is an event of a streamed message.
+type streamEvent struct {
+	Type    string           `json:"type"`
+	Message *MessageResponse `json:"message,omitempty"`
+	Delta   struct {
+		Type         string `json:"type"`
+		Text         string `json:"text"`
+		StopReason   string `json:"stop_reason"`
+		StopSequence string `json:"stop_sequence"`
+	} `json:"delta"`
+	Usage *MessageUsage `json:"usage,omitempty"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// CreateMessage creates a message with the Messages API.
+func (c *Client) CreateMessage(ctx context.Context, r *MessageRequest) (*MessageResponse, error) {
+	if r.Model == "" {
+		if c.Model == "" {
+			r.Model = defaultChatModel
+		} else {
+			r.Model = c.Model
+		}
+	}
+	if r.MaxTokens == 0 {
+		r.MaxTokens = 256
+	}
+	if r.StreamingFunc != nil {
+		r.Stream = true
+	}
+
+	payloadBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/messages", c.baseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	c.setHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// No need to check the error here: if it fails, we'll just return the
+		// status code.
+		var errResp errorMessage
+		_ = json.NewDecoder(resp.Body).Decode(&errResp)
+
+		return nil, &llms.StatusError{
+			StatusCode: resp.StatusCode,
+			Message:    errResp.Error.Message,
+			Header:     resp.Header,
+		}
+	}
+	if r.StreamingFunc != nil {
+		return parseStreamingMessageResponse(ctx, resp.Body, r)
+	}
+
+	var response MessageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	return &response, nil
+}
+
+func parseStreamingMessageResponse(ctx context.Context, body io.Reader, r *MessageRequest) (*MessageResponse, error) { // nolint:lll,cyclop
+	response := &MessageResponse{}
+	text := ""
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+
+		var event streamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, fmt.Errorf("parse stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				response = event.Message
+			}
+		case "content_block_delta":
+			if event.Delta.Type != "text_delta" {
+				continue
+			}
+			text += event.Delta.Text
+			if err := r.StreamingFunc(ctx, []byte(event.Delta.Text)); err != nil {
+				return nil, fmt.Errorf("streaming func returned an error: %w", err)
+			}
+		case "message_delta":
+			response.StopReason = event.Delta.StopReason
+			response.StopSequence = event.Delta.StopSequence
+			if event.Usage != nil {
+				response.Usage.OutputTokens = event.Usage.OutputTokens
+			}
+		case "message_stop":
+			response.Content = []ContentBlock{{Type: "text", Text: text}}
+			return response, nil
+		case "error":
+			if event.Error != nil {
+				return nil, fmt.Errorf("%w: %s: %s", ErrStream, event.Error.Type, event.Error.Message)
+			}
+			return nil, ErrStream
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read stream: %w", err)
+	}
+	return nil, fmt.Errorf("%w: %w", ErrStream, io.ErrUnexpectedEOF)
+}
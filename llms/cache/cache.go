@@ -0,0 +1,69 @@
+// Package cache provides wrappers that cache the responses of LLMs, chat LLMs
+// and language models.
+//
+// Responses are stored in a pluggable Cache keyed by the prompt (or messages)
+// and the call options that influence the result, such as the model, the
+// temperature, the stop words and the functions. The package ships with an
+// in-memory LRU cache and a cache that stores entries as files on disk.
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrEmptyResponse is returned when the wrapped model returns no generations.
+var ErrEmptyResponse = errors.New("no response")
+
+// Cache is a key-value store for cached responses.
+type Cache interface {
+	// Get returns the value stored for the key and whether it was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the value for the key. A ttl of zero means the entry does
+	// not expire.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+type options struct {
+	ttl             time.Duration
+	namespace       string
+	bypassStreaming bool
+}
+
+// Option is a function that configures a cache wrapper.
+type Option func(*options)
+
+// WithTTL sets how long cached responses are valid. If not set, cached
+// responses do not expire.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithNamespace sets a namespace that is part of every key. Use it to keep the
+// responses of different providers or client configurations apart when they
+// share a Cache.
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithBypassStreaming makes calls with a streaming function skip the cache.
+// If not set, a cached response is passed to the streaming function as a
+// single chunk.
+func WithBypassStreaming() Option {
+	return func(o *options) {
+		o.bypassStreaming = true
+	}
+}
+
+func applyOptions(opts ...Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/fake"
+	"github.com/tmc/langchaingo/prompts"
+	"github.com/tmc/langchaingo/schema"
+)
+
+func TestLLM(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	inner := fake.New(fake.WithResponses("a", "b", "c", "d"))
+	llm := NewLLM(inner, NewInMemory(10))
+
+	out, err := llm.Call(ctx, "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "a", out)
+
+	out, err = llm.Call(ctx, "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "a", out)
+
+	// Call options that change the response are part of the key.
+	out, err = llm.Call(ctx, "hi", llms.WithTemperature(0.5))
+	require.NoError(t, err)
+	assert.Equal(t, "b", out)
+
+	gens, err := llm.Generate(ctx, []string{"hi", "there", "again"})
+	require.NoError(t, err)
+	require.Len(t, gens, 3)
+	assert.Equal(t, []string{"a", "c", "d"}, []string{gens[0].Text, gens[1].Text, gens[2].Text})
+	assert.Equal(t, []string{"hi", "hi", "there", "again"}, inner.Prompts())
+}
+
+func TestLLMStreaming(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	var chunks []string
+	streamingFunc := llms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+		chunks = append(chunks, string(chunk))
+		return nil
+	})
+
+	inner := fake.New(fake.WithResponses("hello world", "bypassed"))
+	llm := NewLLM(inner, NewInMemory(10))
+	_, err := llm.Call(ctx, "hi", streamingFunc)
+	require.NoError(t, err)
+	_, err = llm.Call(ctx, "hi", streamingFunc)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hello", " world", "hello world"}, chunks)
+
+	llm = NewLLM(inner, llm.cache, WithBypassStreaming())
+	out, err := llm.Call(ctx, "hi", streamingFunc)
+	require.NoError(t, err)
+	assert.Equal(t, "bypassed", out)
+}
+
+func TestLanguageModelStreaming(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	var chunks []string
+	streamingFunc := llms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+		chunks = append(chunks, string(chunk))
+		return nil
+	})
+
+	inner := fake.New(fake.WithResponses("hello world"))
+	model := NewLanguageModel(inner, NewInMemory(10))
+	prompt := []schema.PromptValue{prompts.StringPromptValue("hi")}
+	for i := 0; i < 2; i++ {
+		result, err := model.GeneratePrompt(ctx, prompt, streamingFunc)
+		require.NoError(t, err)
+		assert.Equal(t, "hello world", result.Generations[0][0].Text)
+	}
+	// The cached response is streamed as a single chunk.
+	assert.Equal(t, []string{"hello", " world", "hello world"}, chunks)
+	assert.Len(t, inner.Prompts(), 1)
+}
+
+func TestChat(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	inner := fake.NewChat(fake.WithResponses("first", "second"))
+	chat := NewChat(inner, NewInMemory(10))
+
+	messages := []schema.ChatMessage{schema.HumanChatMessage{Content: "hi"}}
+	for i := 0; i < 2; i++ {
+		msg, err := chat.Call(ctx, messages)
+		require.NoError(t, err)
+		assert.Equal(t, "first", msg.Content)
+	}
+
+	msg, err := chat.Call(ctx, []schema.ChatMessage{schema.SystemChatMessage{Content: "hi"}})
+	require.NoError(t, err)
+	assert.Equal(t, "second", msg.Content)
+}
+
+func TestKeyMessagesPointers(t *testing.T) {
+	t.Parallel()
+
+	call := func(location string) *schema.AIChatMessage {
+		return &schema.AIChatMessage{FunctionCall: &schema.FunctionCall{
+			Name:      "get_weather",
+			Arguments: `{"location": "` + location + `"}`,
+		}}
+	}
+	paris, err := key("", "chat", newKeyMessages([]schema.ChatMessage{call("Paris")}), llms.CallOptions{})
+	require.NoError(t, err)
+	rome, err := key("", "chat", newKeyMessages([]schema.ChatMessage{call("Rome")}), llms.CallOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, paris, rome)
+
+	value, err := key("", "chat", newKeyMessages([]schema.ChatMessage{*call("Paris")}), llms.CallOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, paris, value)
+
+	first, err := key("", "chat", newKeyMessages([]schema.ChatMessage{
+		&schema.ToolChatMessage{ID: "call_1", Content: "sunny"},
+	}), llms.CallOptions{})
+	require.NoError(t, err)
+	second, err := key("", "chat", newKeyMessages([]schema.ChatMessage{
+		&schema.ToolChatMessage{ID: "call_2", Content: "sunny"},
+	}), llms.CallOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+}
+
+func TestInMemory(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	now := time.Now()
+	c := NewInMemory(2)
+	c.now = func() time.Time { return now }
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), 0))
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Minute))
+	_, ok, _ := c.Get(ctx, "a")
+	assert.True(t, ok)
+
+	// "b" is the least recently used entry.
+	require.NoError(t, c.Set(ctx, "c", []byte("3"), 0))
+	_, ok, _ = c.Get(ctx, "b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	require.NoError(t, c.Set(ctx, "d", []byte("4"), time.Minute))
+	now = now.Add(time.Minute)
+	_, ok, _ = c.Get(ctx, "d")
+	assert.False(t, ok)
+}
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	c, err := NewFile(t.TempDir())
+	require.NoError(t, err)
+
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	_, ok, err := c.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, c.Set(ctx, "key", []byte("value"), time.Minute))
+	v, ok, err := c.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value"), v)
+
+	now = now.Add(time.Hour)
+	_, ok, err = c.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// Chat wraps an llms.ChatLLM and caches its responses.
+type Chat struct {
+	chat llms.ChatLLM
+	wrapper
+}
+
+var (
+	_ llms.ChatLLM       = (*Chat)(nil)
+	_ llms.LanguageModel = (*Chat)(nil)
+)
+
+// NewChat returns a new Chat that caches the responses of the given chat LLM in c.
+func NewChat(chat llms.ChatLLM, c Cache, opts ...Option) *Chat {
+	return &Chat{
+		chat:    chat,
+		wrapper: wrapper{cache: c, opts: applyOptions(opts...)},
+	}
+}
+
+// Call returns the cached chat response for the messages or requests a new one.
+func (c *Chat) Call(ctx context.Context, messages []schema.ChatMessage, options ...llms.CallOption) (*schema.AIChatMessage, error) { // nolint: lll
+	r, err := c.Generate(ctx, [][]schema.ChatMessage{messages}, options...)
+	if err != nil {
+		return nil, err
+	}
+	if len(r) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	return r[0].Message, nil
+}
+
+// Generate returns the cached chat responses for the sets of messages and
+// requests the missing ones from the wrapped chat LLM in a single call.
+func (c *Chat) Generate(ctx context.Context, messageSets [][]schema.ChatMessage, options ...llms.CallOption) ([]*llms.Generation, error) { // nolint:lll
+	inputs := make([]any, len(messageSets))
+	for i, messages := range messageSets {
+		inputs[i] = newKeyMessages(messages)
+	}
+	return c.generate(ctx, "chat", inputs, options, func(missing []int) ([]*llms.Generation, error) {
+		missingSets := make([][]schema.ChatMessage, 0, len(missing))
+		for _, i := range missing {
+			missingSets = append(missingSets, messageSets[i])
+		}
+		return c.chat.Generate(ctx, missingSets, options...)
+	})
+}
+
+func (c *Chat) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
+	return llms.GenerateChatPrompt(ctx, c, promptValues, options...)
+}
+
+func (c *Chat) GetNumTokens(text string) int {
+	if lm, ok := c.chat.(llms.LanguageModel); ok {
+		return lm.GetNumTokens(text)
+	}
+	return llms.CountTokens("", text)
+}
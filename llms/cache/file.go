@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File is a Cache that stores every entry as a file in a directory, so that
+// cached responses survive restarts.
+type File struct {
+	dir string
+	now func() time.Time
+}
+
+var _ Cache = (*File)(nil)
+
+type fileEntry struct {
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	Value     []byte    `json:"value"`
+}
+
+// NewFile returns a new file cache storing its entries in dir. The directory
+// is created if it does not exist.
+func NewFile(dir string) (*File, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil { //nolint:gomnd
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+	return &File{dir: dir, now: time.Now}, nil
+}
+
+// Get returns the value stored for the key. Expired entries are removed.
+func (c *File) Get(_ context.Context, key string) ([]byte, bool, error) {
+	path := c.path(key)
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("read cache entry: %w", err)
+	}
+
+	var entry fileEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return nil, false, fmt.Errorf("parse cache entry: %w", err)
+	}
+	if !entry.ExpiresAt.IsZero() && !c.now().Before(entry.ExpiresAt) {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, false, fmt.Errorf("remove cache entry: %w", err)
+		}
+		return nil, false, nil
+	}
+	return entry.Value, true, nil
+}
+
+// Set stores the value for the key. The entry is written to a temporary file
+// first and then renamed, so that readers never see a partial entry.
+func (c *File) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := fileEntry{Value: value}
+	if ttl > 0 {
+		entry.ExpiresAt = c.now().Add(ttl)
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal cache entry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	return nil
+}
+
+// path returns the file name for the key. Keys are hashed so that arbitrary
+// keys map to valid file names.
+func (c *File) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// keyOptions are the call options that influence the response.
+type keyOptions struct {
+	Model                string                    `json:"model,omitempty"`
+	MaxTokens            int                       `json:"max_tokens,omitempty"`
+	Temperature          float64                   `json:"temperature,omitempty"`
+	StopWords            []string                  `json:"stop_words,omitempty"`
+	TopK                 int                       `json:"top_k,omitempty"`
+	TopP                 float64                   `json:"top_p,omitempty"`
+	Seed                 int                       `json:"seed,omitempty"`
+	MinLength            int                       `json:"min_length,omitempty"`
+	MaxLength            int                       `json:"max_length,omitempty"`
+	N                    int                       `json:"n,omitempty"`
+	RepetitionPenalty    float64                   `json:"repetition_penalty,omitempty"`
+	FrequencyPenalty     float64                   `json:"frequency_penalty,omitempty"`
+	PresencePenalty      float64                   `json:"presence_penalty,omitempty"`
+	LogProbs             bool                      `json:"logprobs,omitempty"`
+	TopLogProbs          int                       `json:"top_logprobs,omitempty"`
+	FunctionCallBehavior llms.FunctionCallBehavior `json:"function_call,omitempty"`
+	Functions            []llms.FunctionDefinition `json:"functions,omitempty"`
+	Tools                []llms.Tool               `json:"tools,omitempty"`
+	ToolChoice           any                       `json:"tool_choice,omitempty"`
+	ResponseFormat       *llms.ResponseFormat      `json:"response_format,omitempty"`
+}
+
+func newKeyOptions(opts llms.CallOptions) keyOptions {
+	return keyOptions{
+		Model:                opts.Model,
+		MaxTokens:            opts.MaxTokens,
+		Temperature:          opts.Temperature,
+		StopWords:            opts.StopWords,
+		TopK:                 opts.TopK,
+		TopP:                 opts.TopP,
+		Seed:                 opts.Seed,
+		MinLength:            opts.MinLength,
+		MaxLength:            opts.MaxLength,
+		N:                    opts.N,
+		RepetitionPenalty:    opts.RepetitionPenalty,
+		FrequencyPenalty:     opts.FrequencyPenalty,
+		PresencePenalty:      opts.PresencePenalty,
+		LogProbs:             opts.LogProbs,
+		TopLogProbs:          opts.TopLogProbs,
+		FunctionCallBehavior: opts.FunctionCallBehavior,
+		Functions:            opts.Functions,
+		Tools:                opts.Tools,
+		ToolChoice:           opts.ToolChoice,
+		ResponseFormat:       opts.ResponseFormat,
+	}
+}
+
+// keyMessage is the part of a chat message that is part of the key.
+type keyMessage struct {
+	Type    schema.ChatMessageType `json:"type"`
+	Content string                 `json:"content"`
+	Name    string                 `json:"name,omitempty"`
+	Extra   any                    `json:"extra,omitempty"`
+}
+
+func newKeyMessages(messages []schema.ChatMessage) []keyMessage {
+	keyMessages := make([]keyMessage, 0, len(messages))
+	for _, m := range messages {
+		// Pointers, such as the messages returned by ChatLLM.Call, are keyed
+		// as the messages they point to.
+		switch p := m.(type) {
+		case *schema.AIChatMessage:
+			if p != nil {
+				m = *p
+			}
+		case *schema.ToolChatMessage:
+			if p != nil {
+				m = *p
+			}
+		}
+		km := keyMessage{Type: m.GetType(), Content: m.GetContent()}
+		if n, ok := m.(schema.Named); ok {
+			km.Name = n.GetName()
+		}
+		if ai, ok := m.(schema.AIChatMessage); ok && ai.FunctionCall != nil {
+			km.Extra = ai.FunctionCall
+		}
+		if ai, ok := m.(schema.AIChatMessage); ok && len(ai.ToolCalls) > 0 {
+			km.Extra = ai.ToolCalls
+		}
+		if mp, ok := m.(schema.MultiPart); ok && schema.HasMedia(mp.GetParts()) {
+			km.Extra = mp.GetParts()
+		}
+		if tool, ok := m.(schema.ToolChatMessage); ok {
+			km.Name = tool.ID
+		}
+		keyMessages = append(keyMessages, km)
+	}
+	return keyMessages
+}
+
+// key returns the hex encoded SHA-256 hash of the JSON encoding of the parts.
+func key(namespace, kind string, input any, opts llms.CallOptions) (string, error) {
+	b, err := json.Marshal(struct {
+		Namespace string     `json:"namespace,omitempty"`
+		Kind      string     `json:"kind"`
+		Input     any        `json:"input"`
+		Options   keyOptions `json:"options"`
+	}{
+		Namespace: namespace,
+		Kind:      kind,
+		Input:     input,
+		Options:   newKeyOptions(opts),
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
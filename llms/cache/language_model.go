@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// LanguageModel wraps an llms.LanguageModel and caches the results of
+// GeneratePrompt. Use it for models that implement neither llms.LLM nor
+// llms.ChatLLM; for the others prefer LLM and Chat, which cache every prompt
+// separately.
+type LanguageModel struct {
+	model llms.LanguageModel
+	wrapper
+}
+
+var _ llms.LanguageModel = (*LanguageModel)(nil)
+
+// NewLanguageModel returns a new LanguageModel that caches the results of the
+// given language model in c.
+func NewLanguageModel(model llms.LanguageModel, c Cache, opts ...Option) *LanguageModel {
+	return &LanguageModel{
+		model:   model,
+		wrapper: wrapper{cache: c, opts: applyOptions(opts...)},
+	}
+}
+
+// GeneratePrompt returns the cached result for the prompt values or requests a
+// new one from the wrapped language model.
+func (l *LanguageModel) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	if opts.StreamingFunc != nil && l.opts.bypassStreaming {
+		return l.model.GeneratePrompt(ctx, promptValues, options...)
+	}
+
+	type keyPromptValue struct {
+		Text     string       `json:"text"`
+		Messages []keyMessage `json:"messages"`
+	}
+	input := make([]keyPromptValue, 0, len(promptValues))
+	for _, pv := range promptValues {
+		input = append(input, keyPromptValue{Text: pv.String(), Messages: newKeyMessages(pv.Messages())})
+	}
+	k, err := key(l.opts.namespace, "prompt", input, opts)
+	if err != nil {
+		return llms.LLMResult{}, fmt.Errorf("compute cache key: %w", err)
+	}
+
+	b, ok, err := l.cache.Get(ctx, k)
+	if err != nil {
+		return llms.LLMResult{}, fmt.Errorf("get cached response: %w", err)
+	}
+	if ok {
+		var result llms.LLMResult
+		if err := json.Unmarshal(b, &result); err != nil {
+			return llms.LLMResult{}, fmt.Errorf("parse cached response: %w", err)
+		}
+		if opts.StreamingFunc != nil {
+			for _, generations := range result.Generations {
+				for _, g := range generations {
+					if err := opts.StreamingFunc(ctx, []byte(g.Text)); err != nil {
+						return llms.LLMResult{}, fmt.Errorf("streaming func returned an error: %w", err)
+					}
+				}
+			}
+		}
+		return result, nil
+	}
+
+	result, err := l.model.GeneratePrompt(ctx, promptValues, options...)
+	if err != nil {
+		return result, err
+	}
+	b, err = json.Marshal(result)
+	if err != nil {
+		return result, fmt.Errorf("marshal response: %w", err)
+	}
+	if err := l.cache.Set(ctx, k, b, l.opts.ttl); err != nil {
+		return result, fmt.Errorf("cache response: %w", err)
+	}
+	return result, nil
+}
+
+func (l *LanguageModel) GetNumTokens(text string) int {
+	return l.model.GetNumTokens(text)
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// LLM wraps an llms.LLM and caches its responses.
+type LLM struct {
+	llm llms.LLM
+	wrapper
+}
+
+var (
+	_ llms.LLM           = (*LLM)(nil)
+	_ llms.LanguageModel = (*LLM)(nil)
+)
+
+// NewLLM returns a new LLM that caches the responses of the given LLM in c.
+func NewLLM(llm llms.LLM, c Cache, opts ...Option) *LLM {
+	return &LLM{
+		llm:     llm,
+		wrapper: wrapper{cache: c, opts: applyOptions(opts...)},
+	}
+}
+
+// Call returns the cached completion for the prompt or requests a new one.
+func (l *LLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	r, err := l.Generate(ctx, []string{prompt}, options...)
+	if err != nil {
+		return "", err
+	}
+	if len(r) == 0 {
+		return "", ErrEmptyResponse
+	}
+	return r[0].Text, nil
+}
+
+// Generate returns the cached completions for the prompts and requests the
+// missing ones from the wrapped LLM in a single call.
+func (l *LLM) Generate(ctx context.Context, prompts []string, options ...llms.CallOption) ([]*llms.Generation, error) {
+	inputs := make([]any, len(prompts))
+	for i, prompt := range prompts {
+		inputs[i] = prompt
+	}
+	return l.generate(ctx, "llm", inputs, options, func(missing []int) ([]*llms.Generation, error) {
+		missingPrompts := make([]string, 0, len(missing))
+		for _, i := range missing {
+			missingPrompts = append(missingPrompts, prompts[i])
+		}
+		return l.llm.Generate(ctx, missingPrompts, options...)
+	})
+}
+
+func (l *LLM) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
+	return llms.GeneratePrompt(ctx, l, promptValues, options...)
+}
+
+func (l *LLM) GetNumTokens(text string) int {
+	if lm, ok := l.llm.(llms.LanguageModel); ok {
+		return lm.GetNumTokens(text)
+	}
+	return llms.CountTokens("", text)
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const _defaultInMemoryCapacity = 1024
+
+// InMemory is an in-memory Cache that evicts the least recently used entry
+// once it holds more entries than its capacity. It is safe for concurrent use.
+type InMemory struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+var _ Cache = (*InMemory)(nil)
+
+type inMemoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewInMemory returns a new in-memory LRU cache holding at most capacity
+// entries. If capacity is not positive, the default value 1024 is used.
+func NewInMemory(capacity int) *InMemory {
+	if capacity <= 0 {
+		capacity = _defaultInMemoryCapacity
+	}
+	return &InMemory{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Get returns the value stored for the key.
+func (c *InMemory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry, _ := elem.Value.(*inMemoryEntry)
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+// Set stores the value for the key, evicting the least recently used entry
+// if the cache is full.
+func (c *InMemory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &inMemoryEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expiresAt = c.now().Add(ttl)
+	}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		oldestEntry, _ := oldest.Value.(*inMemoryEntry)
+		delete(c.entries, oldestEntry.key)
+	}
+	return nil
+}
+
+// Len returns the number of entries in the cache.
+func (c *InMemory) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// ErrUnexpectedResponseLength is returned when the wrapped model returns a
+// different number of generations than it was asked for.
+var ErrUnexpectedResponseLength = errors.New("unexpected length of response")
+
+// wrapper holds the state shared by the LLM and Chat wrappers.
+type wrapper struct {
+	cache Cache
+	opts  options
+}
+
+// generate returns a generation for each input. Cached generations are read
+// from the cache, the others are requested with call, which receives the
+// indices of the inputs that were not found, and then stored.
+func (w wrapper) generate(
+	ctx context.Context,
+	kind string,
+	inputs []any,
+	options []llms.CallOption,
+	call func(missing []int) ([]*llms.Generation, error),
+) ([]*llms.Generation, error) {
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	missing := make([]int, 0, len(inputs))
+	if opts.StreamingFunc != nil && w.opts.bypassStreaming {
+		for i := range inputs {
+			missing = append(missing, i)
+		}
+		return call(missing)
+	}
+
+	keys := make([]string, len(inputs))
+	generations := make([]*llms.Generation, len(inputs))
+	for i, input := range inputs {
+		k, err := key(w.opts.namespace, kind, input, opts)
+		if err != nil {
+			return nil, fmt.Errorf("compute cache key: %w", err)
+		}
+		keys[i] = k
+
+		generation, err := w.get(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+		if generation == nil {
+			missing = append(missing, i)
+			continue
+		}
+		generations[i] = generation
+		if opts.StreamingFunc != nil {
+			if err := opts.StreamingFunc(ctx, []byte(generation.Text)); err != nil {
+				return nil, fmt.Errorf("streaming func returned an error: %w", err)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return generations, nil
+	}
+
+	result, err := call(missing)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != len(missing) {
+		if len(missing) == len(inputs) {
+			// Nothing was cached, so the response can be passed through as is.
+			return result, nil
+		}
+		return nil, ErrUnexpectedResponseLength
+	}
+	for j, i := range missing {
+		generations[i] = result[j]
+		if err := w.set(ctx, keys[i], result[j]); err != nil {
+			return nil, err
+		}
+	}
+	return generations, nil
+}
+
+func (w wrapper) get(ctx context.Context, key string) (*llms.Generation, error) {
+	b, ok, err := w.cache.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("get cached response: %w", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	var generation llms.Generation
+	if err := json.Unmarshal(b, &generation); err != nil {
+		return nil, fmt.Errorf("parse cached response: %w", err)
+	}
+	return &generation, nil
+}
+
+func (w wrapper) set(ctx context.Context, key string, generation *llms.Generation) error {
+	if generation == nil {
+		return nil
+	}
+	b, err := json.Marshal(generation)
+	if err != nil {
+		return fmt.Errorf("marshal response: %w", err)
+	}
+	if err := w.cache.Set(ctx, key, b, w.opts.ttl); err != nil {
+		return fmt.Errorf("cache response: %w", err)
+	}
+	return nil
+}
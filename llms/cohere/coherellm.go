@@ -3,9 +3,11 @@ package cohere
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 
 	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/internal/parallel"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/cohere/internal/cohereclient"
 	"github.com/tmc/langchaingo/schema"
@@ -49,28 +51,85 @@ func (o *LLM) Generate(ctx context.Context, prompts []string, options ...llms.Ca
 		opt(&opts)
 	}
 
-	generations := make([]*llms.Generation, 0, len(prompts))
-
-	for _, prompt := range prompts {
-		result, err := o.client.CreateGeneration(ctx, &cohereclient.GenerationRequest{
-			Prompt: prompt,
-		})
+	generations := make([]*llms.Generation, len(prompts))
+	err := parallel.Run(ctx, len(prompts), opts.Concurrency(), func(ctx context.Context, i int) error {
+		generation, err := o.generate(ctx, prompts[i], opts)
 		if err != nil {
-			return nil, err
+			if len(prompts) > 1 {
+				err = fmt.Errorf("prompt %d: %w", i, err)
+			}
+			return err
 		}
-
-		generations = append(generations, &llms.Generation{
-			Text: result.Text,
-		})
+		generations[i] = generation
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	if o.CallbacksHandler != nil {
-		o.CallbacksHandler.HandleLLMEnd(ctx, llms.LLMResult{Generations: [][]*llms.Generation{generations}})
+		o.CallbacksHandler.HandleLLMEnd(ctx, llms.NewLLMResult(generations))
 	}
 
 	return generations, nil
 }
 
+// generate generates the completion of a prompt.
+func (o *LLM) generate(ctx context.Context, prompt string, opts llms.CallOptions) (*llms.Generation, error) {
+	prompt = llms.PromptWithResponseFormat(prompt, opts.ResponseFormat)
+	result, err := o.client.CreateGeneration(ctx, &cohereclient.GenerationRequest{
+		Prompt:            prompt,
+		NumGenerations:    opts.N,
+		ReturnLikelihoods: opts.LogProbs,
+		StreamingFunc:     opts.StreamingFunc,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Text, err = llms.ParseResponse(opts.ResponseFormat, result.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := llms.Usage{
+		PromptTokens:     result.InputTokens,
+		CompletionTokens: result.OutputTokens,
+		FinishReason:     result.FinishReason,
+	}
+	generation := &llms.Generation{
+		Text: result.Text,
+		GenerationInfo: map[string]any{
+			llms.UsageKey: usage.OrEstimate(opts.Model, prompt, result.Text),
+		},
+		LogProbs: likelihoodsToLogProbs(result.TokenLikelihoods),
+	}
+	for _, candidate := range result.Candidates {
+		text, err := llms.ParseResponse(opts.ResponseFormat, candidate.Text)
+		if err != nil {
+			return nil, err
+		}
+		generation.Candidates = append(generation.Candidates, &llms.Generation{
+			Text:           text,
+			GenerationInfo: map[string]any{"FinishReason": candidate.FinishReason},
+			LogProbs:       likelihoodsToLogProbs(candidate.TokenLikelihoods),
+		})
+	}
+	return generation, nil
+}
+
+// likelihoodsToLogProbs converts the token likelihoods of a generation, which
+// are log probabilities.
+func likelihoodsToLogProbs(likelihoods []cohereclient.TokenLikelihood) []llms.TokenLogProb {
+	if len(likelihoods) == 0 {
+		return nil
+	}
+	logProbs := make([]llms.TokenLogProb, 0, len(likelihoods))
+	for _, l := range likelihoods {
+		logProbs = append(logProbs, llms.TokenLogProb{Token: l.Token, LogProb: l.Likelihood})
+	}
+	return logProbs
+}
+
 func (o *LLM) GetNumTokens(text string) int {
 	return o.client.GetNumTokens(text)
 }
@@ -1,6 +1,7 @@
 package cohereclient
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -10,8 +11,11 @@ import (
 	"strings"
 
 	"github.com/cohere-ai/tokenizer"
+	"github.com/tmc/langchaingo/llms"
 )
 
+const defaultBaseURL = "https://api.cohere.ai"
+
 var (
 	ErrEmptyResponse = errors.New("empty response")
 	ErrModelNotFound = errors.New("model not found")
@@ -47,6 +51,9 @@ func New(token string, baseURL string, model string, opts ...Option) (*Client, e
 		return nil, fmt.Errorf("create tokenizer: %w", err)
 	}
 
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
 	c := &Client{
 		token:      token,
 		baseURL:    baseURL,
@@ -66,34 +73,79 @@ func New(token string, baseURL string, model string, opts ...Option) (*Client, e
 
 type GenerationRequest struct {
 	Prompt string `json:"prompt"`
+	// NumGenerations is the number of generations to return.
+	NumGenerations int `json:"num_generations,omitempty"`
+	// ReturnLikelihoods is whether to return the log likelihoods of the
+	// generated tokens.
+	ReturnLikelihoods bool `json:"return_likelihoods,omitempty"`
+
+	// StreamingFunc is a function to be called for each chunk of a streaming response.
+	// Return an error to stop streaming early.
+	StreamingFunc func(ctx context.Context, chunk []byte) error `json:"-"`
 }
 
 type Generation struct {
-	Text string `json:"text"`
+	Text         string `json:"text"`
+	FinishReason string `json:"finish_reason"`
+	InputTokens  int    `json:"input_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+	// TokenLikelihoods are the log likelihoods of the generated tokens, if
+	// requested.
+	TokenLikelihoods []TokenLikelihood `json:"token_likelihoods,omitempty"`
+	// Candidates are the other generations, if more than one was requested.
+	Candidates []*Generation `json:"candidates,omitempty"`
+}
+
+// TokenLikelihood is the log likelihood of a token.
+type TokenLikelihood struct {
+	Token      string  `json:"token"`
+	Likelihood float64 `json:"likelihood"`
 }
 
 type generateRequestPayload struct {
-	Prompt string `json:"prompt"`
-	Model  string `json:"model"`
+	Prompt            string `json:"prompt"`
+	Model             string `json:"model"`
+	Stream            bool   `json:"stream,omitempty"`
+	NumGenerations    int    `json:"num_generations,omitempty"`
+	ReturnLikelihoods string `json:"return_likelihoods,omitempty"`
 }
 
 type generateResponsePayload struct {
 	ID          string `json:"id,omitempty"`
 	Message     string `json:"message,omitempty"`
 	Generations []struct {
-		ID   string `json:"id,omitempty"`
-		Text string `json:"text,omitempty"`
+		ID               string            `json:"id,omitempty"`
+		Text             string            `json:"text,omitempty"`
+		FinishReason     string            `json:"finish_reason,omitempty"`
+		TokenLikelihoods []TokenLikelihood `json:"token_likelihoods,omitempty"`
 	} `json:"generations,omitempty"`
+	Meta struct {
+		BilledUnits struct {
+			InputTokens  int `json:"input_tokens,omitempty"`
+			OutputTokens int `json:"output_tokens,omitempty"`
+		} `json:"billed_units,omitempty"`
+	} `json:"meta,omitempty"`
 }
 
-func (c *Client) CreateGeneration(ctx context.Context, r *GenerationRequest) (*Generation, error) {
-	if c.baseURL == "" {
-		c.baseURL = "https://api.cohere.ai"
-	}
+// streamedGenerationPayload is a line of a streamed generation. The last line
+// has IsFinished set and holds the whole response.
+type streamedGenerationPayload struct {
+	Index        int                      `json:"index,omitempty"`
+	Text         string                   `json:"text,omitempty"`
+	IsFinished   bool                     `json:"is_finished"`
+	FinishReason string                   `json:"finish_reason,omitempty"`
+	Response     *generateResponsePayload `json:"response,omitempty"`
+}
 
+func (c *Client) CreateGeneration(ctx context.Context, r *GenerationRequest) (*Generation, error) {
 	payload := generateRequestPayload{
-		Prompt: r.Prompt,
-		Model:  c.model,
+		Prompt:         r.Prompt,
+		Model:          c.model,
+		Stream:         r.StreamingFunc != nil,
+		NumGenerations: r.NumGenerations,
+	}
+	if r.ReturnLikelihoods {
+		payload.ReturnLikelihoods = "GENERATION"
 	}
 
 	payloadBytes, err := json.Marshal(&payload)
@@ -121,6 +173,22 @@ func (c *Client) CreateGeneration(ctx context.Context, r *GenerationRequest) (*G
 	defer res.Body.Close()
 
 	var response generateResponsePayload
+	if res.StatusCode != http.StatusOK {
+		// No need to check the error here: if it fails, we'll just return the
+		// status code.
+		_ = json.NewDecoder(res.Body).Decode(&response)
+		if strings.HasPrefix(response.Message, "model not found") {
+			return nil, ErrModelNotFound
+		}
+		return nil, &llms.StatusError{
+			StatusCode: res.StatusCode,
+			Message:    response.Message,
+			Header:     res.Header,
+		}
+	}
+	if r.StreamingFunc != nil {
+		return parseStreamingGenerationResponse(ctx, res, r)
+	}
 	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
 		return nil, fmt.Errorf("parse response: %w", err)
 	}
@@ -134,11 +202,67 @@ func (c *Client) CreateGeneration(ctx context.Context, r *GenerationRequest) (*G
 
 	var generation Generation
 	generation.Text = response.Generations[0].Text
+	generation.FinishReason = response.Generations[0].FinishReason
+	generation.InputTokens = response.Meta.BilledUnits.InputTokens
+	generation.OutputTokens = response.Meta.BilledUnits.OutputTokens
+	generation.TokenLikelihoods = response.Generations[0].TokenLikelihoods
+	for _, g := range response.Generations[1:] {
+		generation.Candidates = append(generation.Candidates, &Generation{
+			Text:             g.Text,
+			FinishReason:     g.FinishReason,
+			TokenLikelihoods: g.TokenLikelihoods,
+		})
+	}
 
 	return &generation, nil
 }
 
+func parseStreamingGenerationResponse(ctx context.Context, res *http.Response, r *GenerationRequest) (*Generation, error) { //nolint:lll
+	var generation Generation
+
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var payload streamedGenerationPayload
+		if err := json.Unmarshal(line, &payload); err != nil {
+			return nil, fmt.Errorf("parse stream payload: %w", err)
+		}
+		if payload.IsFinished {
+			generation.FinishReason = payload.FinishReason
+			if payload.Response != nil {
+				generation.InputTokens = payload.Response.Meta.BilledUnits.InputTokens
+				generation.OutputTokens = payload.Response.Meta.BilledUnits.OutputTokens
+			}
+			return &generation, nil
+		}
+		// Only the first generation is streamed.
+		if payload.Index != 0 {
+			continue
+		}
+
+		generation.Text += payload.Text
+		if err := r.StreamingFunc(ctx, []byte(payload.Text)); err != nil {
+			return nil, fmt.Errorf("streaming func returned an error: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read stream: %w", err)
+	}
+	if generation.Text == "" {
+		return nil, ErrEmptyResponse
+	}
+	return &generation, nil
+}
+
+// GetNumTokens returns the number of tokens of the text, using the tokenizer
+// registered for the model with llms.RegisterTokenizer if any.
 func (c *Client) GetNumTokens(text string) int {
+	if t, ok := llms.LookupTokenizer(c.model); ok {
+		return t.Count(text)
+	}
 	encoded, _ := c.encoder.Encode(text)
 	return len(encoded)
 }
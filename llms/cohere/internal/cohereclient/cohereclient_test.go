@@ -0,0 +1,101 @@
+package cohereclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mockServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload generateRequestPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		if payload.NumGenerations > 1 {
+			assert.Equal(t, "GENERATION", payload.ReturnLikelihoods)
+			_, _ = w.Write([]byte(`{"generations":[{"text":"Hi","finish_reason":"COMPLETE","token_likelihoods":[{"token":"Hi","likelihood":-0.5}]},{"text":"Hey","finish_reason":"COMPLETE","token_likelihoods":[{"token":"Hey","likelihood":-1.5}]}],"meta":{"billed_units":{"input_tokens":3,"output_tokens":2}}}`)) // nolint:lll
+			return
+		}
+		if !payload.Stream {
+			_, _ = w.Write([]byte(`{"generations":[{"text":"Hello world","finish_reason":"COMPLETE"}],"meta":{"billed_units":{"input_tokens":3,"output_tokens":2}}}`)) // nolint:lll
+			return
+		}
+		_, _ = w.Write([]byte(`{"text":"Hello","is_finished":false}` + "\n"))
+		_, _ = w.Write([]byte(`{"text":" world","is_finished":false}` + "\n"))
+		_, _ = w.Write([]byte(`{"is_finished":true,"finish_reason":"COMPLETE","response":{"generations":[{"text":"Hello world"}],"meta":{"billed_units":{"input_tokens":3,"output_tokens":2}}}}` + "\n")) // nolint:lll
+	}))
+}
+
+func TestCreateGeneration(t *testing.T) {
+	t.Parallel()
+
+	server := mockServer(t)
+	t.Cleanup(server.Close)
+
+	client, err := New("token", server.URL, "command")
+	require.NoError(t, err)
+
+	want := &Generation{Text: "Hello world", FinishReason: "COMPLETE", InputTokens: 3, OutputTokens: 2}
+
+	generation, err := client.CreateGeneration(context.Background(), &GenerationRequest{Prompt: "Hi"})
+	require.NoError(t, err)
+	assert.Equal(t, want, generation)
+
+	var chunks []string
+	generation, err = client.CreateGeneration(context.Background(), &GenerationRequest{
+		Prompt: "Hi",
+		StreamingFunc: func(_ context.Context, chunk []byte) error {
+			chunks = append(chunks, string(chunk))
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, want, generation)
+	assert.Equal(t, []string{"Hello", " world"}, chunks)
+
+	errStop := errors.New("stop")
+	_, err = client.CreateGeneration(context.Background(), &GenerationRequest{
+		Prompt: "Hi",
+		StreamingFunc: func(context.Context, []byte) error {
+			return errStop
+		},
+	})
+	require.ErrorIs(t, err, errStop)
+}
+
+func TestCreateGenerationCandidates(t *testing.T) {
+	t.Parallel()
+
+	server := mockServer(t)
+	t.Cleanup(server.Close)
+
+	client, err := New("token", server.URL, "command")
+	require.NoError(t, err)
+
+	generation, err := client.CreateGeneration(context.Background(), &GenerationRequest{
+		Prompt:            "Hi",
+		NumGenerations:    2,
+		ReturnLikelihoods: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Generation{
+		Text:             "Hi",
+		FinishReason:     "COMPLETE",
+		InputTokens:      3,
+		OutputTokens:     2,
+		TokenLikelihoods: []TokenLikelihood{{Token: "Hi", Likelihood: -0.5}},
+		Candidates: []*Generation{{
+			Text:             "Hey",
+			FinishReason:     "COMPLETE",
+			TokenLikelihoods: []TokenLikelihood{{Token: "Hey", Likelihood: -1.5}},
+		}},
+	}, generation)
+}
@@ -0,0 +1,41 @@
+package llms
+
+import "sync/atomic"
+
+// defaultMaxConcurrency is the default maximum concurrency, or 0 for 1.
+//
+// nolint:gochecknoglobals
+var defaultMaxConcurrency atomic.Int32
+
+// SetDefaultMaxConcurrency sets the maximum number of prompts generated
+// concurrently by the calls that do not set it with WithMaxConcurrency. It
+// defaults to 1, generating the prompts one after the other.
+func SetDefaultMaxConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	defaultMaxConcurrency.Store(int32(n))
+}
+
+// DefaultMaxConcurrency returns the maximum number of prompts generated
+// concurrently by the calls that do not set it.
+func DefaultMaxConcurrency() int {
+	if n := int(defaultMaxConcurrency.Load()); n > 0 {
+		return n
+	}
+	return 1
+}
+
+// Concurrency returns the maximum number of prompts to generate concurrently
+// with the options. Streaming calls generate the prompts one after the other,
+// so that the chunks of the prompts are not interleaved.
+func (o CallOptions) Concurrency() int {
+	switch {
+	case o.StreamingFunc != nil:
+		return 1
+	case o.MaxConcurrency > 0:
+		return o.MaxConcurrency
+	default:
+		return DefaultMaxConcurrency()
+	}
+}
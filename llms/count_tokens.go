@@ -10,46 +10,24 @@ const (
 	_tokenApproximation = 4
 )
 
-const (
-	_gpt35TurboContextSize   = 4096
-	_gpt432KContextSize      = 32768
-	_gpt4ContextSize         = 8192
-	_textDavinci3ContextSize = 4097
-	_textBabbage1ContextSize = 2048
-	_textAda1ContextSize     = 2048
-	_textCurie1ContextSize   = 2048
-	_codeDavinci2ContextSize = 8000
-	_codeCushman1ContextSize = 2048
-	_textBisonContextSize    = 2048
-	_chatBisonContextSize    = 2048
-	_defaultContextSize      = 2048
-)
-
-// nolint:gochecknoglobals
-var modelToContextSize = map[string]int{
-	"gpt-3.5-turbo":    _gpt35TurboContextSize,
-	"gpt-4-32k":        _gpt432KContextSize,
-	"gpt-4":            _gpt4ContextSize,
-	"text-davinci-003": _textDavinci3ContextSize,
-	"text-curie-001":   _textCurie1ContextSize,
-	"text-babbage-001": _textBabbage1ContextSize,
-	"text-ada-001":     _textBabbage1ContextSize,
-	"code-davinci-002": _codeDavinci2ContextSize,
-	"code-cushman-001": _codeCushman1ContextSize,
-}
-
-// ModelContextSize gets the max number of tokens for a language model. If the model
-// name isn't recognized the default value 2048 is returned.
+// GetModelContextSize gets the max number of tokens for a language model from
+// the model registry. If the model isn't registered the default value 2048 is
+// returned.
 func GetModelContextSize(model string) int {
-	contextSize, ok := modelToContextSize[model]
-	if !ok {
+	info, ok := LookupModel(model)
+	if !ok || info.ContextSize == 0 {
 		return _defaultContextSize
 	}
-	return contextSize
+	return info.ContextSize
 }
 
-// CountTokens gets the number of tokens the text contains.
+// CountTokens gets the number of tokens the text contains. It uses the
+// tokenizer registered for the model with RegisterTokenizer if any, and
+// tiktoken otherwise.
 func CountTokens(model, text string) int {
+	if t, ok := LookupTokenizer(model); ok {
+		return t.Count(text)
+	}
 	e, err := tiktoken.EncodingForModel(model)
 	if err != nil {
 		e, err = tiktoken.GetEncoding("gpt2")
@@ -62,6 +40,11 @@ func CountTokens(model, text string) int {
 }
 
 // CalculateMaxTokens calculates the max number of tokens that could be added to a text.
+// It is at most the max number of output tokens of the model, if registered.
 func CalculateMaxTokens(model, text string) int {
-	return GetModelContextSize(model) - CountTokens(model, text)
+	maxTokens := GetModelContextSize(model) - CountTokens(model, text)
+	if info, ok := LookupModel(model); ok && info.MaxOutputTokens > 0 && info.MaxOutputTokens < maxTokens {
+		return info.MaxOutputTokens
+	}
+	return maxTokens
 }
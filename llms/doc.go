@@ -8,6 +8,7 @@
 // 3. OpenAI:            llms/openai/
 // 4. Vertex AI:         llms/vertexai/
 // 5. Cohere:            llms/cohere/
+// 6. Ollama:            llms/ollama/
 //
 // Each subpackage includes provider-specific LLM implementations and helper files for communication
 // with supported LLM providers. The internal directories within these subpackages contain provider-specific
@@ -97,6 +97,7 @@ func (l *LLM) Generate(ctx context.Context, prompts []string, options ...llms.Ca
 
 	generations := make([]*llms.Generation, 0, len(prompts))
 	for _, prompt := range prompts {
+		prompt = llms.PromptWithResponseFormat(prompt, opts.ResponseFormat)
 		result, err := l.client.CreateCompletion(ctx, l.getModelPath(opts), &ernieclient.CompletionRequest{
 			Messages:      []ernieclient.Message{{Role: "user", Content: prompt}},
 			Temperature:   opts.Temperature,
@@ -112,9 +113,22 @@ func (l *LLM) Generate(ctx context.Context, prompts []string, options ...llms.Ca
 			return nil, fmt.Errorf("%w, error_code:%v, erro_msg:%v, id:%v",
 				ErrCodeResponse, result.ErrorCode, result.ErrorMsg, result.ID)
 		}
+		result.Result, err = llms.ParseResponse(opts.ResponseFormat, result.Result)
+		if err != nil {
+			return nil, err
+		}
 
+		usage := llms.Usage{
+			PromptTokens:     result.Usage.PromptTokens,
+			CompletionTokens: result.Usage.CompletionTokens,
+			TotalTokens:      result.Usage.TotalTokens,
+			FinishReason:     finishReason(result),
+		}
 		generations = append(generations, &llms.Generation{
 			Text: result.Result,
+			GenerationInfo: map[string]any{
+				llms.UsageKey: usage.OrEstimate(string(l.model), prompt, result.Result),
+			},
 		})
 	}
 
@@ -169,3 +183,10 @@ func (l *LLM) getModelPath(opts llms.CallOptions) ernieclient.ModelPath {
 		return ernieclient.DefaultCompletionModelPath
 	}
 }
+
+func finishReason(result *ernieclient.Completion) string {
+	if result.IsTruncated {
+		return "length"
+	}
+	return "stop"
+}
@@ -7,10 +7,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/tmc/langchaingo/llms"
 )
 
 var (
@@ -202,7 +205,15 @@ func (c *Client) CreateCompletion(ctx context.Context, modelPath ModelPath, r *C
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%w: %d", ErrCompletionCode, resp.StatusCode)
+		// No need to check the error here: if it fails, we'll just return the
+		// status code.
+		b, _ := io.ReadAll(resp.Body)
+		return nil, &llms.StatusError{
+			StatusCode: resp.StatusCode,
+			Message:    string(b),
+			Header:     resp.Header,
+			Err:        ErrCompletionCode,
+		}
 	}
 
 	if r.Stream {
@@ -238,7 +249,15 @@ func (c *Client) CreateEmbedding(ctx context.Context, texts []string) (*Embeddin
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%w: %d", ErrEmbeddingCode, resp.StatusCode)
+		// No need to check the error here: if it fails, we'll just return the
+		// status code.
+		b, _ := io.ReadAll(resp.Body)
+		return nil, &llms.StatusError{
+			StatusCode: resp.StatusCode,
+			Message:    string(b),
+			Header:     resp.Header,
+			Err:        ErrEmbeddingCode,
+		}
 	}
 
 	var response EmbeddingResponse
@@ -263,7 +282,15 @@ func (c *Client) getAccessToken(ctx context.Context) (*authResponse, error) {
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%w: %d", ErrAccessTokenCode, resp.StatusCode)
+		// No need to check the error here: if it fails, we'll just return the
+		// status code.
+		b, _ := io.ReadAll(resp.Body)
+		return nil, &llms.StatusError{
+			StatusCode: resp.StatusCode,
+			Message:    string(b),
+			Header:     resp.Header,
+			Err:        ErrAccessTokenCode,
+		}
 	}
 
 	var response authResponse
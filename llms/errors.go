@@ -0,0 +1,109 @@
+package llms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+// StatusError is returned by provider clients when the API responds with an
+// unexpected HTTP status code.
+type StatusError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Message is the error message reported by the API, if any.
+	Message string
+	// Header holds the response headers, e.g. Retry-After.
+	Header http.Header
+	// Err is the error of the provider client the status is reported as, if
+	// any, so that errors.Is matches it.
+	Err error
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("API returned unexpected status code: %d", e.StatusCode)
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %d", e.Err, e.StatusCode)
+	}
+	if e.Message == "" {
+		return msg
+	}
+	return fmt.Sprintf("%s: %s", msg, e.Message)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorClass is a coarse classification of an error returned by a provider.
+type ErrorClass string
+
+const (
+	// ErrorClassCanceled is an error caused by a canceled or expired context.
+	ErrorClassCanceled ErrorClass = "canceled"
+	// ErrorClassRateLimited is an error caused by the provider rate limiting requests.
+	ErrorClassRateLimited ErrorClass = "rate_limited"
+	// ErrorClassServer is an error caused by the provider failing to serve the request.
+	ErrorClassServer ErrorClass = "server"
+	// ErrorClassTimeout is an error caused by a request timing out.
+	ErrorClassTimeout ErrorClass = "timeout"
+	// ErrorClassNetwork is an error caused by the connection to the provider.
+	ErrorClassNetwork ErrorClass = "network"
+	// ErrorClassClient is an error caused by an invalid request, e.g. a bad
+	// parameter or a missing permission.
+	ErrorClassClient ErrorClass = "client"
+	// ErrorClassUnknown is any other error.
+	ErrorClassUnknown ErrorClass = "unknown"
+)
+
+// Retryable reports whether errors of the class are transient, so that the same
+// request may succeed if it is sent again.
+func (c ErrorClass) Retryable() bool {
+	switch c {
+	case ErrorClassRateLimited, ErrorClassServer, ErrorClassTimeout, ErrorClassNetwork:
+		return true
+	case ErrorClassCanceled, ErrorClassClient, ErrorClassUnknown:
+		return false
+	}
+	return false
+}
+
+// ClassifyError returns the class of an error returned by a provider.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassUnknown
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassCanceled
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.StatusCode == http.StatusTooManyRequests:
+			return ErrorClassRateLimited
+		case statusErr.StatusCode == http.StatusRequestTimeout:
+			return ErrorClassTimeout
+		case statusErr.StatusCode >= http.StatusInternalServerError:
+			return ErrorClassServer
+		case statusErr.StatusCode >= http.StatusBadRequest:
+			return ErrorClassClient
+		}
+		return ErrorClassUnknown
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassNetwork
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) {
+		return ErrorClassNetwork
+	}
+	return ErrorClassUnknown
+}
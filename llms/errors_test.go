@@ -0,0 +1,46 @@
+package llms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errProvider = errors.New("provider API returned unexpected status code")
+
+func TestClassifyError(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		err       error
+		expected  ErrorClass
+		retryable bool
+	}{
+		{&StatusError{StatusCode: http.StatusTooManyRequests}, ErrorClassRateLimited, true},
+		{fmt.Errorf("wrapped: %w", &StatusError{StatusCode: http.StatusBadGateway}), ErrorClassServer, true},
+		{&StatusError{StatusCode: http.StatusRequestTimeout}, ErrorClassTimeout, true},
+		{&StatusError{StatusCode: http.StatusUnauthorized}, ErrorClassClient, false},
+		{fmt.Errorf("send request: %w", context.Canceled), ErrorClassCanceled, false},
+		{errors.New("boom"), ErrorClassUnknown, false},
+		{&StatusError{StatusCode: http.StatusServiceUnavailable, Err: errProvider}, ErrorClassServer, true},
+	}
+
+	for _, tc := range cases {
+		class := ClassifyError(tc.err)
+		assert.Equal(t, tc.expected, class, tc.err.Error())
+		assert.Equal(t, tc.retryable, class.Retryable(), tc.err.Error())
+	}
+}
+
+func TestStatusErrorUnwrap(t *testing.T) {
+	t.Parallel()
+
+	err := fmt.Errorf("wrapped: %w", &StatusError{StatusCode: http.StatusTooManyRequests, Message: "slow down", Err: errProvider})
+	assert.ErrorIs(t, err, errProvider)
+	assert.Equal(t, "wrapped: provider API returned unexpected status code: 429: slow down", err.Error())
+	assert.Equal(t, "API returned unexpected status code: 500", (&StatusError{StatusCode: 500}).Error())
+}
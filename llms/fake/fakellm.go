@@ -0,0 +1,228 @@
+// Package fake provides deterministic, scripted implementations of llms.LLM and
+// llms.ChatLLM that can be used to test chains and agents without calling a
+// real provider.
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+var (
+	// ErrEmptyResponse is returned when the fake generated no response.
+	ErrEmptyResponse = errors.New("no response")
+	// ErrNoResponse is returned when no scripted response is left for a prompt.
+	ErrNoResponse = errors.New("no scripted response for prompt")
+)
+
+// LLM is a fake LLM that returns scripted responses.
+type LLM struct {
+	CallbacksHandler callbacks.Handler
+	script           *script
+}
+
+var (
+	_ llms.LLM           = (*LLM)(nil)
+	_ llms.LanguageModel = (*LLM)(nil)
+)
+
+// New returns a new fake LLM.
+func New(opts ...Option) *LLM {
+	return &LLM{script: newScript(opts...)}
+}
+
+// Call returns the scripted response for the given prompt.
+func (f *LLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	r, err := f.Generate(ctx, []string{prompt}, options...)
+	if err != nil {
+		return "", err
+	}
+	if len(r) == 0 {
+		return "", ErrEmptyResponse
+	}
+	return r[0].Text, nil
+}
+
+// Generate returns a scripted response for each of the prompts.
+func (f *LLM) Generate(ctx context.Context, prompts []string, options ...llms.CallOption) ([]*llms.Generation, error) {
+	if f.CallbacksHandler != nil {
+		f.CallbacksHandler.HandleLLMStart(ctx, prompts)
+	}
+
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	generations := make([]*llms.Generation, 0, len(prompts))
+	for _, prompt := range prompts {
+		text, err := f.script.respond(ctx, prompt, opts)
+		if err != nil {
+			return nil, err
+		}
+		generations = append(generations, &llms.Generation{
+			Text:           text,
+			GenerationInfo: map[string]any{llms.UsageKey: usage(prompt, text)},
+		})
+	}
+
+	if f.CallbacksHandler != nil {
+		f.CallbacksHandler.HandleLLMEnd(ctx, llms.NewLLMResult(generations))
+	}
+	return generations, nil
+}
+
+func (f *LLM) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
+	return llms.GeneratePrompt(ctx, f, promptValues, options...)
+}
+
+// GetNumTokens returns the number of whitespace separated words in the text.
+func (f *LLM) GetNumTokens(text string) int {
+	return countTokens(text)
+}
+
+// Prompts returns every prompt the fake received, in order.
+func (f *LLM) Prompts() []string {
+	return f.script.recordedPrompts()
+}
+
+// script holds the scripted responses and the prompts received so far. It is
+// shared by LLM and Chat and is safe for concurrent use.
+type script struct {
+	mu      sync.Mutex
+	opts    options
+	next    int
+	prompts []string
+}
+
+func newScript(opts ...Option) *script {
+	s := &script{}
+	for _, opt := range opts {
+		opt(&s.opts)
+	}
+	return s
+}
+
+func (s *script) respond(ctx context.Context, prompt string, opts llms.CallOptions) (string, error) {
+	text, err := s.lookup(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	text = truncateAtStopWords(text, opts.StopWords)
+
+	if opts.StreamingFunc != nil {
+		for _, chunk := range s.chunks(text) {
+			if err := opts.StreamingFunc(ctx, []byte(chunk)); err != nil {
+				return "", fmt.Errorf("streaming func returned an error: %w", err)
+			}
+		}
+	}
+	// The scripted responses are checked against the requested format like
+	// the responses of a model.
+	return llms.ParseResponse(opts.ResponseFormat, text)
+}
+
+func (s *script) lookup(ctx context.Context, prompt string) (string, error) {
+	s.mu.Lock()
+	s.prompts = append(s.prompts, prompt)
+	for _, m := range s.opts.matches {
+		if m.pattern.MatchString(prompt) {
+			s.mu.Unlock()
+			return m.reply, nil
+		}
+	}
+	responseFunc := s.opts.responseFunc
+	if responseFunc == nil && s.next < len(s.opts.responses) {
+		text := s.opts.responses[s.next]
+		s.next++
+		s.mu.Unlock()
+		return text, nil
+	}
+	s.mu.Unlock()
+
+	if responseFunc == nil {
+		return "", fmt.Errorf("%w: %q", ErrNoResponse, prompt)
+	}
+	return responseFunc(ctx, prompt)
+}
+
+func (s *script) recordedPrompts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prompts := make([]string, len(s.prompts))
+	copy(prompts, s.prompts)
+	return prompts
+}
+
+// chunks splits the text into the pieces handed to the streaming function.
+func (s *script) chunks(text string) []string {
+	if s.opts.chunkSize > 0 {
+		runes := []rune(text)
+		chunks := make([]string, 0, len(runes)/s.opts.chunkSize+1)
+		for i := 0; i < len(runes); i += s.opts.chunkSize {
+			end := i + s.opts.chunkSize
+			if end > len(runes) {
+				end = len(runes)
+			}
+			chunks = append(chunks, string(runes[i:end]))
+		}
+		return chunks
+	}
+
+	// Split into words, keeping the whitespace in front of each word.
+	var chunks []string
+	start := 0
+	inWord := false
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			if inWord {
+				chunks = append(chunks, text[start:i])
+				start = i
+				inWord = false
+			}
+			continue
+		}
+		inWord = true
+	}
+	if start < len(text) {
+		chunks = append(chunks, text[start:])
+	}
+	return chunks
+}
+
+func truncateAtStopWords(text string, stopWords []string) string {
+	end := len(text)
+	for _, stopWord := range stopWords {
+		if stopWord == "" {
+			continue
+		}
+		if i := strings.Index(text, stopWord); i >= 0 && i < end {
+			end = i
+		}
+	}
+	return text[:end]
+}
+
+func countTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+// usage returns the usage of a response, counting words as tokens.
+func usage(prompt, text string) llms.Usage {
+	promptTokens, completionTokens := countTokens(prompt), countTokens(text)
+	return llms.Usage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+		FinishReason:     "stop",
+	}
+}
@@ -0,0 +1,113 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+const (
+	humanPrefix = "Human"
+	aiPrefix    = "AI"
+)
+
+// Chat is a fake chat LLM that returns scripted responses. The prompt used to
+// select a response is the buffer string of the messages, as returned by
+// schema.GetBufferString with the "Human" and "AI" prefixes.
+type Chat struct {
+	CallbacksHandler callbacks.Handler
+	script           *script
+	messages         [][]schema.ChatMessage
+}
+
+var (
+	_ llms.ChatLLM       = (*Chat)(nil)
+	_ llms.LanguageModel = (*Chat)(nil)
+)
+
+// NewChat returns a new fake chat LLM.
+func NewChat(opts ...Option) *Chat {
+	return &Chat{script: newScript(opts...)}
+}
+
+// Call returns the scripted response for the given messages.
+func (f *Chat) Call(ctx context.Context, messages []schema.ChatMessage, options ...llms.CallOption) (*schema.AIChatMessage, error) { // nolint: lll
+	r, err := f.Generate(ctx, [][]schema.ChatMessage{messages}, options...)
+	if err != nil {
+		return nil, err
+	}
+	if len(r) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	return r[0].Message, nil
+}
+
+// Generate returns a scripted response for each of the sets of messages.
+func (f *Chat) Generate(ctx context.Context, messageSets [][]schema.ChatMessage, options ...llms.CallOption) ([]*llms.Generation, error) { // nolint:lll
+	prompts := make([]string, 0, len(messageSets))
+	for _, messages := range messageSets {
+		prompt, err := schema.GetBufferString(messages, humanPrefix, aiPrefix)
+		if err != nil {
+			return nil, err
+		}
+		prompts = append(prompts, prompt)
+	}
+
+	if f.CallbacksHandler != nil {
+		f.CallbacksHandler.HandleLLMStart(ctx, prompts)
+	}
+
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	generations := make([]*llms.Generation, 0, len(messageSets))
+	for i, prompt := range prompts {
+		f.script.mu.Lock()
+		f.messages = append(f.messages, messageSets[i])
+		f.script.mu.Unlock()
+
+		text, err := f.script.respond(ctx, prompt, opts)
+		if err != nil {
+			return nil, err
+		}
+		generations = append(generations, &llms.Generation{
+			Message:        &schema.AIChatMessage{Content: text},
+			Text:           text,
+			GenerationInfo: map[string]any{llms.UsageKey: usage(prompt, text)},
+		})
+	}
+
+	if f.CallbacksHandler != nil {
+		f.CallbacksHandler.HandleLLMEnd(ctx, llms.NewLLMResult(generations))
+	}
+	return generations, nil
+}
+
+func (f *Chat) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
+	return llms.GenerateChatPrompt(ctx, f, promptValues, options...)
+}
+
+// GetNumTokens returns the number of whitespace separated words in the text.
+func (f *Chat) GetNumTokens(text string) int {
+	return countTokens(text)
+}
+
+// Prompts returns the buffer string of every set of messages the fake
+// received, in order.
+func (f *Chat) Prompts() []string {
+	return f.script.recordedPrompts()
+}
+
+// Messages returns every set of messages the fake received, in order.
+func (f *Chat) Messages() [][]schema.ChatMessage {
+	f.script.mu.Lock()
+	defer f.script.mu.Unlock()
+
+	messages := make([][]schema.ChatMessage, len(f.messages))
+	copy(messages, f.messages)
+	return messages
+}
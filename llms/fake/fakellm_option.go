@@ -0,0 +1,77 @@
+package fake
+
+import (
+	"context"
+	"regexp"
+	"sort"
+)
+
+type match struct {
+	pattern *regexp.Regexp
+	reply   string
+}
+
+type options struct {
+	responses    []string
+	matches      []match
+	responseFunc func(ctx context.Context, prompt string) (string, error)
+	chunkSize    int
+}
+
+type Option func(*options)
+
+// WithResponses sets the sequence of responses returned by the fake. Each
+// call consumes the next response. Once the sequence is exhausted the fake
+// returns ErrNoResponse.
+func WithResponses(responses ...string) Option {
+	return func(opts *options) {
+		opts.responses = append(opts.responses, responses...)
+	}
+}
+
+// WithMatch adds a reply that is returned whenever the prompt matches the given
+// regular expression. Matches are evaluated in the order they were added and
+// take precedence over WithResponseFunc and WithResponses. It panics if the
+// pattern is not a valid regular expression.
+func WithMatch(pattern, reply string) Option {
+	re := regexp.MustCompile(pattern)
+	return func(opts *options) {
+		opts.matches = append(opts.matches, match{pattern: re, reply: reply})
+	}
+}
+
+// WithRegexResponses adds a reply for every regular expression in the map. The
+// patterns are evaluated in lexical order to keep the fake deterministic.
+func WithRegexResponses(responses map[string]string) Option {
+	patterns := make([]string, 0, len(responses))
+	for pattern := range responses {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	matchOpts := make([]Option, 0, len(patterns))
+	for _, pattern := range patterns {
+		matchOpts = append(matchOpts, WithMatch(pattern, responses[pattern]))
+	}
+	return func(opts *options) {
+		for _, opt := range matchOpts {
+			opt(opts)
+		}
+	}
+}
+
+// WithResponseFunc sets a function that computes the response for a prompt. It
+// is consulted when no pattern added with WithMatch matches the prompt.
+func WithResponseFunc(fn func(ctx context.Context, prompt string) (string, error)) Option {
+	return func(opts *options) {
+		opts.responseFunc = fn
+	}
+}
+
+// WithChunkSize sets the number of runes passed to the streaming function per
+// chunk. If not set, the response is streamed word by word.
+func WithChunkSize(chunkSize int) Option {
+	return func(opts *options) {
+		opts.chunkSize = chunkSize
+	}
+}
@@ -0,0 +1,103 @@
+package fake
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/chains"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/prompts"
+	"github.com/tmc/langchaingo/schema"
+)
+
+func TestLLMResponses(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	llm := New(
+		WithMatch(`(?i)weather`, "It is sunny."),
+		WithResponses("first", "second"),
+	)
+
+	out, err := llm.Call(ctx, "What is the weather like?")
+	require.NoError(t, err)
+	assert.Equal(t, "It is sunny.", out)
+
+	out, err = llm.Call(ctx, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "first", out)
+
+	out, err = llm.Call(ctx, "hello again")
+	require.NoError(t, err)
+	assert.Equal(t, "second", out)
+
+	_, err = llm.Call(ctx, "one too many")
+	assert.ErrorIs(t, err, ErrNoResponse)
+
+	assert.Equal(t, []string{"What is the weather like?", "hello", "hello again", "one too many"}, llm.Prompts())
+}
+
+func TestLLMResponseFunc(t *testing.T) {
+	t.Parallel()
+
+	llm := New(WithResponseFunc(func(_ context.Context, prompt string) (string, error) {
+		return strings.ToUpper(prompt), nil
+	}))
+	out, err := llm.Call(context.Background(), "shout")
+	require.NoError(t, err)
+	assert.Equal(t, "SHOUT", out)
+}
+
+func TestLLMStreamingAndStopWords(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	var chunks []string
+	streamingFunc := func(_ context.Context, chunk []byte) error {
+		chunks = append(chunks, string(chunk))
+		return nil
+	}
+
+	llm := New(WithResponses("Hello brave new world\nObservation: ignored"))
+	out, err := llm.Call(ctx, "hi", llms.WithStopWords([]string{"\nObservation:"}), llms.WithStreamingFunc(streamingFunc))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello brave new world", out)
+	assert.Equal(t, []string{"Hello", " brave", " new", " world"}, chunks)
+
+	chunks = nil
+	llm = New(WithResponses("abcdefg"), WithChunkSize(3))
+	_, err = llm.Call(ctx, "hi", llms.WithStreamingFunc(streamingFunc))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"abc", "def", "g"}, chunks)
+}
+
+func TestChat(t *testing.T) {
+	t.Parallel()
+
+	chat := NewChat(WithRegexResponses(map[string]string{
+		`Human: hello$`: "Hi! How can I help?",
+	}))
+	messages := []schema.ChatMessage{
+		schema.SystemChatMessage{Content: "be nice"},
+		schema.HumanChatMessage{Content: "hello"},
+	}
+	msg, err := chat.Call(context.Background(), messages)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi! How can I help?", msg.Content)
+	assert.Equal(t, [][]schema.ChatMessage{messages}, chat.Messages())
+	assert.Equal(t, []string{"System: be nice\nHuman: hello"}, chat.Prompts())
+}
+
+func TestLLMChain(t *testing.T) {
+	t.Parallel()
+
+	llm := New(WithResponses("Paris"))
+	chain := chains.NewLLMChain(llm, prompts.NewPromptTemplate("What is the capital of {{.country}}?", []string{"country"}))
+	out, err := chains.Run(context.Background(), chain, "France")
+	require.NoError(t, err)
+	assert.Equal(t, "Paris", out)
+	assert.Equal(t, []string{"What is the capital of France?"}, llm.Prompts())
+}
@@ -0,0 +1,83 @@
+package fallback
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// Chat is an llms.ChatLLM that tries a list of chat LLMs in order.
+type Chat struct {
+	CallbacksHandler callbacks.Handler
+	models           []Model[llms.ChatLLM]
+	opts             options
+}
+
+var (
+	_ llms.ChatLLM       = (*Chat)(nil)
+	_ llms.LanguageModel = (*Chat)(nil)
+)
+
+// NewChat returns a new Chat that tries the given models in order.
+func NewChat(models []Model[llms.ChatLLM], opts ...Option) *Chat {
+	return &Chat{
+		models: models,
+		opts:   applyOptions(opts...),
+	}
+}
+
+// Call requests a chat response for the given messages.
+func (c *Chat) Call(ctx context.Context, messages []schema.ChatMessage, options ...llms.CallOption) (*schema.AIChatMessage, error) { // nolint: lll
+	r, err := c.Generate(ctx, [][]schema.ChatMessage{messages}, options...)
+	if err != nil {
+		return nil, err
+	}
+	if len(r) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	return r[0].Message, nil
+}
+
+// Generate requests chat responses from the first model that serves the request.
+func (c *Chat) Generate(ctx context.Context, messageSets [][]schema.ChatMessage, options ...llms.CallOption) ([]*llms.Generation, error) { // nolint:lll
+	prompts := make([]string, 0, len(messageSets))
+	for _, messages := range messageSets {
+		prompt, err := schema.GetBufferString(messages, "Human", "AI")
+		if err != nil {
+			return nil, err
+		}
+		prompts = append(prompts, prompt)
+	}
+	if c.CallbacksHandler != nil {
+		c.CallbacksHandler.HandleLLMStart(ctx, prompts)
+	}
+
+	var generations []*llms.Generation
+	name, err := try(ctx, c.CallbacksHandler, c.opts, c.models, longest(prompts), callOptions(options).MaxTokens,
+		func(ctx context.Context, model llms.ChatLLM, streamed *atomic.Bool) error {
+			var err error
+			generations, err = model.Generate(ctx, messageSets, withTrackStreaming(options, streamed)...)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	annotate(ctx, c.CallbacksHandler, name, generations)
+	return generations, nil
+}
+
+func (c *Chat) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
+	return llms.GenerateChatPrompt(ctx, c, promptValues, options...)
+}
+
+// GetNumTokens returns the number of tokens of the text for the first model.
+func (c *Chat) GetNumTokens(text string) int {
+	if len(c.models) == 0 {
+		return llms.CountTokens("", text)
+	}
+	return c.models[0].countTokens(text)
+}
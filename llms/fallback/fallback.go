@@ -0,0 +1,214 @@
+// Package fallback provides composite LLMs, chat LLMs and language models that
+// try a list of models in order, falling back to the next model when one
+// fails with a transient error such as a rate limit or an outage.
+//
+// Optionally, requests are routed by the number of tokens in the prompt, so
+// that models whose context window is too small for a prompt are skipped.
+// The name of the model that served a request is reported in the
+// GenerationInfo of every generation under ModelKey and to the callbacks
+// handler.
+package fallback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// ModelKey is the key of the GenerationInfo entry and of the LLMOutput entry
+// holding the name of the model that served the request.
+const ModelKey = "Model"
+
+var (
+	// ErrEmptyResponse is returned when the serving model returns no generations.
+	ErrEmptyResponse = errors.New("no response")
+	// ErrNoModels is returned when no model was configured.
+	ErrNoModels = errors.New("no models configured")
+	// ErrAllModelsFailed is returned, wrapping the errors of every model that
+	// was tried, when no model served the request.
+	ErrAllModelsFailed = errors.New("all models failed")
+	// ErrPromptTooLong is returned when routing by tokens and the prompt does
+	// not fit the context window of any model.
+	ErrPromptTooLong = errors.New("prompt does not fit the context window of any model")
+)
+
+// Model is a model the requests may be sent to.
+type Model[T any] struct {
+	// Name is the name of the model. It is reported as the model that served
+	// a request and used to look up the context size.
+	Name string
+	// Model is the model itself.
+	Model T
+	// ContextSize is the maximum number of tokens of the model. If zero,
+	// llms.GetModelContextSize(Name) is used.
+	ContextSize int
+}
+
+func (m Model[T]) contextSize() int {
+	if m.ContextSize > 0 {
+		return m.ContextSize
+	}
+	return llms.GetModelContextSize(m.Name)
+}
+
+func (m Model[T]) countTokens(texts ...string) int {
+	count := func(text string) int { return llms.CountTokens(m.Name, text) }
+	if lm, ok := any(m.Model).(llms.LanguageModel); ok {
+		count = lm.GetNumTokens
+	}
+	total := 0
+	for _, text := range texts {
+		total += count(text)
+	}
+	return total
+}
+
+type options struct {
+	fallbackIf    func(err error) bool
+	routeByTokens bool
+}
+
+// Option is a function that configures a fallback model.
+type Option func(*options)
+
+// WithFallbackOn sets the classes of errors, as returned by llms.ClassifyError,
+// that make the request fall back to the next model. If not set, the request
+// falls back on every retryable error class.
+func WithFallbackOn(classes ...llms.ErrorClass) Option {
+	return func(o *options) {
+		o.fallbackIf = func(err error) bool {
+			class := llms.ClassifyError(err)
+			for _, c := range classes {
+				if c == class {
+					return true
+				}
+			}
+			return false
+		}
+	}
+}
+
+// WithFallbackIf sets a function that decides whether an error makes the
+// request fall back to the next model.
+func WithFallbackIf(fallbackIf func(err error) bool) Option {
+	return func(o *options) {
+		o.fallbackIf = fallbackIf
+	}
+}
+
+// WithRouteByTokens skips the models whose context window cannot hold the
+// prompt and the requested max tokens.
+func WithRouteByTokens() Option {
+	return func(o *options) {
+		o.routeByTokens = true
+	}
+}
+
+func applyOptions(opts ...Option) options {
+	o := options{
+		fallbackIf: func(err error) bool {
+			return llms.ClassifyError(err).Retryable()
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// try calls fn for each model in order until one succeeds or fails with an
+// error that does not trigger a fallback. It returns the name of the model
+// that served the request.
+func try[T any](
+	ctx context.Context,
+	handler callbacks.Handler,
+	o options,
+	models []Model[T],
+	texts []string,
+	maxTokens int,
+	fn func(ctx context.Context, model T, streamed *atomic.Bool) error,
+) (string, error) {
+	if len(models) == 0 {
+		return "", ErrNoModels
+	}
+
+	var errs []error
+	tried := 0
+	for _, m := range models {
+		if o.routeByTokens && m.countTokens(texts...)+maxTokens > m.contextSize() {
+			continue
+		}
+		tried++
+
+		var streamed atomic.Bool
+		err := fn(ctx, m.Model, &streamed)
+		if err == nil {
+			return m.Name, nil
+		}
+		// Once output was streamed to the caller, falling back would repeat it.
+		if ctx.Err() != nil || streamed.Load() || !o.fallbackIf(err) {
+			return m.Name, err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", m.Name, err))
+		if handler != nil {
+			handler.HandleText(ctx, fmt.Sprintf("model %s failed, falling back: %v", m.Name, err))
+		}
+	}
+	if tried == 0 {
+		return "", ErrPromptTooLong
+	}
+	return "", fmt.Errorf("%w: %w", ErrAllModelsFailed, errors.Join(errs...))
+}
+
+// trackStreaming returns a call option that records in streamed whether the
+// streaming function was called.
+func trackStreaming(streamed *atomic.Bool) llms.CallOption {
+	return func(o *llms.CallOptions) {
+		streamingFunc := o.StreamingFunc
+		if streamingFunc == nil {
+			return
+		}
+		o.StreamingFunc = func(ctx context.Context, chunk []byte) error {
+			streamed.Store(true)
+			return streamingFunc(ctx, chunk)
+		}
+	}
+}
+
+// annotate records the serving model in the generations and reports it to the
+// callbacks handler.
+func annotate(ctx context.Context, handler callbacks.Handler, name string, generations []*llms.Generation) {
+	for _, g := range generations {
+		if g == nil {
+			continue
+		}
+		if g.GenerationInfo == nil {
+			g.GenerationInfo = make(map[string]any)
+		}
+		g.GenerationInfo[ModelKey] = name
+	}
+	if handler != nil {
+		handler.HandleLLMEnd(ctx, llms.LLMResult{
+			Generations: [][]*llms.Generation{generations},
+			LLMOutput:   map[string]any{ModelKey: name, llms.UsageKey: llms.TotalUsage(generations)},
+		})
+	}
+}
+
+// withTrackStreaming returns a copy of the options tracking streaming, as the
+// caller's slice may have spare capacity.
+func withTrackStreaming(options []llms.CallOption, streamed *atomic.Bool) []llms.CallOption {
+	return append(append([]llms.CallOption{}, options...), trackStreaming(streamed))
+}
+
+func callOptions(options []llms.CallOption) llms.CallOptions {
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	return opts
+}
@@ -0,0 +1,175 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/fake"
+	"github.com/tmc/langchaingo/prompts"
+	"github.com/tmc/langchaingo/schema"
+)
+
+func failWith(err error) fake.Option {
+	return fake.WithResponseFunc(func(context.Context, string) (string, error) {
+		return "", err
+	})
+}
+
+type recordingHandler struct {
+	callbacks.Handler
+	prompts [][]string
+	outputs []map[string]any
+	texts   []string
+}
+
+func (h *recordingHandler) HandleLLMStart(_ context.Context, prompts []string) {
+	h.prompts = append(h.prompts, prompts)
+}
+
+func (h *recordingHandler) HandleLLMEnd(_ context.Context, res llms.LLMResult) {
+	h.outputs = append(h.outputs, res.LLMOutput)
+}
+
+func (h *recordingHandler) HandleText(_ context.Context, text string) {
+	h.texts = append(h.texts, text)
+}
+
+func TestLLMFallsBack(t *testing.T) {
+	t.Parallel()
+
+	handler := &recordingHandler{}
+	llm := NewLLM([]Model[llms.LLM]{
+		{Name: "primary", Model: fake.New(failWith(&llms.StatusError{StatusCode: http.StatusTooManyRequests}))},
+		{Name: "secondary", Model: fake.New(fake.WithResponses("hello"))},
+	})
+	llm.CallbacksHandler = handler
+
+	generations, err := llm.Generate(context.Background(), []string{"hi"})
+	require.NoError(t, err)
+	require.Len(t, generations, 1)
+	assert.Equal(t, "hello", generations[0].Text)
+	assert.Equal(t, "secondary", generations[0].GenerationInfo[ModelKey])
+	require.Len(t, handler.outputs, 1)
+	assert.Equal(t, "secondary", handler.outputs[0][ModelKey])
+	require.Len(t, handler.texts, 1)
+	assert.Contains(t, handler.texts[0], "primary")
+}
+
+func TestLLMDoesNotFallBackOnClientErrors(t *testing.T) {
+	t.Parallel()
+
+	secondary := fake.New(fake.WithResponses("hello"))
+	llm := NewLLM([]Model[llms.LLM]{
+		{Name: "primary", Model: fake.New(failWith(&llms.StatusError{StatusCode: http.StatusBadRequest}))},
+		{Name: "secondary", Model: secondary},
+	})
+
+	_, err := llm.Call(context.Background(), "hi")
+	var statusErr *llms.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Empty(t, secondary.Prompts())
+
+	llm = NewLLM(llm.models, WithFallbackOn(llms.ErrorClassClient))
+	out, err := llm.Call(context.Background(), "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", out)
+}
+
+func TestLLMAllModelsFailed(t *testing.T) {
+	t.Parallel()
+
+	llm := NewLLM([]Model[llms.LLM]{
+		{Name: "a", Model: fake.New(failWith(&llms.StatusError{StatusCode: http.StatusBadGateway}))},
+		{Name: "b", Model: fake.New(failWith(&llms.StatusError{StatusCode: http.StatusServiceUnavailable}))},
+	})
+
+	_, err := llm.Call(context.Background(), "hi")
+	require.ErrorIs(t, err, ErrAllModelsFailed)
+	assert.Contains(t, err.Error(), "a: ")
+	assert.Contains(t, err.Error(), "b: ")
+
+	_, err = NewLLM(nil).Call(context.Background(), "hi")
+	require.ErrorIs(t, err, ErrNoModels)
+}
+
+func TestLLMDoesNotFallBackAfterStreaming(t *testing.T) {
+	t.Parallel()
+
+	errStream := errors.New("stream broke")
+	secondary := fake.New(fake.WithResponses("hello"))
+	llm := NewLLM([]Model[llms.LLM]{
+		{Name: "primary", Model: fake.New(fake.WithResponses("one two"))},
+		{Name: "secondary", Model: secondary},
+	}, WithFallbackIf(func(error) bool { return true }))
+
+	_, err := llm.Call(context.Background(), "hi", llms.WithStreamingFunc(func(context.Context, []byte) error {
+		return errStream
+	}))
+	require.ErrorIs(t, err, errStream)
+	assert.Empty(t, secondary.Prompts())
+}
+
+func TestLLMRouteByTokens(t *testing.T) {
+	t.Parallel()
+
+	llm := NewLLM([]Model[llms.LLM]{
+		{Name: "small", Model: fake.New(fake.WithResponses("small")), ContextSize: 4},
+		{Name: "large", Model: fake.New(fake.WithResponses("large")), ContextSize: 100},
+	}, WithRouteByTokens())
+
+	out, err := llm.Call(context.Background(), "one two")
+	require.NoError(t, err)
+	assert.Equal(t, "small", out)
+
+	out, err = llm.Call(context.Background(), strings.Repeat("word ", 10))
+	require.NoError(t, err)
+	assert.Equal(t, "large", out)
+
+	_, err = llm.Call(context.Background(), strings.Repeat("word ", 200))
+	require.ErrorIs(t, err, ErrPromptTooLong)
+}
+
+func TestChatFallsBack(t *testing.T) {
+	t.Parallel()
+
+	chat := NewChat([]Model[llms.ChatLLM]{
+		{Name: "primary", Model: fake.NewChat(failWith(&llms.StatusError{StatusCode: http.StatusInternalServerError}))},
+		{Name: "secondary", Model: fake.NewChat(fake.WithResponses("hello"))},
+	})
+
+	generations, err := chat.Generate(context.Background(), [][]schema.ChatMessage{
+		{schema.HumanChatMessage{Content: "hi"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, generations, 1)
+	assert.Equal(t, "hello", generations[0].Message.Content)
+	assert.Equal(t, "secondary", generations[0].GenerationInfo[ModelKey])
+}
+
+func TestLanguageModelFallsBack(t *testing.T) {
+	t.Parallel()
+
+	handler := &recordingHandler{}
+	lm := NewLanguageModel([]Model[llms.LanguageModel]{
+		{Name: "primary", Model: fake.New(failWith(&llms.StatusError{StatusCode: http.StatusTooManyRequests}))},
+		{Name: "secondary", Model: fake.NewChat(fake.WithResponses("hello"))},
+	})
+	lm.CallbacksHandler = handler
+
+	result, err := lm.GeneratePrompt(context.Background(), []schema.PromptValue{
+		prompts.StringPromptValue("hi"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "secondary", result.LLMOutput[ModelKey])
+	assert.Equal(t, "hello", result.Generations[0][0].Text)
+	assert.Equal(t, "secondary", result.Generations[0][0].GenerationInfo[ModelKey])
+	assert.Equal(t, [][]string{{"hi"}}, handler.prompts)
+	require.Len(t, handler.outputs, 1)
+}
@@ -0,0 +1,70 @@
+package fallback
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// LanguageModel is an llms.LanguageModel that tries a list of language models
+// in order.
+type LanguageModel struct {
+	CallbacksHandler callbacks.Handler
+	models           []Model[llms.LanguageModel]
+	opts             options
+}
+
+var _ llms.LanguageModel = (*LanguageModel)(nil)
+
+// NewLanguageModel returns a new LanguageModel that tries the given models in order.
+func NewLanguageModel(models []Model[llms.LanguageModel], opts ...Option) *LanguageModel {
+	return &LanguageModel{
+		models: models,
+		opts:   applyOptions(opts...),
+	}
+}
+
+// GeneratePrompt generates a result from the first model that serves the request.
+func (l *LanguageModel) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
+	prompts := make([]string, 0, len(promptValues))
+	for _, pv := range promptValues {
+		prompts = append(prompts, pv.String())
+	}
+	if l.CallbacksHandler != nil {
+		l.CallbacksHandler.HandleLLMStart(ctx, prompts)
+	}
+
+	var result llms.LLMResult
+	name, err := try(ctx, l.CallbacksHandler, l.opts, l.models, longest(prompts), callOptions(options).MaxTokens,
+		func(ctx context.Context, model llms.LanguageModel, streamed *atomic.Bool) error {
+			var err error
+			result, err = model.GeneratePrompt(ctx, promptValues, withTrackStreaming(options, streamed)...)
+			return err
+		})
+	if err != nil {
+		return llms.LLMResult{}, err
+	}
+
+	for _, generations := range result.Generations {
+		annotate(ctx, nil, name, generations)
+	}
+	if result.LLMOutput == nil {
+		result.LLMOutput = make(map[string]any)
+	}
+	result.LLMOutput[ModelKey] = name
+	if l.CallbacksHandler != nil {
+		l.CallbacksHandler.HandleLLMEnd(ctx, result)
+	}
+	return result, nil
+}
+
+// GetNumTokens returns the number of tokens of the text for the first model.
+func (l *LanguageModel) GetNumTokens(text string) int {
+	if len(l.models) == 0 {
+		return llms.CountTokens("", text)
+	}
+	return l.models[0].Model.GetNumTokens(text)
+}
@@ -0,0 +1,90 @@
+package fallback
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// LLM is an llms.LLM that tries a list of LLMs in order.
+type LLM struct {
+	CallbacksHandler callbacks.Handler
+	models           []Model[llms.LLM]
+	opts             options
+}
+
+var (
+	_ llms.LLM           = (*LLM)(nil)
+	_ llms.LanguageModel = (*LLM)(nil)
+)
+
+// NewLLM returns a new LLM that tries the given models in order.
+func NewLLM(models []Model[llms.LLM], opts ...Option) *LLM {
+	return &LLM{
+		models: models,
+		opts:   applyOptions(opts...),
+	}
+}
+
+// Call requests a completion for the given prompt.
+func (l *LLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	r, err := l.Generate(ctx, []string{prompt}, options...)
+	if err != nil {
+		return "", err
+	}
+	if len(r) == 0 {
+		return "", ErrEmptyResponse
+	}
+	return r[0].Text, nil
+}
+
+// Generate requests completions from the first model that serves the request.
+func (l *LLM) Generate(ctx context.Context, prompts []string, options ...llms.CallOption) ([]*llms.Generation, error) {
+	if l.CallbacksHandler != nil {
+		l.CallbacksHandler.HandleLLMStart(ctx, prompts)
+	}
+
+	var generations []*llms.Generation
+	name, err := try(ctx, l.CallbacksHandler, l.opts, l.models, longest(prompts), callOptions(options).MaxTokens,
+		func(ctx context.Context, model llms.LLM, streamed *atomic.Bool) error {
+			var err error
+			generations, err = model.Generate(ctx, prompts, withTrackStreaming(options, streamed)...)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	annotate(ctx, l.CallbacksHandler, name, generations)
+	return generations, nil
+}
+
+func (l *LLM) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
+	return llms.GeneratePrompt(ctx, l, promptValues, options...)
+}
+
+// GetNumTokens returns the number of tokens of the text for the first model.
+func (l *LLM) GetNumTokens(text string) int {
+	if len(l.models) == 0 {
+		return llms.CountTokens("", text)
+	}
+	return l.models[0].countTokens(text)
+}
+
+// longest returns the longest of the texts, which decides whether a batch of
+// prompts fits the context window of a model.
+func longest(texts []string) []string {
+	if len(texts) == 0 {
+		return nil
+	}
+	longest := texts[0]
+	for _, text := range texts[1:] {
+		if len(text) > len(longest) {
+			longest = text
+		}
+	}
+	return []string{longest}
+}
@@ -0,0 +1,225 @@
+package functions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+const (
+	humanPrefix = "Human"
+	aiPrefix    = "AI"
+)
+
+// generateFunc generates a reply to a set of messages with the wrapped model.
+type generateFunc func(ctx context.Context, messages []schema.ChatMessage, options []llms.CallOption) (*llms.Generation, error) // nolint:lll
+
+// Chat is an llms.ChatLLM that emulates function calling with the wrapped
+// model.
+type Chat struct {
+	CallbacksHandler callbacks.Handler
+	generate         generateFunc
+	numTokens        func(text string) int
+	opts             options
+}
+
+var (
+	_ llms.ChatLLM       = (*Chat)(nil)
+	_ llms.LanguageModel = (*Chat)(nil)
+)
+
+// NewChat returns a new Chat that emulates function calling with the given
+// chat LLM.
+func NewChat(chat llms.ChatLLM, opts ...Option) *Chat {
+	generate := func(ctx context.Context, messages []schema.ChatMessage, options []llms.CallOption) (*llms.Generation, error) { // nolint:lll
+		generations, err := chat.Generate(ctx, [][]schema.ChatMessage{messages}, options...)
+		if err != nil {
+			return nil, err
+		}
+		if len(generations) == 0 {
+			return nil, ErrEmptyResponse
+		}
+		return generations[0], nil
+	}
+	return &Chat{
+		generate:  generate,
+		numTokens: numTokensFunc(chat),
+		opts:      applyOptions(opts...),
+	}
+}
+
+// NewChatFromLLM returns a new Chat that emulates function calling with the
+// given LLM. The messages are rendered into a single prompt.
+func NewChatFromLLM(llm llms.LLM, opts ...Option) *Chat {
+	generate := func(ctx context.Context, messages []schema.ChatMessage, options []llms.CallOption) (*llms.Generation, error) { // nolint:lll
+		prompt, err := schema.GetBufferString(messages, humanPrefix, aiPrefix)
+		if err != nil {
+			return nil, err
+		}
+		generations, err := llm.Generate(ctx, []string{prompt}, options...)
+		if err != nil {
+			return nil, err
+		}
+		if len(generations) == 0 {
+			return nil, ErrEmptyResponse
+		}
+		generations[0].Message = &schema.AIChatMessage{Content: generations[0].Text}
+		return generations[0], nil
+	}
+	return &Chat{
+		generate:  generate,
+		numTokens: numTokensFunc(llm),
+		opts:      applyOptions(opts...),
+	}
+}
+
+// Call requests a chat response for the given messages.
+func (c *Chat) Call(ctx context.Context, messages []schema.ChatMessage, options ...llms.CallOption) (*schema.AIChatMessage, error) { // nolint: lll
+	r, err := c.Generate(ctx, [][]schema.ChatMessage{messages}, options...)
+	if err != nil {
+		return nil, err
+	}
+	if len(r) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	return r[0].Message, nil
+}
+
+// Generate requests a chat response for each of the sets of messages. If
+// functions are passed with llms.WithFunctions, the reply of the model is
+// parsed into the FunctionCall of the returned message.
+func (c *Chat) Generate(ctx context.Context, messageSets [][]schema.ChatMessage, options ...llms.CallOption) ([]*llms.Generation, error) { // nolint:lll
+	if c.CallbacksHandler != nil {
+		c.CallbacksHandler.HandleLLMStart(ctx, getPromptsFromMessageSets(messageSets))
+	}
+
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	generations := make([]*llms.Generation, 0, len(messageSets))
+	for _, messages := range messageSets {
+		g, err := c.generateOne(ctx, messages, opts, options)
+		if err != nil {
+			return nil, err
+		}
+		generations = append(generations, g)
+	}
+
+	if c.CallbacksHandler != nil {
+		c.CallbacksHandler.HandleLLMEnd(ctx, llms.NewLLMResult(generations))
+	}
+	return generations, nil
+}
+
+func (c *Chat) generateOne(ctx context.Context, messages []schema.ChatMessage, opts llms.CallOptions, options []llms.CallOption) (*llms.Generation, error) { // nolint:lll
+	// The options are copied, as the caller's slice may have spare capacity
+	// shared by the message sets.
+	options = append(append([]llms.CallOption{}, options...), withoutFunctions)
+	if len(opts.Functions) == 0 || opts.FunctionCallBehavior == llms.FunctionCallBehaviorNone {
+		return c.generate(ctx, messages, options)
+	}
+
+	forced := forcedFunction(opts.FunctionCallBehavior)
+	system, err := instructions(opts.Functions, forced)
+	if err != nil {
+		return nil, err
+	}
+	messages = append([]schema.ChatMessage{schema.SystemChatMessage{Content: system}}, messages...)
+	// The reply is only known to be text once it is complete.
+	options = append(options, withoutStreaming)
+
+	var (
+		usage    llms.Usage
+		hasUsage bool
+	)
+	for attempt := 0; ; attempt++ {
+		g, err := c.generate(ctx, messages, options)
+		if err != nil {
+			return nil, err
+		}
+		// The usage is seeded with the first one, as Add only keeps the model
+		// of usages of the same model.
+		u, ok := llms.GetUsage(g)
+		switch {
+		case !ok:
+		case !hasUsage:
+			usage, hasUsage = u, true
+		default:
+			usage = usage.Add(u)
+		}
+
+		call, err := parse(g.Text, opts.Functions, forced)
+		if err != nil {
+			if attempt >= c.opts.maxRetries {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidFunctionCall, err)
+			}
+			messages = append(messages,
+				schema.AIChatMessage{Content: g.Text},
+				schema.HumanChatMessage{Content: correction(err)},
+			)
+			continue
+		}
+
+		usage.FinishReason = u.FinishReason
+		msg := &schema.AIChatMessage{Content: g.Text}
+		if call != nil {
+			msg = &schema.AIChatMessage{FunctionCall: call}
+			usage.FinishReason = "function_call"
+		} else if opts.StreamingFunc != nil {
+			if err := opts.StreamingFunc(ctx, []byte(g.Text)); err != nil {
+				return nil, err
+			}
+		}
+		g.Message = msg
+		g.Text = msg.Content
+		if hasUsage {
+			llms.SetUsage(g, usage)
+		}
+		return g, nil
+	}
+}
+
+func (c *Chat) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
+	return llms.GenerateChatPrompt(ctx, c, promptValues, options...)
+}
+
+func (c *Chat) GetNumTokens(text string) int {
+	return c.numTokens(text)
+}
+
+// withoutFunctions keeps the wrapped model from receiving the functions, as
+// they are described in the prompt instead.
+func withoutFunctions(o *llms.CallOptions) {
+	o.Functions = nil
+	o.FunctionCallBehavior = ""
+}
+
+func withoutStreaming(o *llms.CallOptions) {
+	o.StreamingFunc = nil
+}
+
+func numTokensFunc(model any) func(text string) int {
+	if lm, ok := model.(llms.LanguageModel); ok {
+		return lm.GetNumTokens
+	}
+	return func(text string) int {
+		return llms.CountTokens("", text)
+	}
+}
+
+func getPromptsFromMessageSets(messageSets [][]schema.ChatMessage) []string {
+	prompts := make([]string, 0, len(messageSets))
+	for _, messages := range messageSets {
+		prompt := ""
+		for _, m := range messages {
+			prompt += m.GetContent()
+		}
+		prompts = append(prompts, prompt)
+	}
+	return prompts
+}
@@ -0,0 +1,166 @@
+// Package functions emulates function calling for LLMs and chat LLMs without
+// native support for it.
+//
+// The wrappers inject the definitions passed with llms.WithFunctions into the
+// prompt, instruct the model to reply with a JSON object when it calls a
+// function and parse that reply into the FunctionCall of the returned
+// schema.AIChatMessage. Replies that name an unknown function or miss
+// required arguments are sent back to the model with the error, up to the
+// number of retries set with WithMaxRetries.
+package functions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+var (
+	// ErrEmptyResponse is returned when the wrapped model returns no generations.
+	ErrEmptyResponse = errors.New("no response")
+	// ErrInvalidFunctionCall is returned, wrapping the last parse error, when
+	// the model did not reply with a valid function call within the retries.
+	ErrInvalidFunctionCall = errors.New("invalid function call")
+)
+
+const instructionsTemplate = `You have access to the following functions, described as JSON schemas:
+
+%s
+
+%s Respond with only a JSON object of the form:
+{"function_call": {"name": "<function name>", "arguments": {<arguments matching the schema>}}}`
+
+// instructions returns the system prompt that describes the functions to the
+// model. If function is not empty, the model must call that function.
+func instructions(functions []llms.FunctionDefinition, function string) (string, error) {
+	definitions, err := json.MarshalIndent(functions, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal functions: %w", err)
+	}
+	when := "To call a function, do not answer in text."
+	if function != "" {
+		when = fmt.Sprintf("You must call the function %q.", function)
+	}
+	return fmt.Sprintf(instructionsTemplate, definitions, when), nil
+}
+
+// forcedFunction returns the name of the function the behavior forces the
+// model to call, if any.
+func forcedFunction(behavior llms.FunctionCallBehavior) string {
+	switch behavior {
+	case "", llms.FunctionCallBehaviorAuto, llms.FunctionCallBehaviorNone:
+		return ""
+	}
+	var forced struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(behavior), &forced); err != nil {
+		return string(behavior)
+	}
+	return forced.Name
+}
+
+type reply struct {
+	FunctionCall *struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function_call"`
+}
+
+// parse parses the text of a reply. It returns a nil function call if no
+// function is forced and the reply does not attempt to call one.
+func parse(text string, functions []llms.FunctionDefinition, forced string) (*schema.FunctionCall, error) {
+	if forced == "" && !strings.Contains(text, `"function_call"`) {
+		return nil, nil // nolint:nilnil
+	}
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("the reply does not contain a JSON object")
+	}
+
+	var r reply
+	if err := json.Unmarshal([]byte(text[start:end+1]), &r); err != nil {
+		return nil, fmt.Errorf("the reply is not valid JSON: %w", err)
+	}
+	if r.FunctionCall == nil {
+		return nil, errors.New(`the reply does not contain a "function_call" object`)
+	}
+	if forced != "" && r.FunctionCall.Name != forced {
+		return nil, fmt.Errorf("the reply calls %q instead of %q", r.FunctionCall.Name, forced)
+	}
+
+	var function *llms.FunctionDefinition
+	for i := range functions {
+		if functions[i].Name == r.FunctionCall.Name {
+			function = &functions[i]
+			break
+		}
+	}
+	if function == nil {
+		return nil, fmt.Errorf("the function %q does not exist", r.FunctionCall.Name)
+	}
+
+	arguments, err := parseArguments(r.FunctionCall.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRequired(function, arguments); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(arguments)
+	if err != nil {
+		return nil, err
+	}
+	return &schema.FunctionCall{Name: function.Name, Arguments: string(b)}, nil
+}
+
+// parseArguments parses the arguments of a function call, which models
+// return either as an object or as a string holding an object.
+func parseArguments(raw json.RawMessage) (map[string]any, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return map[string]any{}, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		raw = json.RawMessage(s)
+	}
+	var arguments map[string]any
+	if err := json.Unmarshal(raw, &arguments); err != nil {
+		return nil, fmt.Errorf("the arguments are not a JSON object: %w", err)
+	}
+	return arguments, nil
+}
+
+// checkRequired checks that the arguments hold the properties required by the
+// schema of the function parameters.
+func checkRequired(function *llms.FunctionDefinition, arguments map[string]any) error {
+	b, err := json.Marshal(function.Parameters)
+	if err != nil {
+		return fmt.Errorf("marshal parameters of %q: %w", function.Name, err)
+	}
+	var parameters struct {
+		Required []string `json:"required"`
+	}
+	// Parameters that are not an object schema have no required properties.
+	_ = json.Unmarshal(b, &parameters)
+
+	var missing []string
+	for _, name := range parameters.Required {
+		if _, ok := arguments[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the arguments miss the required properties %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// correction returns the message sent to the model after an invalid reply.
+func correction(err error) string {
+	return fmt.Sprintf("Your reply is not a valid function call: %v. Respond again with only the JSON object.", err)
+}
@@ -0,0 +1,203 @@
+package functions
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/fake"
+	"github.com/tmc/langchaingo/schema"
+)
+
+var weatherFunctions = []llms.FunctionDefinition{{
+	Name:        "get_weather",
+	Description: "Get the current weather in a location",
+	Parameters: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"location": map[string]any{"type": "string"},
+			"unit":     map[string]any{"type": "string", "enum": []string{"celsius", "fahrenheit"}},
+		},
+		"required": []string{"location"},
+	},
+}}
+
+func TestChatFunctionCall(t *testing.T) {
+	t.Parallel()
+
+	inner := fake.NewChat(fake.WithResponses(
+		`Sure. {"function_call": {"name": "get_weather", "arguments": {"location": "Boston"}}}`,
+	))
+	chat := NewChat(inner)
+
+	msg, err := chat.Call(context.Background(), []schema.ChatMessage{
+		schema.HumanChatMessage{Content: "What is the weather like in Boston?"},
+	}, llms.WithFunctions(weatherFunctions))
+	require.NoError(t, err)
+	require.NotNil(t, msg.FunctionCall)
+	assert.Equal(t, "get_weather", msg.FunctionCall.Name)
+	assert.JSONEq(t, `{"location": "Boston"}`, msg.FunctionCall.Arguments)
+
+	// The functions are described in a system message.
+	sent := inner.Messages()[0]
+	require.Len(t, sent, 2)
+	assert.Equal(t, schema.ChatMessageTypeSystem, sent[0].GetType())
+	assert.Contains(t, sent[0].GetContent(), "get_weather")
+}
+
+func TestChatTextReply(t *testing.T) {
+	t.Parallel()
+
+	var streamed []byte
+	chat := NewChat(fake.NewChat(fake.WithResponses("Hello there")))
+	msg, err := chat.Call(context.Background(), []schema.ChatMessage{
+		schema.HumanChatMessage{Content: "Hi"},
+	}, llms.WithFunctions(weatherFunctions), llms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+		streamed = append(streamed, chunk...)
+		return nil
+	}))
+	require.NoError(t, err)
+	assert.Nil(t, msg.FunctionCall)
+	assert.Equal(t, "Hello there", msg.Content)
+	assert.Equal(t, "Hello there", string(streamed))
+}
+
+func TestChatRetriesInvalidReply(t *testing.T) {
+	t.Parallel()
+
+	inner := fake.NewChat(fake.WithResponses(
+		`{"function_call": {"name": "get_forecast", "arguments": {}}}`,
+		`{"function_call": {"name": "get_weather", "arguments": {"unit": "celsius"}}}`,
+		`{"function_call": {"name": "get_weather", "arguments": "{\"location\": \"Paris\"}"}}`,
+	))
+	chat := NewChat(inner)
+
+	generations, err := chat.Generate(context.Background(), [][]schema.ChatMessage{{
+		schema.HumanChatMessage{Content: "Weather in Paris?"},
+	}}, llms.WithFunctions(weatherFunctions))
+	require.NoError(t, err)
+	require.Len(t, generations, 1)
+	msg := generations[0].Message
+	require.NotNil(t, msg.FunctionCall)
+	assert.JSONEq(t, `{"location": "Paris"}`, msg.FunctionCall.Arguments)
+
+	// The errors are sent back to the model.
+	sent := inner.Messages()
+	require.Len(t, sent, 3)
+	assert.Contains(t, sent[1][len(sent[1])-1].GetContent(), `"get_forecast" does not exist`)
+	assert.Contains(t, sent[2][len(sent[2])-1].GetContent(), "location")
+
+	// The usage of all attempts is accounted for.
+	usage, ok := llms.GetUsage(generations[0])
+	require.True(t, ok)
+	assert.Equal(t, "function_call", usage.FinishReason)
+	assert.Greater(t, usage.PromptTokens, 0)
+}
+
+// modelChat is a fake chat model reporting the model of its usage.
+type modelChat struct {
+	*fake.Chat
+	model string
+}
+
+func (c modelChat) Generate(ctx context.Context, messageSets [][]schema.ChatMessage, options ...llms.CallOption) ([]*llms.Generation, error) { // nolint:lll
+	generations, err := c.Chat.Generate(ctx, messageSets, options...)
+	for _, g := range generations {
+		if u, ok := llms.GetUsage(g); ok {
+			u.Model = c.model
+			llms.SetUsage(g, u)
+		}
+	}
+	return generations, err
+}
+
+func TestChatUsageKeepsModel(t *testing.T) {
+	t.Parallel()
+
+	chat := NewChat(modelChat{
+		Chat: fake.NewChat(fake.WithResponses(
+			"not a call",
+			`{"function_call": {"name": "get_weather", "arguments": {"location": "Rome"}}}`,
+		)),
+		model: "model-1",
+	})
+	costs := callbacks.NewCostHandler(callbacks.WithPricingTable(callbacks.PricingTable{
+		"model-1": {InputPer1KTokens: 1, OutputPer1KTokens: 1},
+	}))
+	chat.CallbacksHandler = costs
+
+	generations, err := chat.Generate(context.Background(), [][]schema.ChatMessage{{
+		schema.HumanChatMessage{Content: "Weather in Rome?"},
+	}}, llms.WithFunctions(weatherFunctions))
+	require.NoError(t, err)
+	usage, ok := llms.GetUsage(generations[0])
+	require.True(t, ok)
+	assert.Equal(t, "model-1", usage.Model)
+
+	report := costs.Report()
+	require.Contains(t, report.Models, "model-1")
+	assert.Greater(t, report.Total.USD, 0.0)
+	assert.Zero(t, report.Total.UnpricedTokens)
+}
+
+func TestChatRetriesExhausted(t *testing.T) {
+	t.Parallel()
+
+	chat := NewChat(fake.NewChat(fake.WithResponses("no", "still no")), WithMaxRetries(1))
+	_, err := chat.Call(context.Background(), []schema.ChatMessage{
+		schema.HumanChatMessage{Content: "Weather in Paris?"},
+	}, llms.WithFunctions(weatherFunctions), llms.WithFunctionCallBehavior(`{"name": "get_weather"}`))
+	require.ErrorIs(t, err, ErrInvalidFunctionCall)
+}
+
+func TestChatFromLLM(t *testing.T) {
+	t.Parallel()
+
+	inner := fake.New(fake.WithResponses(
+		`{"function_call": {"name": "get_weather", "arguments": {"location": "Oslo"}}}`,
+		"Plain answer",
+	))
+	chat := NewChatFromLLM(inner)
+
+	msg, err := chat.Call(context.Background(), []schema.ChatMessage{
+		schema.HumanChatMessage{Content: "Weather in Oslo?"},
+	}, llms.WithFunctions(weatherFunctions))
+	require.NoError(t, err)
+	require.NotNil(t, msg.FunctionCall)
+	var args map[string]string
+	require.NoError(t, json.Unmarshal([]byte(msg.FunctionCall.Arguments), &args))
+	assert.Equal(t, "Oslo", args["location"])
+	assert.Contains(t, inner.Prompts()[0], "System: You have access to the following functions")
+
+	// Without functions, the LLM is used as a plain chat model.
+	msg, err = chat.Call(context.Background(), []schema.ChatMessage{
+		schema.HumanChatMessage{Content: "Hi"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Plain answer", msg.Content)
+	assert.Equal(t, "Human: Hi", inner.Prompts()[1])
+}
+
+func TestChatDoesNotModifyOptions(t *testing.T) {
+	t.Parallel()
+
+	chat := NewChat(fake.NewChat(fake.WithResponses(
+		`{"function_call": {"name": "get_weather", "arguments": {"location": "Rome"}}}`,
+		"Plain answer",
+	)))
+	// The spare capacity of the options must not be written to.
+	options := make([]llms.CallOption, 1, 4)
+	options[0] = llms.WithFunctions(weatherFunctions)
+	_, err := chat.Generate(context.Background(), [][]schema.ChatMessage{
+		{schema.HumanChatMessage{Content: "Weather in Rome?"}},
+		{schema.HumanChatMessage{Content: "Hi"}},
+	}, options...)
+	require.NoError(t, err)
+	for _, opt := range options[1:cap(options)] {
+		assert.Nil(t, opt)
+	}
+}
@@ -0,0 +1,28 @@
+package functions
+
+const _defaultMaxRetries = 2
+
+type options struct {
+	maxRetries int
+}
+
+// Option is a function that configures the function calling emulation.
+type Option func(*options)
+
+// WithMaxRetries sets the number of times an invalid function call is sent
+// back to the model to be corrected. Defaults to 2.
+func WithMaxRetries(maxRetries int) Option {
+	return func(o *options) {
+		o.maxRetries = maxRetries
+	}
+}
+
+func applyOptions(opts ...Option) options {
+	o := options{
+		maxRetries: _defaultMaxRetries,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,51 @@
+package hftokenizer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// nolint:gochecknoglobals
+var byteToChar, charToByte = byteLevelAlphabet()
+
+// byteLevelAlphabet returns the mapping of the bytes to the printable
+// characters byte level tokenizers use, as GPT-2 does.
+func byteLevelAlphabet() ([256]rune, map[rune]byte) {
+	var chars [256]rune
+	bytes := make(map[rune]byte, 256)
+	n := 0
+	for b := 0; b < 256; b++ {
+		printable := (b >= '!' && b <= '~') || (b >= 0xA1 && b <= 0xAC) || (b >= 0xAE && b <= 0xFF)
+		r := rune(b)
+		if !printable {
+			r = rune(256 + n)
+			n++
+		}
+		chars[b] = r
+		bytes[r] = byte(b)
+	}
+	return chars, bytes
+}
+
+// bytesToChars returns the text with each byte replaced by its character.
+func bytesToChars(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for i := 0; i < len(text); i++ {
+		b.WriteRune(byteToChar[text[i]])
+	}
+	return b.String()
+}
+
+// charsToBytes returns the text of the bytes of the characters.
+func charsToBytes(text string) string {
+	bytes := make([]byte, 0, len(text))
+	for _, r := range text {
+		if b, ok := charToByte[r]; ok {
+			bytes = append(bytes, b)
+			continue
+		}
+		bytes = utf8.AppendRune(bytes, r)
+	}
+	return strings.ToValidUTF8(string(bytes), string(utf8.RuneError))
+}
@@ -0,0 +1,183 @@
+package hftokenizer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// decoder converts the tokens of a text back to the text.
+type decoder func(tokens []string) string
+
+// decodeStep transforms the tokens being decoded.
+type decodeStep func(tokens []string) []string
+
+func newDecoder(c *component) (decoder, error) {
+	if c == nil {
+		return func(tokens []string) string { return strings.Join(tokens, " ") }, nil
+	}
+	step, err := newDecodeStep(c)
+	if err != nil {
+		return nil, err
+	}
+	return func(tokens []string) string {
+		return strings.Join(step(tokens), "")
+	}, nil
+}
+
+func newDecodeStep(c *component) (decodeStep, error) { //nolint:cyclop,funlen
+	switch c.Type {
+	case "Sequence":
+		steps := make([]decodeStep, 0, len(c.Decoders))
+		for _, sub := range c.Decoders {
+			step, err := newDecodeStep(sub)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, step)
+		}
+		return func(tokens []string) []string {
+			for _, step := range steps {
+				tokens = step(tokens)
+			}
+			return tokens
+		}, nil
+	case "ByteLevel":
+		return func(tokens []string) []string {
+			return []string{charsToBytes(strings.Join(tokens, ""))}
+		}, nil
+	case "Metaspace":
+		replacement := c.Replacement
+		if replacement == "" {
+			replacement = _defaultMetaspace
+		}
+		stripFirst := c.PrependScheme != "never"
+		if c.PrependScheme == "" {
+			stripFirst = boolOr(c.AddPrefixSpace, true)
+		}
+		return mapTokens(func(i int, token string) string {
+			token = strings.ReplaceAll(token, replacement, " ")
+			if i == 0 && stripFirst {
+				token = strings.TrimPrefix(token, " ")
+			}
+			return token
+		}), nil
+	case "WordPiece":
+		prefix := c.Prefix
+		if prefix == "" {
+			prefix = _defaultWordPiecePrefix
+		}
+		cleanup := boolOr(c.Cleanup, true)
+		return mapTokens(func(i int, token string) string {
+			if i > 0 {
+				if strings.HasPrefix(token, prefix) {
+					token = strings.TrimPrefix(token, prefix)
+				} else {
+					token = " " + token
+				}
+			}
+			if cleanup {
+				token = cleanupTokenization(token)
+			}
+			return token
+		}), nil
+	case "BPEDecoder":
+		suffix := c.Suffix
+		if suffix == "" {
+			suffix = "</w>"
+		}
+		return func(tokens []string) []string {
+			return mapTokens(func(i int, token string) string {
+				if i == len(tokens)-1 {
+					return strings.ReplaceAll(token, suffix, "")
+				}
+				return strings.ReplaceAll(token, suffix, " ")
+			})(tokens)
+		}, nil
+	case "Replace":
+		replace, err := newReplace(c)
+		if err != nil {
+			return nil, err
+		}
+		return mapTokens(func(_ int, token string) string { return replace(token) }), nil
+	case "ByteFallback":
+		return byteFallback, nil
+	case "Fuse":
+		return func(tokens []string) []string { return []string{strings.Join(tokens, "")} }, nil
+	case "Strip":
+		return mapTokens(func(_ int, token string) string {
+			for i := 0; i < c.Start && strings.HasPrefix(token, c.Content); i++ {
+				token = strings.TrimPrefix(token, c.Content)
+			}
+			for i := 0; i < c.Stop && strings.HasSuffix(token, c.Content); i++ {
+				token = strings.TrimSuffix(token, c.Content)
+			}
+			return token
+		}), nil
+	}
+	return nil, fmt.Errorf("%w: decoder %q", ErrUnsupported, c.Type)
+}
+
+func mapTokens(f func(i int, token string) string) decodeStep {
+	return func(tokens []string) []string {
+		mapped := make([]string, len(tokens))
+		for i, token := range tokens {
+			mapped[i] = f(i, token)
+		}
+		return mapped
+	}
+}
+
+// byteFallback converts the consecutive <0xXX> tokens to the text of their
+// bytes.
+func byteFallback(tokens []string) []string {
+	result := make([]string, 0, len(tokens))
+	var pending []byte
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		if utf8.Valid(pending) {
+			result = append(result, string(pending))
+		} else {
+			for range pending {
+				result = append(result, string(utf8.RuneError))
+			}
+		}
+		pending = nil
+	}
+	for _, token := range tokens {
+		if len(token) == 6 && strings.HasPrefix(token, "<0x") && strings.HasSuffix(token, ">") {
+			if b, err := strconv.ParseUint(token[3:5], 16, 8); err == nil {
+				pending = append(pending, byte(b))
+				continue
+			}
+		}
+		flush()
+		result = append(result, token)
+	}
+	flush()
+	return result
+}
+
+// nolint:gochecknoglobals
+var cleanupReplacer = strings.NewReplacer(
+	" .", ".",
+	" ?", "?",
+	" !", "!",
+	" ,", ",",
+	" ' ", "'",
+	" n't", "n't",
+	" 'm", "'m",
+	" do not", " don't",
+	" 's", "'s",
+	" 've", "'ve",
+	" 're", "'re",
+)
+
+// cleanupTokenization removes the spaces before the punctuation and the
+// contractions.
+func cleanupTokenization(text string) string {
+	return cleanupReplacer.Replace(text)
+}
@@ -0,0 +1,396 @@
+package hftokenizer
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	_defaultMaxInputCharsPerWord = 100
+	_defaultWordPiecePrefix      = "##"
+	_maxCachedWordLength         = 64
+	_maxCacheSize                = 10000
+)
+
+// model converts the pre-tokenized pieces of a text to token ids.
+type model interface {
+	// tokenize appends the ids of the tokens of the piece to ids.
+	tokenize(ids []int, piece string) []int
+	idToToken(id int) (string, bool)
+	tokenToID(token string) (int, bool)
+}
+
+type modelConfig struct {
+	Type                    string            `json:"type"`
+	Vocab                   map[string]int    `json:"vocab"`
+	Merges                  []json.RawMessage `json:"merges"`
+	UnkToken                *string           `json:"unk_token"`
+	ContinuingSubwordPrefix *string           `json:"continuing_subword_prefix"`
+	EndOfWordSuffix         *string           `json:"end_of_word_suffix"`
+	FuseUnk                 bool              `json:"fuse_unk"`
+	ByteFallback            bool              `json:"byte_fallback"`
+	IgnoreMerges            bool              `json:"ignore_merges"`
+	MaxInputCharsPerWord    int               `json:"max_input_chars_per_word"`
+}
+
+func newModel(raw json.RawMessage) (model, error) {
+	var header struct {
+		Type   string          `json:"type"`
+		Merges json.RawMessage `json:"merges"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("decode model: %w", err)
+	}
+	if header.Type == "" && header.Merges != nil {
+		// Older files do not name the type of BPE models.
+		header.Type = "BPE"
+	}
+	switch header.Type {
+	case "BPE", "WordPiece", "WordLevel":
+	default:
+		return nil, fmt.Errorf("%w: model %q", ErrUnsupported, header.Type)
+	}
+
+	var c modelConfig
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, fmt.Errorf("decode model: %w", err)
+	}
+	c.Type = header.Type
+
+	v := newVocab(c.Vocab)
+	switch c.Type {
+	case "WordPiece":
+		wp := &wordPiece{
+			vocab:        v,
+			prefix:       _defaultWordPiecePrefix,
+			maxInputLen:  c.MaxInputCharsPerWord,
+			unknownToken: -1,
+		}
+		if c.ContinuingSubwordPrefix != nil {
+			wp.prefix = *c.ContinuingSubwordPrefix
+		}
+		if wp.maxInputLen == 0 {
+			wp.maxInputLen = _defaultMaxInputCharsPerWord
+		}
+		if c.UnkToken != nil {
+			if id, ok := v.tokens[*c.UnkToken]; ok {
+				wp.unknownToken = id
+			}
+		}
+		return wp, nil
+	case "WordLevel":
+		wl := &wordLevel{vocab: v, unknownToken: -1}
+		if c.UnkToken != nil {
+			if id, ok := v.tokens[*c.UnkToken]; ok {
+				wl.unknownToken = id
+			}
+		}
+		return wl, nil
+	}
+	return newBPE(c, v)
+}
+
+// vocab maps the tokens to their ids and back.
+type vocab struct {
+	tokens map[string]int
+	ids    map[int]string
+}
+
+func newVocab(tokens map[string]int) vocab {
+	ids := make(map[int]string, len(tokens))
+	for token, id := range tokens {
+		ids[id] = token
+	}
+	return vocab{tokens: tokens, ids: ids}
+}
+
+func (v vocab) idToToken(id int) (string, bool) {
+	token, ok := v.ids[id]
+	return token, ok
+}
+
+func (v vocab) tokenToID(token string) (int, bool) {
+	id, ok := v.tokens[token]
+	return id, ok
+}
+
+// wordLevel maps each piece to a token of the vocabulary.
+type wordLevel struct {
+	vocab
+	unknownToken int
+}
+
+func (m *wordLevel) tokenize(ids []int, piece string) []int {
+	if id, ok := m.tokens[piece]; ok {
+		return append(ids, id)
+	}
+	if m.unknownToken >= 0 {
+		return append(ids, m.unknownToken)
+	}
+	return ids
+}
+
+// wordPiece splits each piece in the longest tokens of the vocabulary, from
+// left to right.
+type wordPiece struct {
+	vocab
+	prefix       string
+	maxInputLen  int
+	unknownToken int
+}
+
+func (m *wordPiece) tokenize(ids []int, piece string) []int {
+	runes := []rune(piece)
+	if len(runes) > m.maxInputLen {
+		return m.unknown(ids)
+	}
+
+	n := len(ids)
+	for start := 0; start < len(runes); {
+		end := len(runes)
+		found := -1
+		for ; start < end; end-- {
+			sub := string(runes[start:end])
+			if start > 0 {
+				sub = m.prefix + sub
+			}
+			if id, ok := m.tokens[sub]; ok {
+				found = id
+				break
+			}
+		}
+		if found < 0 {
+			// The whole piece is unknown if any part of it is.
+			return m.unknown(ids[:n])
+		}
+		ids = append(ids, found)
+		start = end
+	}
+	return ids
+}
+
+func (m *wordPiece) unknown(ids []int) []int {
+	if m.unknownToken >= 0 {
+		return append(ids, m.unknownToken)
+	}
+	return ids
+}
+
+type mergePair struct {
+	left, right string
+}
+
+// bpe merges the characters of each piece following the ranked merges.
+type bpe struct {
+	vocab
+	ranks        map[mergePair]int
+	unknownToken int
+	prefix       string
+	suffix       string
+	fuseUnknown  bool
+	byteFallback bool
+	ignoreMerges bool
+
+	mu    sync.RWMutex
+	cache map[string][]int
+}
+
+func newBPE(c modelConfig, v vocab) (*bpe, error) {
+	m := &bpe{
+		vocab:        v,
+		ranks:        make(map[mergePair]int, len(c.Merges)),
+		unknownToken: -1,
+		fuseUnknown:  c.FuseUnk,
+		byteFallback: c.ByteFallback,
+		ignoreMerges: c.IgnoreMerges,
+		cache:        make(map[string][]int),
+	}
+	if c.UnkToken != nil {
+		if id, ok := v.tokens[*c.UnkToken]; ok {
+			m.unknownToken = id
+		}
+	}
+	if c.ContinuingSubwordPrefix != nil {
+		m.prefix = *c.ContinuingSubwordPrefix
+	}
+	if c.EndOfWordSuffix != nil {
+		m.suffix = *c.EndOfWordSuffix
+	}
+
+	for rank, raw := range c.Merges {
+		pair, err := parseMerge(raw)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := m.ranks[pair]; !ok {
+			m.ranks[pair] = rank
+		}
+	}
+	return m, nil
+}
+
+// parseMerge parses a merge, written as "left right" or ["left", "right"].
+func parseMerge(raw json.RawMessage) (mergePair, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		left, right, ok := strings.Cut(s, " ")
+		if !ok {
+			return mergePair{}, fmt.Errorf("invalid merge %q", s) // nolint:goerr113
+		}
+		return mergePair{left: left, right: right}, nil
+	}
+	var pair []string
+	if err := json.Unmarshal(raw, &pair); err != nil || len(pair) != 2 {
+		return mergePair{}, fmt.Errorf("invalid merge %s", string(raw)) // nolint:goerr113
+	}
+	return mergePair{left: pair[0], right: pair[1]}, nil
+}
+
+func (m *bpe) tokenize(ids []int, piece string) []int {
+	cacheable := len(piece) <= _maxCachedWordLength
+	if cacheable {
+		m.mu.RLock()
+		cached, ok := m.cache[piece]
+		m.mu.RUnlock()
+		if ok {
+			return append(ids, cached...)
+		}
+	}
+
+	n := len(ids)
+	ids = m.tokenizeWord(ids, piece)
+
+	if cacheable {
+		m.mu.Lock()
+		if len(m.cache) < _maxCacheSize {
+			m.cache[piece] = append([]int(nil), ids[n:]...)
+		}
+		m.mu.Unlock()
+	}
+	return ids
+}
+
+// symbol is a part of a word being merged, in a linked list of the parts.
+type symbol struct {
+	text       string
+	prev, next int
+	merged     bool
+}
+
+// mergeCandidate is a pair of adjacent symbols that can be merged.
+type mergeCandidate struct {
+	rank, pos   int
+	left, right string
+}
+
+type mergeQueue []mergeCandidate
+
+func (q mergeQueue) Len() int { return len(q) }
+func (q mergeQueue) Less(i, j int) bool {
+	if q[i].rank != q[j].rank {
+		return q[i].rank < q[j].rank
+	}
+	return q[i].pos < q[j].pos
+}
+func (q mergeQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *mergeQueue) Push(x any)   { *q = append(*q, x.(mergeCandidate)) } // nolint:forcetypeassert
+func (q *mergeQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+func (m *bpe) tokenizeWord(ids []int, word string) []int { //nolint:cyclop
+	if m.ignoreMerges {
+		if id, ok := m.tokens[word]; ok {
+			return append(ids, id)
+		}
+	}
+
+	runes := []rune(word)
+	symbols := make([]symbol, len(runes))
+	for i, r := range runes {
+		text := string(r)
+		if i > 0 {
+			text = m.prefix + text
+		}
+		if i == len(runes)-1 {
+			text += m.suffix
+		}
+		symbols[i] = symbol{text: text, prev: i - 1, next: i + 1}
+	}
+	symbols[len(symbols)-1].next = -1
+
+	queue := &mergeQueue{}
+	push := func(pos int) {
+		next := symbols[pos].next
+		if next < 0 {
+			return
+		}
+		pair := mergePair{left: symbols[pos].text, right: symbols[next].text}
+		if rank, ok := m.ranks[pair]; ok {
+			heap.Push(queue, mergeCandidate{rank: rank, pos: pos, left: pair.left, right: pair.right})
+		}
+	}
+	for i := range symbols {
+		push(i)
+	}
+
+	for queue.Len() > 0 {
+		c := heap.Pop(queue).(mergeCandidate) // nolint:forcetypeassert
+		left := &symbols[c.pos]
+		if left.merged || left.next < 0 || left.text != c.left || symbols[left.next].text != c.right {
+			// The candidate is stale: one of its symbols was merged since.
+			continue
+		}
+		right := &symbols[left.next]
+		left.text += strings.TrimPrefix(right.text, m.prefix)
+		right.merged = true
+		left.next = right.next
+		if right.next >= 0 {
+			symbols[right.next].prev = c.pos
+		}
+		if left.prev >= 0 {
+			push(left.prev)
+		}
+		push(c.pos)
+	}
+
+	unknown := false
+	for i := 0; i >= 0; i = symbols[i].next {
+		if id, ok := m.tokens[symbols[i].text]; ok {
+			ids = append(ids, id)
+			unknown = false
+			continue
+		}
+		if m.byteFallback {
+			if byteIDs, ok := m.byteTokens(symbols[i].text); ok {
+				ids = append(ids, byteIDs...)
+				unknown = false
+				continue
+			}
+		}
+		if m.unknownToken >= 0 && !(m.fuseUnknown && unknown) {
+			ids = append(ids, m.unknownToken)
+		}
+		unknown = true
+	}
+	return ids
+}
+
+// byteTokens returns the ids of the <0xXX> tokens of the bytes of the text.
+func (m *bpe) byteTokens(text string) ([]int, bool) {
+	ids := make([]int, 0, len(text))
+	for i := 0; i < len(text); i++ {
+		id, ok := m.tokens[fmt.Sprintf("<0x%02X>", text[i])]
+		if !ok {
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
@@ -0,0 +1,166 @@
+package hftokenizer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/dlclark/regexp2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalizer normalizes a text before it is pre-tokenized.
+type normalizer func(text string) string
+
+func identity(text string) string { return text }
+
+func newNormalizer(c *component) (normalizer, error) { //nolint:cyclop
+	if c == nil {
+		return identity, nil
+	}
+	switch c.Type {
+	case "Sequence":
+		normalizers := make([]normalizer, 0, len(c.Normalizers))
+		for _, sub := range c.Normalizers {
+			n, err := newNormalizer(sub)
+			if err != nil {
+				return nil, err
+			}
+			normalizers = append(normalizers, n)
+		}
+		return func(text string) string {
+			for _, n := range normalizers {
+				text = n(text)
+			}
+			return text
+		}, nil
+	case "NFC":
+		return norm.NFC.String, nil
+	case "NFD":
+		return norm.NFD.String, nil
+	case "NFKC", "Precompiled":
+		// Precompiled normalizers are SentencePiece character maps, which are
+		// mostly NFKC.
+		return norm.NFKC.String, nil
+	case "NFKD":
+		return norm.NFKD.String, nil
+	case "Lowercase":
+		return strings.ToLower, nil
+	case "StripAccents":
+		return stripAccents, nil
+	case "Strip":
+		return func(text string) string {
+			if c.StripLeft {
+				text = strings.TrimLeftFunc(text, unicode.IsSpace)
+			}
+			if c.StripRight {
+				text = strings.TrimRightFunc(text, unicode.IsSpace)
+			}
+			return text
+		}, nil
+	case "Prepend":
+		return func(text string) string {
+			if text == "" {
+				return text
+			}
+			return c.Prepend + text
+		}, nil
+	case "Replace":
+		return newReplace(c)
+	case "BertNormalizer":
+		return bertNormalizer(c), nil
+	}
+	return nil, fmt.Errorf("%w: normalizer %q", ErrUnsupported, c.Type)
+}
+
+// newReplace returns a function replacing the pattern of the component by its
+// content.
+func newReplace(c *component) (func(string) string, error) {
+	if c.Pattern == nil {
+		return nil, fmt.Errorf("%w: Replace without pattern", ErrUnsupported)
+	}
+	if c.Pattern.String != nil {
+		old := *c.Pattern.String
+		return func(text string) string { return strings.ReplaceAll(text, old, c.Content) }, nil
+	}
+	if c.Pattern.Regex == nil {
+		return nil, fmt.Errorf("%w: Replace without pattern", ErrUnsupported)
+	}
+	re, err := regexp2.Compile(*c.Pattern.Regex, regexp2.None)
+	if err != nil {
+		return nil, fmt.Errorf("compile pattern: %w", err)
+	}
+	return func(text string) string {
+		replaced, err := re.Replace(text, c.Content, -1, -1)
+		if err != nil {
+			return text
+		}
+		return replaced
+	}, nil
+}
+
+// stripAccents removes the combining marks of the text.
+func stripAccents(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+func bertNormalizer(c *component) normalizer {
+	lowercase := boolOr(c.Lowercase, true)
+	strip := boolOr(c.StripAccents, lowercase)
+	return func(text string) string {
+		if c.CleanText {
+			text = strings.Map(func(r rune) rune {
+				switch {
+				case r == 0 || r == unicode.ReplacementChar || isControl(r):
+					return -1
+				case unicode.IsSpace(r):
+					return ' '
+				}
+				return r
+			}, text)
+		}
+		if c.HandleChineseChars {
+			var b strings.Builder
+			for _, r := range text {
+				if isChineseChar(r) {
+					b.WriteRune(' ')
+					b.WriteRune(r)
+					b.WriteRune(' ')
+					continue
+				}
+				b.WriteRune(r)
+			}
+			text = b.String()
+		}
+		if strip {
+			text = stripAccents(norm.NFD.String(text))
+		}
+		if lowercase {
+			text = strings.ToLower(text)
+		}
+		return text
+	}
+}
+
+func isControl(r rune) bool {
+	if r == '\t' || r == '\n' || r == '\r' {
+		return false
+	}
+	return unicode.In(r, unicode.Cc, unicode.Cf, unicode.Co, unicode.Cs)
+}
+
+func isChineseChar(r rune) bool {
+	return (r >= 0x4E00 && r <= 0x9FFF) ||
+		(r >= 0x3400 && r <= 0x4DBF) ||
+		(r >= 0x20000 && r <= 0x2A6DF) ||
+		(r >= 0x2A700 && r <= 0x2B73F) ||
+		(r >= 0x2B740 && r <= 0x2B81F) ||
+		(r >= 0x2B820 && r <= 0x2CEAF) ||
+		(r >= 0xF900 && r <= 0xFAFF) ||
+		(r >= 0x2F800 && r <= 0x2FA1F)
+}
@@ -0,0 +1,317 @@
+package hftokenizer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/dlclark/regexp2"
+)
+
+const (
+	// _gpt2Pattern is the pattern splitting the text of byte level
+	// pre-tokenizers.
+	_gpt2Pattern = `'s|'t|'re|'ve|'m|'ll|'d| ?\p{L}+| ?\p{N}+| ?[^\s\p{L}\p{N}]+|\s+(?!\S)|\s+`
+	// _whitespacePattern is the pattern of the Whitespace pre-tokenizer.
+	_whitespacePattern = `\w+|[^\w\s]+`
+
+	_defaultMetaspace = "▁"
+)
+
+// preTokenizer splits the pieces of a normalized text in smaller pieces,
+// which are tokenized independently. First is whether the first piece is at
+// the start of the text.
+type preTokenizer func(pieces []string, first bool) []string
+
+// splitBehavior is what to do with the delimiters of a split.
+type splitBehavior string
+
+const (
+	splitRemoved            splitBehavior = "Removed"
+	splitIsolated           splitBehavior = "Isolated"
+	splitMergedWithPrevious splitBehavior = "MergedWithPrevious"
+	splitMergedWithNext     splitBehavior = "MergedWithNext"
+	splitContiguous         splitBehavior = "Contiguous"
+)
+
+// matcher returns the byte ranges of the delimiters of a text.
+type matcher func(text string) [][2]int
+
+func newPreTokenizer(c *component) (preTokenizer, error) { //nolint:cyclop,funlen
+	if c == nil {
+		return func(pieces []string, _ bool) []string { return pieces }, nil
+	}
+	switch c.Type {
+	case "Sequence":
+		preTokenizers := make([]preTokenizer, 0, len(c.Pretokenizers))
+		for _, sub := range c.Pretokenizers {
+			p, err := newPreTokenizer(sub)
+			if err != nil {
+				return nil, err
+			}
+			preTokenizers = append(preTokenizers, p)
+		}
+		return func(pieces []string, first bool) []string {
+			for _, p := range preTokenizers {
+				pieces = p(pieces, first)
+			}
+			return pieces
+		}, nil
+	case "ByteLevel":
+		return byteLevelPreTokenizer(c), nil
+	case "Metaspace":
+		return metaspacePreTokenizer(c), nil
+	case "Whitespace":
+		// The pieces are the matches of the pattern, without the whitespace.
+		return splitter(regexpMatcher(regexp2.MustCompile(_whitespacePattern, regexp2.None)), splitRemoved, true), nil
+	case "WhitespaceSplit":
+		return func(pieces []string, _ bool) []string {
+			var split []string
+			for _, piece := range pieces {
+				split = append(split, strings.Fields(piece)...)
+			}
+			return split
+		}, nil
+	case "BertPreTokenizer":
+		whitespace := splitter(runeMatcher(unicode.IsSpace), splitRemoved, false)
+		punctuation := splitter(runeMatcher(isPunctuation), splitIsolated, false)
+		return func(pieces []string, first bool) []string {
+			return punctuation(whitespace(pieces, first), first)
+		}, nil
+	case "Punctuation":
+		return splitter(runeMatcher(isPunctuation), behaviorOr(c.Behavior, splitIsolated), false), nil
+	case "Digits":
+		if c.IndividualDigits {
+			return splitter(runeMatcher(unicode.IsDigit), splitIsolated, false), nil
+		}
+		return splitter(runeMatcher(unicode.IsDigit), splitContiguous, false), nil
+	case "Split":
+		m, err := patternMatcher(c)
+		if err != nil {
+			return nil, err
+		}
+		return splitter(m, behaviorOr(c.Behavior, splitIsolated), c.Invert), nil
+	}
+	return nil, fmt.Errorf("%w: pre-tokenizer %q", ErrUnsupported, c.Type)
+}
+
+func behaviorOr(behavior string, def splitBehavior) splitBehavior {
+	if behavior == "" {
+		return def
+	}
+	return splitBehavior(behavior)
+}
+
+func patternMatcher(c *component) (matcher, error) {
+	if c.Pattern == nil {
+		return nil, fmt.Errorf("%w: Split without pattern", ErrUnsupported)
+	}
+	if c.Pattern.String != nil {
+		return stringMatcher(*c.Pattern.String), nil
+	}
+	if c.Pattern.Regex == nil {
+		return nil, fmt.Errorf("%w: Split without pattern", ErrUnsupported)
+	}
+	re, err := regexp2.Compile(*c.Pattern.Regex, regexp2.None)
+	if err != nil {
+		return nil, fmt.Errorf("compile pattern: %w", err)
+	}
+	return regexpMatcher(re), nil
+}
+
+// stringMatcher matches the occurrences of the delimiter.
+func stringMatcher(delimiter string) matcher {
+	return func(text string) [][2]int {
+		if delimiter == "" {
+			return nil
+		}
+		var matches [][2]int
+		for start := 0; ; {
+			i := strings.Index(text[start:], delimiter)
+			if i < 0 {
+				return matches
+			}
+			matches = append(matches, [2]int{start + i, start + i + len(delimiter)})
+			start += i + len(delimiter)
+		}
+	}
+}
+
+// regexpMatcher matches the text with a regexp supporting lookarounds.
+func regexpMatcher(re *regexp2.Regexp) matcher {
+	return func(text string) [][2]int {
+		runes := []rune(text)
+		// The matches are indexed by rune, convert them to byte offsets.
+		offsets := make([]int, len(runes)+1)
+		for i, r := range runes {
+			offsets[i+1] = offsets[i] + len(string(r))
+		}
+		var matches [][2]int
+		m, err := re.FindRunesMatch(runes)
+		for err == nil && m != nil {
+			if m.Length > 0 {
+				matches = append(matches, [2]int{offsets[m.Index], offsets[m.Index+m.Length]})
+			}
+			m, err = re.FindNextMatch(m)
+		}
+		return matches
+	}
+}
+
+// runeMatcher matches each rune of the text for which it returns true.
+func runeMatcher(is func(r rune) bool) matcher {
+	return func(text string) [][2]int {
+		var matches [][2]int
+		for i, r := range text {
+			if is(r) {
+				matches = append(matches, [2]int{i, i + len(string(r))})
+			}
+		}
+		return matches
+	}
+}
+
+// splitter splits the pieces on the delimiters matched, or on what is not
+// matched if invert is true.
+func splitter(match matcher, behavior splitBehavior, invert bool) preTokenizer {
+	return func(pieces []string, _ bool) []string {
+		split := make([]string, 0, len(pieces))
+		for _, piece := range pieces {
+			split = append(split, splitPiece(piece, match(piece), behavior, invert)...)
+		}
+		return split
+	}
+}
+
+type span struct {
+	text      string
+	delimiter bool
+}
+
+func splitPiece(text string, matches [][2]int, behavior splitBehavior, invert bool) []string { //nolint:cyclop
+	spans := make([]span, 0, 2*len(matches)+1)
+	start := 0
+	for _, m := range matches {
+		if m[0] > start {
+			spans = append(spans, span{text: text[start:m[0]], delimiter: invert})
+		}
+		spans = append(spans, span{text: text[m[0]:m[1]], delimiter: !invert})
+		start = m[1]
+	}
+	if start < len(text) {
+		spans = append(spans, span{text: text[start:], delimiter: invert})
+	}
+
+	var split []string
+	switch behavior {
+	case splitRemoved:
+		for _, s := range spans {
+			if !s.delimiter {
+				split = append(split, s.text)
+			}
+		}
+	case splitMergedWithPrevious:
+		for i, s := range spans {
+			if s.delimiter && i > 0 && !spans[i-1].delimiter {
+				split[len(split)-1] += s.text
+				continue
+			}
+			split = append(split, s.text)
+		}
+	case splitMergedWithNext:
+		pending := ""
+		for _, s := range spans {
+			if s.delimiter {
+				if pending != "" {
+					split = append(split, pending)
+				}
+				pending = s.text
+				continue
+			}
+			split = append(split, pending+s.text)
+			pending = ""
+		}
+		if pending != "" {
+			split = append(split, pending)
+		}
+	case splitContiguous:
+		for i, s := range spans {
+			if s.delimiter && i > 0 && spans[i-1].delimiter {
+				split[len(split)-1] += s.text
+				continue
+			}
+			split = append(split, s.text)
+		}
+	default:
+		for _, s := range spans {
+			split = append(split, s.text)
+		}
+	}
+	return split
+}
+
+func byteLevelPreTokenizer(c *component) preTokenizer {
+	addPrefixSpace := boolOr(c.AddPrefixSpace, true)
+	split := func(pieces []string, _ bool) []string { return pieces }
+	if boolOr(c.UseRegex, true) {
+		split = splitter(regexpMatcher(regexp2.MustCompile(_gpt2Pattern, regexp2.None)), splitIsolated, false)
+	}
+	return func(pieces []string, first bool) []string {
+		if addPrefixSpace {
+			prefixed := make([]string, len(pieces))
+			for i, piece := range pieces {
+				if !strings.HasPrefix(piece, " ") {
+					piece = " " + piece
+				}
+				prefixed[i] = piece
+			}
+			pieces = prefixed
+		}
+		pieces = split(pieces, first)
+		for i, piece := range pieces {
+			pieces[i] = bytesToChars(piece)
+		}
+		return pieces
+	}
+}
+
+func metaspacePreTokenizer(c *component) preTokenizer {
+	replacement := c.Replacement
+	if replacement == "" {
+		replacement = _defaultMetaspace
+	}
+	scheme := c.PrependScheme
+	if scheme == "" {
+		scheme = "never"
+		if boolOr(c.AddPrefixSpace, true) {
+			scheme = "always"
+		}
+	}
+	split := boolOr(c.Split, true)
+	delimiters := stringMatcher(replacement)
+	return func(pieces []string, first bool) []string {
+		var result []string
+		for i, piece := range pieces {
+			piece = strings.ReplaceAll(piece, " ", replacement)
+			prepend := scheme == "always" || (scheme == "first" && first && i == 0)
+			if prepend && !strings.HasPrefix(piece, replacement) {
+				piece = replacement + piece
+			}
+			if !split {
+				result = append(result, piece)
+				continue
+			}
+			result = append(result, splitPiece(piece, delimiters(piece), splitMergedWithNext, false)...)
+		}
+		return result
+	}
+}
+
+// isPunctuation reports whether the rune is a punctuation, including all the
+// non alphanumeric ASCII characters as BERT does.
+func isPunctuation(r rune) bool {
+	if (r >= 33 && r <= 47) || (r >= 58 && r <= 64) || (r >= 91 && r <= 96) || (r >= 123 && r <= 126) {
+		return true
+	}
+	return unicode.IsPunct(r)
+}
@@ -0,0 +1,221 @@
+package hftokenizer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// ErrUnsupported is returned when a tokenizer.json uses a component that is
+// not supported.
+var ErrUnsupported = errors.New("unsupported tokenizer component")
+
+// Tokenizer is a tokenizer loaded from a HuggingFace tokenizer.json file.
+// It implements llms.Tokenizer.
+//
+// The tokens added by the post processor, such as the beginning of sequence
+// token, are not part of the encoded tokens.
+type Tokenizer struct {
+	normalizer   normalizer
+	preTokenizer preTokenizer
+	model        model
+	decoder      decoder
+
+	addedTokens  map[string]int
+	addedPattern *regexp.Regexp
+	idToAdded    map[int]string
+}
+
+var _ llms.Tokenizer = (*Tokenizer)(nil)
+
+// config is the content of a tokenizer.json file.
+type config struct {
+	AddedTokens []struct {
+		ID      int    `json:"id"`
+		Content string `json:"content"`
+	} `json:"added_tokens"`
+	Normalizer   *component      `json:"normalizer"`
+	PreTokenizer *component      `json:"pre_tokenizer"`
+	Decoder      *component      `json:"decoder"`
+	Model        json.RawMessage `json:"model"`
+}
+
+// Load reads a tokenizer from the content of a tokenizer.json file. It
+// supports the BPE, WordPiece and WordLevel models.
+func Load(r io.Reader) (*Tokenizer, error) {
+	var c config
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return nil, fmt.Errorf("decode tokenizer: %w", err)
+	}
+
+	t := &Tokenizer{
+		addedTokens: make(map[string]int, len(c.AddedTokens)),
+		idToAdded:   make(map[int]string, len(c.AddedTokens)),
+	}
+	var err error
+	if t.normalizer, err = newNormalizer(c.Normalizer); err != nil {
+		return nil, err
+	}
+	if t.preTokenizer, err = newPreTokenizer(c.PreTokenizer); err != nil {
+		return nil, err
+	}
+	if t.decoder, err = newDecoder(c.Decoder); err != nil {
+		return nil, err
+	}
+	if t.model, err = newModel(c.Model); err != nil {
+		return nil, err
+	}
+
+	contents := make([]string, 0, len(c.AddedTokens))
+	for _, added := range c.AddedTokens {
+		if added.Content == "" {
+			continue
+		}
+		t.addedTokens[added.Content] = added.ID
+		t.idToAdded[added.ID] = added.Content
+		contents = append(contents, added.Content)
+	}
+	if len(contents) > 0 {
+		// Match the longest added tokens first.
+		sort.Slice(contents, func(i, j int) bool { return len(contents[i]) > len(contents[j]) })
+		for i, content := range contents {
+			contents[i] = regexp.QuoteMeta(content)
+		}
+		t.addedPattern = regexp.MustCompile(strings.Join(contents, "|"))
+	}
+	return t, nil
+}
+
+// LoadFile reads a tokenizer from a tokenizer.json file.
+func LoadFile(path string) (*Tokenizer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// RegisterFile loads the tokenizer.json file and registers the tokenizer
+// for the model with llms.RegisterTokenizer.
+func RegisterFile(model, path string) error {
+	t, err := LoadFile(path)
+	if err != nil {
+		return err
+	}
+	llms.RegisterTokenizer(model, t)
+	return nil
+}
+
+// Encode returns the token ids of the text.
+func (t *Tokenizer) Encode(text string) []int {
+	ids := make([]int, 0, len(text)/4)
+	first := true
+	encodeSegment := func(segment string) {
+		if segment == "" {
+			return
+		}
+		segment = t.normalizer(segment)
+		for _, piece := range t.preTokenizer([]string{segment}, first) {
+			if piece != "" {
+				ids = t.model.tokenize(ids, piece)
+			}
+		}
+		first = false
+	}
+
+	if t.addedPattern == nil {
+		encodeSegment(text)
+		return ids
+	}
+	start := 0
+	for _, loc := range t.addedPattern.FindAllStringIndex(text, -1) {
+		encodeSegment(text[start:loc[0]])
+		ids = append(ids, t.addedTokens[text[loc[0]:loc[1]]])
+		start = loc[1]
+		first = false
+	}
+	encodeSegment(text[start:])
+	return ids
+}
+
+// Decode returns the text of the token ids. Unknown ids are skipped.
+func (t *Tokenizer) Decode(ids []int) string {
+	tokens := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if token, ok := t.idToAdded[id]; ok {
+			tokens = append(tokens, token)
+			continue
+		}
+		if token, ok := t.model.idToToken(id); ok {
+			tokens = append(tokens, token)
+		}
+	}
+	return t.decoder(tokens)
+}
+
+// Count returns the number of tokens of the text.
+func (t *Tokenizer) Count(text string) int {
+	return len(t.Encode(text))
+}
+
+// TokenToID returns the id of a token of the vocabulary.
+func (t *Tokenizer) TokenToID(token string) (int, bool) {
+	if id, ok := t.addedTokens[token]; ok {
+		return id, true
+	}
+	return t.model.tokenToID(token)
+}
+
+// component is a normalizer, pre-tokenizer or decoder of a tokenizer.json
+// file. The fields used depend on its type.
+type component struct {
+	Type string `json:"type"`
+
+	Normalizers   []*component `json:"normalizers"`
+	Pretokenizers []*component `json:"pretokenizers"`
+	Decoders      []*component `json:"decoders"`
+
+	Pattern *struct {
+		String *string `json:"String"`
+		Regex  *string `json:"Regex"`
+	} `json:"pattern"`
+	Content string `json:"content"`
+	Prepend string `json:"prepend"`
+
+	Lowercase          *bool `json:"lowercase"`
+	StripAccents       *bool `json:"strip_accents"`
+	CleanText          bool  `json:"clean_text"`
+	HandleChineseChars bool  `json:"handle_chinese_chars"`
+	StripLeft          bool  `json:"strip_left"`
+	StripRight         bool  `json:"strip_right"`
+
+	AddPrefixSpace   *bool  `json:"add_prefix_space"`
+	UseRegex         *bool  `json:"use_regex"`
+	Replacement      string `json:"replacement"`
+	PrependScheme    string `json:"prepend_scheme"`
+	Split            *bool  `json:"split"`
+	Behavior         string `json:"behavior"`
+	Invert           bool   `json:"invert"`
+	IndividualDigits bool   `json:"individual_digits"`
+
+	Prefix  string `json:"prefix"`
+	Suffix  string `json:"suffix"`
+	Cleanup *bool  `json:"cleanup"`
+	Start   int    `json:"start"`
+	Stop    int    `json:"stop"`
+}
+
+func boolOr(b *bool, def bool) bool {
+	if b == nil {
+		return def
+	}
+	return *b
+}
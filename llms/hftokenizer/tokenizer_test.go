@@ -0,0 +1,85 @@
+package hftokenizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func loadTestTokenizer(t *testing.T, name string) *Tokenizer {
+	t.Helper()
+	tk, err := LoadFile("testdata/" + name)
+	require.NoError(t, err)
+	return tk
+}
+
+// tokens returns the tokens of the encoded text.
+func tokens(t *testing.T, tk *Tokenizer, text string) []string {
+	t.Helper()
+	var result []string
+	for _, id := range tk.Encode(text) {
+		if token, ok := tk.idToAdded[id]; ok {
+			result = append(result, token)
+			continue
+		}
+		token, ok := tk.model.idToToken(id)
+		require.True(t, ok)
+		result = append(result, token)
+	}
+	return result
+}
+
+func TestByteLevelBPE(t *testing.T) {
+	t.Parallel()
+	tk := loadTestTokenizer(t, "bytelevel.json")
+
+	assert.Equal(t, []string{"hello", "Ġworld", "!"}, tokens(t, tk, "hello world!"))
+	// The last space of a run of spaces goes with the next word.
+	assert.Equal(t, []string{"hello", "Ġ", "Ġworld"}, tokens(t, tk, "hello  world"))
+	assert.Equal(t, []string{"hello", "<|endoftext|>", "hello"}, tokens(t, tk, "hello<|endoftext|>hello"))
+
+	for _, text := range []string{"hello world!", "hello  world", "hello<|endoftext|>hello"} {
+		assert.Equal(t, text, tk.Decode(tk.Encode(text)))
+	}
+	assert.Equal(t, 3, tk.Count("hello world!"))
+}
+
+func TestByteFallbackBPE(t *testing.T) {
+	t.Parallel()
+	tk := loadTestTokenizer(t, "bytefallback.json")
+
+	assert.Equal(t, []string{"▁Hi", "▁", "<0xE2>", "<0x9C>", "<0x93>"}, tokens(t, tk, "Hi ✓"))
+	assert.Equal(t, "Hi ✓", tk.Decode(tk.Encode("Hi ✓")))
+	// Unknown characters without byte tokens are fused in one unknown token.
+	assert.Equal(t, []string{"▁", "<unk>", "i"}, tokens(t, tk, "xyi"))
+}
+
+func TestWordPiece(t *testing.T) {
+	t.Parallel()
+	tk := loadTestTokenizer(t, "wordpiece.json")
+
+	text := "Héllo, unaffable World!"
+	assert.Equal(t, []string{"hello", ",", "un", "##aff", "##able", "world", "[UNK]"}, tokens(t, tk, text))
+	assert.Equal(t, "hello, unaffable world [UNK]", tk.Decode(tk.Encode(text)))
+
+	id, ok := tk.TokenToID("[CLS]")
+	assert.True(t, ok)
+	assert.Equal(t, 2, id)
+}
+
+func TestLoadUnsupported(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load(strings.NewReader(`{"model":{"type":"Unigram","vocab":[]}}`))
+	require.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestRegisterFile(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, RegisterFile("test-wordpiece-model", "testdata/wordpiece.json"))
+	assert.Equal(t, 3, llms.CountTokens("test-wordpiece-model-v2", "hello, world"))
+}
@@ -47,9 +47,10 @@ func (o *LLM) Generate(ctx context.Context, prompts []string, options ...llms.Ca
 	for _, opt := range options {
 		opt(opts)
 	}
+	prompt := llms.PromptWithResponseFormat(prompts[0], opts.ResponseFormat)
 	result, err := o.client.RunInference(ctx, &huggingfaceclient.InferenceRequest{
 		Model:             o.client.Model,
-		Prompt:            prompts[0],
+		Prompt:            prompt,
 		Task:              huggingfaceclient.InferenceTaskTextGeneration,
 		Temperature:       opts.Temperature,
 		TopP:              opts.TopP,
@@ -58,27 +59,55 @@ func (o *LLM) Generate(ctx context.Context, prompts []string, options ...llms.Ca
 		MaxLength:         opts.MaxLength,
 		RepetitionPenalty: opts.RepetitionPenalty,
 		Seed:              opts.Seed,
+		LogProbs:          opts.LogProbs,
+		TopLogProbs:       opts.TopLogProbs,
+		StreamingFunc:     opts.StreamingFunc,
 	})
 	if err != nil {
 		return nil, err
 	}
-
-	generations := []*llms.Generation{
-		{Text: result.Text},
+	result.Text, err = llms.ParseResponse(opts.ResponseFormat, result.Text)
+	if err != nil {
+		return nil, err
 	}
 
+	// The inference API does not report the token usage.
+	generations := []*llms.Generation{{
+		Text: result.Text,
+		GenerationInfo: map[string]any{
+			llms.UsageKey: llms.EstimateUsage(o.client.Model, prompt, result.Text),
+		},
+		LogProbs: tokensToLogProbs(result.Tokens),
+	}}
+
 	if o.CallbacksHandler != nil {
-		o.CallbacksHandler.HandleLLMEnd(ctx, llms.LLMResult{Generations: [][]*llms.Generation{generations}})
+		o.CallbacksHandler.HandleLLMEnd(ctx, llms.NewLLMResult(generations))
 	}
 	return generations, nil
 }
 
+// tokensToLogProbs converts the generated tokens of an inference.
+func tokensToLogProbs(tokens []huggingfaceclient.Token) []llms.TokenLogProb {
+	if len(tokens) == 0 {
+		return nil
+	}
+	logProbs := make([]llms.TokenLogProb, 0, len(tokens))
+	for _, token := range tokens {
+		logProb := llms.TokenLogProb{Token: token.Text, LogProb: token.LogProb}
+		for _, top := range token.TopTokens {
+			logProb.TopLogProbs = append(logProb.TopLogProbs, llms.TopLogProb{Token: top.Text, LogProb: top.LogProb})
+		}
+		logProbs = append(logProbs, logProb)
+	}
+	return logProbs
+}
+
 func (o *LLM) GeneratePrompt(ctx context.Context, prompts []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
 	return llms.GeneratePrompt(ctx, o, prompts, options...)
 }
 
 func (o *LLM) GetNumTokens(text string) int {
-	return llms.CountTokens("gpt2", text)
+	return llms.CountTokens(o.client.Model, text)
 }
 
 func New(opts ...Option) (*LLM, error) {
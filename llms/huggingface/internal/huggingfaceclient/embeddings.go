@@ -5,7 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+
+	"github.com/tmc/langchaingo/llms"
 )
 
 type embeddingPayload struct {
@@ -40,9 +43,15 @@ func (c *Client) createEmbedding(ctx context.Context, model string, task string,
 	defer r.Body.Close()
 
 	if r.StatusCode != http.StatusOK {
-		msg := fmt.Sprintf("API returned unexpected status code: %d", r.StatusCode)
-
-		return nil, fmt.Errorf("%s: %s", msg, "unable to create embeddings") // nolint:goerr113
+		// No need to check the error here: if it fails, we'll just return the
+		// status code.
+		b, _ := io.ReadAll(r.Body)
+		return nil, &llms.StatusError{
+			StatusCode: r.StatusCode,
+			Message:    string(b),
+			Header:     r.Header,
+			Err:        ErrUnexpectedStatusCode,
+		}
 	}
 
 	var response [][]float32
@@ -41,10 +41,34 @@ type InferenceRequest struct {
 	MaxLength         int           `json:"max_length,omitempty"`
 	RepetitionPenalty float64       `json:"repetition_penalty,omitempty"`
 	Seed              int           `json:"seed,omitempty"`
+	// LogProbs is whether to return the log probabilities of the generated
+	// tokens. It requires the model to be served by Text Generation Inference.
+	LogProbs bool `json:"logprobs,omitempty"`
+	// TopLogProbs is the number of most likely tokens to return at each
+	// position, along with their log probabilities.
+	TopLogProbs int `json:"top_logprobs,omitempty"`
+
+	// StreamingFunc is a function to be called for each chunk of a streaming response.
+	// Return an error to stop streaming early. Streaming requires the model to
+	// be served by Text Generation Inference.
+	StreamingFunc func(ctx context.Context, chunk []byte) error `json:"-"`
 }
 
 type InferenceResponse struct {
 	Text string `json:"generated_text"`
+	// Tokens are the generated tokens with their log probabilities, if
+	// requested. Special tokens are left out.
+	Tokens []Token `json:"tokens,omitempty"`
+}
+
+// Token is a generated token.
+type Token struct {
+	Text    string  `json:"text"`
+	LogProb float64 `json:"logprob"`
+	Special bool    `json:"special"`
+	// TopTokens are the most likely tokens at the position of the token, if
+	// requested.
+	TopTokens []Token `json:"top_tokens,omitempty"`
 }
 
 func (c *Client) RunInference(ctx context.Context, request *InferenceRequest) (*InferenceResponse, error) {
@@ -59,9 +83,17 @@ func (c *Client) RunInference(ctx context.Context, request *InferenceRequest) (*
 			MaxLength:         request.MaxLength,
 			RepetitionPenalty: request.RepetitionPenalty,
 			Seed:              request.Seed,
+			Details:           request.LogProbs || request.TopLogProbs > 0,
+			TopNTokens:        request.TopLogProbs,
 		},
 	}
-	resp, err := c.runInference(ctx, payload)
+	var resp inferenceResponsePayload
+	var err error
+	if request.StreamingFunc != nil {
+		resp, err = c.runStreamingInference(ctx, payload, request.StreamingFunc)
+	} else {
+		resp, err = c.runInference(ctx, payload)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to run inference: %w", err)
 	}
@@ -71,9 +103,13 @@ func (c *Client) RunInference(ctx context.Context, request *InferenceRequest) (*
 	text := resp[0].Text
 	// TODO: Add response cleaning based on Model.
 	// e.g., for gpt2, text = text[len(request.Prompt)+1:]
-	return &InferenceResponse{
+	response := &InferenceResponse{
 		Text: text,
-	}, nil
+	}
+	if payload.Parameters.Details && resp[0].Details != nil {
+		response.Tokens = resp[0].Details.tokens()
+	}
+	return response, nil
 }
 
 // EmbeddingRequest is a request to create an embedding.
@@ -3,6 +3,7 @@ package huggingfaceclient
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
 )
 
 const (
@@ -52,6 +54,57 @@ func TestRunInference(t *testing.T) {
 	}
 }
 
+func TestRunInferenceStreaming(t *testing.T) {
+	t.Parallel()
+
+	server := mockServer(t)
+	t.Cleanup(server.Close)
+
+	client, err := New("token", "model")
+	require.NoError(t, err)
+	client.url = server.URL
+
+	var chunks []string
+	resp, err := client.RunInference(context.TODO(), &InferenceRequest{
+		StreamingFunc: func(_ context.Context, chunk []byte) error {
+			chunks = append(chunks, string(chunk))
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &InferenceResponse{Text: "I hug, you hug"}, resp)
+	assert.Equal(t, []string{"I hug,", " you hug"}, chunks)
+
+	errStop := errors.New("stop")
+	_, err = client.RunInference(context.TODO(), &InferenceRequest{
+		StreamingFunc: func(context.Context, []byte) error {
+			return errStop
+		},
+	})
+	require.ErrorIs(t, err, errStop)
+}
+
+func TestRunInferenceLogProbs(t *testing.T) {
+	t.Parallel()
+
+	server := mockServer(t)
+	t.Cleanup(server.Close)
+
+	client, err := New("token", "model")
+	require.NoError(t, err)
+	client.url = server.URL
+
+	resp, err := client.RunInference(context.TODO(), &InferenceRequest{TopLogProbs: 1})
+	require.NoError(t, err)
+	assert.Equal(t, &InferenceResponse{
+		Text: "I hug",
+		Tokens: []Token{
+			{Text: "I", LogProb: -0.1, TopTokens: []Token{{Text: "I", LogProb: -0.1}}},
+			{Text: " hug", LogProb: -0.7, TopTokens: []Token{{Text: " hug", LogProb: -0.7}}},
+		},
+	}, resp)
+}
+
 func mockServer(t *testing.T) *httptest.Server {
 	t.Helper()
 
@@ -63,6 +116,18 @@ func mockServer(t *testing.T) *httptest.Server {
 		err = json.Unmarshal(b, &infReq)
 		require.NoError(t, err)
 
+		if infReq.Stream {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`data:{"token":{"text":"I hug,","special":false},"generated_text":null}` + "\n\n"))
+			_, _ = w.Write([]byte(`data:{"token":{"text":" you hug","special":false},"generated_text":null}` + "\n\n"))
+			_, _ = w.Write([]byte(`data:{"token":{"text":"</s>","special":true},"generated_text":"I hug, you hug"}` + "\n\n"))
+			return
+		}
+		if infReq.Parameters.Details {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`[{"generated_text":"I hug","details":{"tokens":[{"text":"I","logprob":-0.1,"special":false},{"text":" hug","logprob":-0.7,"special":false},{"text":"</s>","logprob":-0.01,"special":true}],"top_tokens":[[{"text":"I","logprob":-0.1,"special":false}],[{"text":" hug","logprob":-0.7,"special":false}],[{"text":"</s>","logprob":-0.01,"special":true}]]}}]`)) // nolint:lll
+			return
+		}
 		if infReq.Parameters.TopK == -1 {
 			w.WriteHeader(http.StatusBadRequest)
 			_, _ = w.Write([]byte(fmt.Sprintf(`{"error":["%s"]}`, errMsg)))
@@ -72,3 +137,26 @@ func mockServer(t *testing.T) *httptest.Server {
 		}
 	}))
 }
+
+func TestRunInferenceStatusError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":"Model is currently loading"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := New("token", "model")
+	require.NoError(t, err)
+	client.url = server.URL
+
+	_, err = client.RunInference(context.TODO(), &InferenceRequest{})
+	require.ErrorIs(t, err, ErrUnexpectedStatusCode)
+	var statusErr *llms.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	assert.Equal(t, "1", statusErr.Header.Get("Retry-After"))
+	assert.Equal(t, llms.ErrorClassServer, llms.ClassifyError(err))
+}
@@ -1,6 +1,7 @@
 package huggingfaceclient
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -8,6 +9,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
 )
 
 var ErrUnexpectedStatusCode = errors.New("unexpected status code")
@@ -24,6 +28,7 @@ type inferencePayload struct {
 	Model      string     `json:"-"`
 	Inputs     string     `json:"inputs"`
 	Parameters parameters `json:"parameters,omitempty"`
+	Stream     bool       `json:"stream,omitempty"`
 }
 
 type parameters struct {
@@ -34,16 +39,124 @@ type parameters struct {
 	MaxLength         int     `json:"max_length,omitempty"`
 	RepetitionPenalty float64 `json:"repetition_penalty,omitempty"`
 	Seed              int     `json:"seed,omitempty"`
+	Details           bool    `json:"details,omitempty"`
+	TopNTokens        int     `json:"top_n_tokens,omitempty"`
 }
 
 type (
 	inferenceResponsePayload []inferenceResponse
 	inferenceResponse        struct {
-		Text string `json:"generated_text"`
+		Text    string            `json:"generated_text"`
+		Details *inferenceDetails `json:"details,omitempty"`
+	}
+	// inferenceDetails are the details of a generation of a Text Generation
+	// Inference endpoint, TopTokens holding the most likely tokens at the
+	// position of each token.
+	inferenceDetails struct {
+		Tokens    []Token   `json:"tokens"`
+		TopTokens [][]Token `json:"top_tokens,omitempty"`
 	}
 )
 
+// streamedInferenceResponse is an event of a streamed inference of a Text
+// Generation Inference endpoint. Only the last event has the generated text.
+type streamedInferenceResponse struct {
+	Token         Token   `json:"token"`
+	TopTokens     []Token `json:"top_tokens"`
+	GeneratedText *string `json:"generated_text"`
+	Error         string  `json:"error"`
+}
+
+// tokens returns the tokens that are not special along with their top tokens.
+func (d *inferenceDetails) tokens() []Token {
+	tokens := make([]Token, 0, len(d.Tokens))
+	for i, token := range d.Tokens {
+		if token.Special {
+			continue
+		}
+		if i < len(d.TopTokens) {
+			token.TopTokens = d.TopTokens[i]
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// ErrStream is returned when the stream of an inference reports an error.
+var ErrStream = errors.New("stream error")
+
 func (c *Client) runInference(ctx context.Context, payload *inferencePayload) (inferenceResponsePayload, error) {
+	r, err := c.postInference(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	// debug print the http response with httputil:
+	// resDump, err := httputil.DumpResponse(r, true)
+	// if err != nil {
+	// 	return nil, err
+	// }
+	// fmt.Fprintf(os.Stderr, "%s", resDump)
+
+	var response inferenceResponsePayload
+	err = json.NewDecoder(r.Body).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// runStreamingInference runs the inference with streaming enabled, calling
+// streamingFunc with the text of each generated token.
+func (c *Client) runStreamingInference(
+	ctx context.Context,
+	payload *inferencePayload,
+	streamingFunc func(ctx context.Context, chunk []byte) error,
+) (inferenceResponsePayload, error) {
+	payload.Stream = true
+	r, err := c.postInference(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	text := ""
+	details := &inferenceDetails{}
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		var event streamedInferenceResponse
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &event); err != nil {
+			return nil, fmt.Errorf("failed to decode stream event: %w", err)
+		}
+		if event.Error != "" {
+			return nil, fmt.Errorf("%w: %s", ErrStream, event.Error)
+		}
+		details.Tokens = append(details.Tokens, event.Token)
+		details.TopTokens = append(details.TopTokens, event.TopTokens)
+		if !event.Token.Special {
+			text += event.Token.Text
+			if err := streamingFunc(ctx, []byte(event.Token.Text)); err != nil {
+				return nil, fmt.Errorf("streaming func returned an error: %w", err)
+			}
+		}
+		if event.GeneratedText != nil {
+			return inferenceResponsePayload{{Text: *event.GeneratedText, Details: details}}, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	return inferenceResponsePayload{{Text: text, Details: details}}, nil
+}
+
+// postInference sends the inference request, returning an error if the
+// response does not have a 200 status code.
+func (c *Client) postInference(ctx context.Context, payload *inferencePayload) (*http.Response, error) {
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return nil, err
@@ -68,33 +181,20 @@ func (c *Client) runInference(ctx context.Context, payload *inferencePayload) (i
 	if err != nil {
 		return nil, err
 	}
-	defer r.Body.Close()
 
 	if r.StatusCode != http.StatusOK {
+		defer r.Body.Close()
 		b, err := io.ReadAll(r.Body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read response body: %w", err)
 		}
 
-		if len(b) > 0 {
-			err = fmt.Errorf("%w: %d, body: %s", ErrUnexpectedStatusCode, r.StatusCode, string(b))
-		} else {
-			err = fmt.Errorf("%w: %d", ErrUnexpectedStatusCode, r.StatusCode)
+		return nil, &llms.StatusError{
+			StatusCode: r.StatusCode,
+			Message:    string(b),
+			Header:     r.Header,
+			Err:        ErrUnexpectedStatusCode,
 		}
-		return nil, err
 	}
-
-	// debug print the http response with httputil:
-	// resDump, err := httputil.DumpResponse(r, true)
-	// if err != nil {
-	// 	return nil, err
-	// }
-	// fmt.Fprintf(os.Stderr, "%s", resDump)
-
-	var response inferenceResponsePayload
-	err = json.NewDecoder(r.Body).Decode(&response)
-	if err != nil {
-		return nil, err
-	}
-	return response, nil
+	return r, nil
 }
@@ -34,6 +34,12 @@ type Generation struct {
 	Message *schema.AIChatMessage `json:"message"`
 	// GenerationInfo is the generation info. This can contain vendor-specific information.
 	GenerationInfo map[string]any `json:"generation_info"`
+	// LogProbs are the log probabilities of the generated tokens, if requested
+	// with WithLogProbs and supported by the model.
+	LogProbs []TokenLogProb `json:"logprobs,omitempty"`
+	// Candidates are the other candidates generated for the same input when
+	// more than one was requested with WithN.
+	Candidates []*Generation `json:"candidates,omitempty"`
 }
 
 // LLMResult is the class that contains all relevant information for an LLM Result.
@@ -48,9 +54,7 @@ func GeneratePrompt(ctx context.Context, l LLM, promptValues []schema.PromptValu
 		prompts = append(prompts, promptValue.String())
 	}
 	generations, err := l.Generate(ctx, prompts, options...)
-	return LLMResult{
-		Generations: [][]*Generation{generations},
-	}, err
+	return NewLLMResult(generations), err
 }
 
 func GenerateChatPrompt(ctx context.Context, l ChatLLM, promptValues []schema.PromptValue, options ...CallOption) (LLMResult, error) { //nolint:lll
@@ -59,7 +63,5 @@ func GenerateChatPrompt(ctx context.Context, l ChatLLM, promptValues []schema.Pr
 		messages = append(messages, promptValue.Messages())
 	}
 	generations, err := l.Generate(ctx, messages, options...)
-	return LLMResult{
-		Generations: [][]*Generation{generations},
-	}, err
+	return NewLLMResult(generations), err
 }
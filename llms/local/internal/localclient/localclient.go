@@ -13,17 +13,43 @@ type Client struct {
 	BinPath      string
 	Args         []string
 	GlobalAsArgs bool
+
+	pool *Pool
+}
+
+// Option is an option for the local client.
+type Option func(*Client)
+
+// WithPool makes the client send requests to a pool of long-lived processes
+// instead of running the binary for every request.
+func WithPool(pool *Pool) Option {
+	return func(c *Client) {
+		c.pool = pool
+	}
 }
 
 // New returns a new local client.
-func New(binPath string, globalAsArgs bool, args ...string) (*Client, error) {
+func New(binPath string, globalAsArgs bool, args []string, opts ...Option) (*Client, error) {
 	c := &Client{BinPath: binPath, GlobalAsArgs: globalAsArgs, Args: args}
+	for _, opt := range opts {
+		opt(c)
+	}
 	return c, nil
 }
 
 // CompletionRequest is a request to create a completion.
 type CompletionRequest struct {
 	Prompt string `json:"prompt"`
+	// Args are passed to the binary in addition to the args of the client. They
+	// are ignored when the client uses a pool.
+	Args []string `json:"-"`
+	// Options are sent to the processes of the pool along with the prompt.
+	Options *Options `json:"options,omitempty"`
+
+	// StreamingFunc is a function to be called for each chunk of a streaming
+	// response. It is only called when the client uses a pool.
+	// Return an error to stop streaming early.
+	StreamingFunc func(ctx context.Context, chunk []byte) error `json:"-"`
 }
 
 // Completion is a completion.
@@ -33,8 +59,17 @@ type Completion struct {
 
 // CreateCompletion creates a completion.
 func (c *Client) CreateCompletion(ctx context.Context, r *CompletionRequest) (*Completion, error) {
+	if c.pool != nil {
+		text, err := c.pool.Do(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return &Completion{Text: text}, nil
+	}
+
 	resp, err := c.createCompletion(ctx, &completionPayload{
 		Prompt: r.Prompt,
+		Args:   r.Args,
 	})
 	if err != nil {
 		return nil, err
@@ -43,3 +78,11 @@ func (c *Client) CreateCompletion(ctx context.Context, r *CompletionRequest) (*C
 		Text: resp.Response,
 	}, nil
 }
+
+// Close stops the processes of the pool, if the client uses one.
+func (c *Client) Close() error {
+	if c.pool == nil {
+		return nil
+	}
+	return c.pool.Close()
+}
@@ -0,0 +1,279 @@
+package localclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrPoolClosed is returned when a request is sent to a closed pool.
+	ErrPoolClosed = errors.New("pool closed")
+	// ErrProcessExited is returned when a process of the pool exits while
+	// serving a request. The process is restarted for the next request.
+	ErrProcessExited = errors.New("process exited")
+	// ErrResponse is returned when a process of the pool answers a request
+	// with an error.
+	ErrResponse = errors.New("local LLM error")
+)
+
+// Options are the generation options sent to the processes of a pool.
+type Options struct {
+	Temperature       float64  `json:"temperature,omitempty"`
+	TopP              float64  `json:"top_p,omitempty"`
+	TopK              int      `json:"top_k,omitempty"`
+	MinLength         int      `json:"min_length,omitempty"`
+	MaxLength         int      `json:"max_length,omitempty"`
+	MaxTokens         int      `json:"max_tokens,omitempty"`
+	RepetitionPenalty float64  `json:"repetition_penalty,omitempty"`
+	Seed              int      `json:"seed,omitempty"`
+	Stop              []string `json:"stop,omitempty"`
+}
+
+// poolResponse is a line written by a process of the pool.
+type poolResponse struct {
+	Text  string `json:"text"`
+	Done  bool   `json:"done"`
+	Error string `json:"error"`
+}
+
+// Pool is a pool of long-lived processes of a local LLM binary.
+//
+// The processes speak a line-delimited JSON protocol over stdin and stdout.
+// Each request is written as a single line:
+//
+//	{"prompt": "...", "options": {"temperature": 0.5, ...}}
+//
+// and the process answers with one or more lines, the last one with done set:
+//
+//	{"text": "partial "}
+//	{"text": "output", "done": true}
+//
+// or, if the request failed, with a line holding the error:
+//
+//	{"error": "...", "done": true}
+//
+// A process serves one request at a time. Processes are started on demand and
+// restarted after they exit, or after they are killed because a request timed
+// out or was canceled.
+type Pool struct {
+	binPath string
+	args    []string
+	timeout time.Duration
+
+	idle chan *worker
+
+	mu      sync.Mutex
+	closed  bool
+	workers []*worker
+}
+
+// NewPool returns a new pool of size processes running the binary with the
+// args. If timeout is positive, it bounds the duration of each request.
+func NewPool(binPath string, args []string, size int, timeout time.Duration) *Pool {
+	if size < 1 {
+		size = 1
+	}
+	p := &Pool{
+		binPath: binPath,
+		args:    args,
+		timeout: timeout,
+		idle:    make(chan *worker, size),
+	}
+	for i := 0; i < size; i++ {
+		w := &worker{}
+		p.workers = append(p.workers, w)
+		p.idle <- w
+	}
+	return p
+}
+
+// Do sends the request to an idle process of the pool and returns the text of
+// the response. The chunks of the response are passed to the StreamingFunc of
+// the request as they are read.
+func (p *Pool) Do(ctx context.Context, r *CompletionRequest) (string, error) {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	var w *worker
+	select {
+	case w = <-p.idle:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { p.idle <- w }()
+
+	if err := p.start(w); err != nil {
+		return "", err
+	}
+	text, err := w.do(ctx, r)
+	switch {
+	case err == nil:
+		return text, nil
+	case errors.Is(err, ErrResponse):
+		return "", err
+	default:
+		// The state of the process is unknown, so it is replaced.
+		w.stop()
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", err
+	}
+}
+
+// start starts the process of the worker if it does not run, unless the pool
+// is closed. The pool stays locked while the process starts, so that Close
+// kills it.
+func (p *Pool) start(w *worker) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	return w.ensureStarted(p.binPath, p.args)
+}
+
+// Close stops the processes of the pool.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	for _, w := range p.workers {
+		w.kill()
+	}
+	return nil
+}
+
+type worker struct {
+	mu      sync.Mutex
+	process *os.Process
+	stdin   io.WriteCloser
+	stdout  *json.Decoder
+	exited  chan struct{}
+}
+
+func (w *worker) ensureStarted(binPath string, args []string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.process != nil {
+		select {
+		case <-w.exited:
+			w.process = nil
+		default:
+			return nil
+		}
+	}
+
+	// #nosec G204
+	cmd := exec.Command(binPath, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	// The read end of stdout is not closed by Wait, so that the output
+	// written before the process exits can be read.
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = stdoutW
+	if err := cmd.Start(); err != nil {
+		stdoutR.Close()
+		stdoutW.Close()
+		return fmt.Errorf("start process: %w", err)
+	}
+	stdoutW.Close()
+
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		stdoutR.Close()
+		close(exited)
+	}()
+
+	w.process = cmd.Process
+	w.stdin = stdin
+	w.stdout = json.NewDecoder(bufio.NewReader(stdoutR))
+	w.exited = exited
+	return nil
+}
+
+func (w *worker) do(ctx context.Context, r *CompletionRequest) (string, error) {
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-ctx.Done():
+			// Killing the process unblocks the reads below.
+			w.kill()
+		case <-finished:
+		}
+	}()
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.stdin.Write(append(line, '\n')); err != nil {
+		return "", fmt.Errorf("%w: write request: %w", ErrProcessExited, err)
+	}
+
+	text := ""
+	for {
+		var resp poolResponse
+		if err := w.stdout.Decode(&resp); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return "", fmt.Errorf("%w: read response: %w", ErrProcessExited, err)
+		}
+		if resp.Error != "" {
+			return "", fmt.Errorf("%w: %s", ErrResponse, resp.Error)
+		}
+		if resp.Text != "" {
+			text += resp.Text
+			if r.StreamingFunc != nil {
+				if err := r.StreamingFunc(ctx, []byte(resp.Text)); err != nil {
+					return "", fmt.Errorf("streaming func returned an error: %w", err)
+				}
+			}
+		}
+		if resp.Done {
+			return text, nil
+		}
+	}
+}
+
+// kill kills the process, if it runs.
+func (w *worker) kill() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.process != nil {
+		_ = w.process.Kill()
+	}
+}
+
+// stop kills the process and waits for it to exit, so that the next request
+// starts a new process.
+func (w *worker) stop() {
+	w.kill()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.process != nil {
+		<-w.exited
+		w.process = nil
+	}
+}
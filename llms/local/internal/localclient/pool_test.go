@@ -0,0 +1,170 @@
+package localclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHelperProcess is not a real test. It is the worker process started by
+// the pool tests, which echoes the prompts word by word.
+func TestHelperProcess(*testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	enc := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		var req CompletionRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			_ = enc.Encode(poolResponse{Error: err.Error(), Done: true})
+			continue
+		}
+		switch req.Prompt {
+		case "crash":
+			os.Exit(1)
+		case "hang":
+			time.Sleep(time.Minute)
+		case "fail":
+			_ = enc.Encode(poolResponse{Error: "bad prompt", Done: true})
+			continue
+		}
+		words := strings.Fields(req.Prompt)
+		for i, word := range words {
+			if i > 0 {
+				word = " " + word
+			}
+			_ = enc.Encode(poolResponse{Text: word})
+		}
+		_ = enc.Encode(poolResponse{Text: fmt.Sprintf(" (pid %d)", os.Getpid()), Done: true})
+	}
+	os.Exit(0)
+}
+
+func newTestPool(t *testing.T, size int, timeout time.Duration) *Pool {
+	t.Helper()
+	t.Setenv("GO_WANT_HELPER_PROCESS", "1")
+	pool := NewPool(os.Args[0], []string{"-test.run=TestHelperProcess"}, size, timeout)
+	t.Cleanup(func() { pool.Close() })
+	return pool
+}
+
+func TestPoolStreams(t *testing.T) {
+	pool := newTestPool(t, 1, 0)
+
+	var chunks []string
+	text, err := pool.Do(context.Background(), &CompletionRequest{
+		Prompt: "hello from the pool",
+		StreamingFunc: func(_ context.Context, chunk []byte) error {
+			chunks = append(chunks, string(chunk))
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(text, "hello from the pool (pid "))
+	assert.Equal(t, []string{"hello", " from", " the", " pool"}, chunks[:4])
+
+	// The process is reused for the next request.
+	again, err := pool.Do(context.Background(), &CompletionRequest{Prompt: "hello from the pool"})
+	require.NoError(t, err)
+	assert.Equal(t, text, again)
+}
+
+func TestPoolConcurrentRequests(t *testing.T) {
+	pool := newTestPool(t, 3, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			text, err := pool.Do(context.Background(), &CompletionRequest{Prompt: fmt.Sprintf("prompt %d", i)})
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(text, fmt.Sprintf("prompt %d (pid", i)))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPoolRestartsAfterCrash(t *testing.T) {
+	pool := newTestPool(t, 1, 0)
+
+	first, err := pool.Do(context.Background(), &CompletionRequest{Prompt: "before"})
+	require.NoError(t, err)
+
+	_, err = pool.Do(context.Background(), &CompletionRequest{Prompt: "crash"})
+	require.ErrorIs(t, err, ErrProcessExited)
+
+	second, err := pool.Do(context.Background(), &CompletionRequest{Prompt: "before"})
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second, "the process should have been restarted")
+}
+
+func TestPoolTimeout(t *testing.T) {
+	pool := newTestPool(t, 1, 100*time.Millisecond)
+
+	_, err := pool.Do(context.Background(), &CompletionRequest{Prompt: "hang"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	text, err := pool.Do(context.Background(), &CompletionRequest{Prompt: "after"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(text, "after"))
+}
+
+func TestPoolResponseError(t *testing.T) {
+	pool := newTestPool(t, 1, 0)
+
+	first, err := pool.Do(context.Background(), &CompletionRequest{Prompt: "before"})
+	require.NoError(t, err)
+
+	_, err = pool.Do(context.Background(), &CompletionRequest{Prompt: "fail"})
+	require.ErrorIs(t, err, ErrResponse)
+
+	// The process is kept after an error response.
+	second, err := pool.Do(context.Background(), &CompletionRequest{Prompt: "before"})
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestPoolCloseStopsProcesses(t *testing.T) {
+	pool := newTestPool(t, 4, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = pool.Do(context.Background(), &CompletionRequest{Prompt: "hi"})
+		}()
+	}
+	require.NoError(t, pool.Close())
+	wg.Wait()
+
+	// No process started by a request racing with Close outlives the pool.
+	for _, w := range pool.workers {
+		w.mu.Lock()
+		process, exited := w.process, w.exited
+		w.mu.Unlock()
+		if process == nil {
+			continue
+		}
+		select {
+		case <-exited:
+		case <-time.After(5 * time.Second):
+			t.Fatal("process still running after Close")
+		}
+	}
+
+	_, err := pool.Do(context.Background(), &CompletionRequest{Prompt: "hi"})
+	require.ErrorIs(t, err, ErrPoolClosed)
+}
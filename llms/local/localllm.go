@@ -45,30 +45,47 @@ func (o *LLM) Call(ctx context.Context, prompt string, options ...llms.CallOptio
 	return r[0].Text, nil
 }
 
-func (o *LLM) appendGlobalsToArgs(opts llms.CallOptions) []string {
+// globalsAsArgs returns the global options as CLI arguments. The arguments
+// are built for every call, as the client is shared by concurrent calls.
+func globalsAsArgs(opts llms.CallOptions) []string {
+	var args []string
 	if opts.Temperature != 0 {
-		o.client.Args = append(o.client.Args, fmt.Sprintf("--temperature=%f", opts.Temperature))
+		args = append(args, fmt.Sprintf("--temperature=%f", opts.Temperature))
 	}
 	if opts.TopP != 0 {
-		o.client.Args = append(o.client.Args, fmt.Sprintf("--top_p=%f", opts.TopP))
+		args = append(args, fmt.Sprintf("--top_p=%f", opts.TopP))
 	}
 	if opts.TopK != 0 {
-		o.client.Args = append(o.client.Args, fmt.Sprintf("--top_k=%d", opts.TopK))
+		args = append(args, fmt.Sprintf("--top_k=%d", opts.TopK))
 	}
 	if opts.MinLength != 0 {
-		o.client.Args = append(o.client.Args, fmt.Sprintf("--min_length=%d", opts.MinLength))
+		args = append(args, fmt.Sprintf("--min_length=%d", opts.MinLength))
 	}
 	if opts.MaxLength != 0 {
-		o.client.Args = append(o.client.Args, fmt.Sprintf("--max_length=%d", opts.MaxLength))
+		args = append(args, fmt.Sprintf("--max_length=%d", opts.MaxLength))
 	}
 	if opts.RepetitionPenalty != 0 {
-		o.client.Args = append(o.client.Args, fmt.Sprintf("--repetition_penalty=%f", opts.RepetitionPenalty))
+		args = append(args, fmt.Sprintf("--repetition_penalty=%f", opts.RepetitionPenalty))
 	}
 	if opts.Seed != 0 {
-		o.client.Args = append(o.client.Args, fmt.Sprintf("--seed=%d", opts.Seed))
+		args = append(args, fmt.Sprintf("--seed=%d", opts.Seed))
 	}
 
-	return o.client.Args
+	return args
+}
+
+func makeClientOptions(opts llms.CallOptions) *localclient.Options {
+	return &localclient.Options{
+		Temperature:       opts.Temperature,
+		TopP:              opts.TopP,
+		TopK:              opts.TopK,
+		MinLength:         opts.MinLength,
+		MaxLength:         opts.MaxLength,
+		MaxTokens:         opts.MaxTokens,
+		RepetitionPenalty: opts.RepetitionPenalty,
+		Seed:              opts.Seed,
+		Stop:              opts.StopWords,
+	}
 }
 
 // Generate generates completions using the local LLM binary.
@@ -83,25 +100,40 @@ func (o *LLM) Generate(ctx context.Context, prompts []string, options ...llms.Ca
 	}
 
 	// If o.client.GlobalAsArgs is true
+	var args []string
 	if o.client.GlobalAsArgs {
 		// Then add the option to the args in --key=value format
-		o.appendGlobalsToArgs(*opts)
+		args = globalsAsArgs(*opts)
 	}
 
 	generations := make([]*llms.Generation, 0, len(prompts))
 	for _, prompt := range prompts {
+		prompt = llms.PromptWithResponseFormat(prompt, opts.ResponseFormat)
 		result, err := o.client.CreateCompletion(ctx, &localclient.CompletionRequest{
-			Prompt: prompt,
+			Prompt:        prompt,
+			Args:          args,
+			Options:       makeClientOptions(*opts),
+			StreamingFunc: opts.StreamingFunc,
 		})
 		if err != nil {
 			return nil, err
 		}
+		result.Text, err = llms.ParseResponse(opts.ResponseFormat, result.Text)
+		if err != nil {
+			return nil, err
+		}
 
-		generations = append(generations, &llms.Generation{Text: result.Text})
+		// The local binary does not report the token usage.
+		generations = append(generations, &llms.Generation{
+			Text: result.Text,
+			GenerationInfo: map[string]any{
+				llms.UsageKey: llms.EstimateUsage("", prompt, result.Text),
+			},
+		})
 	}
 
 	if o.CallbacksHandler != nil {
-		o.CallbacksHandler.HandleLLMEnd(ctx, llms.LLMResult{Generations: [][]*llms.Generation{generations}})
+		o.CallbacksHandler.HandleLLMEnd(ctx, llms.NewLLMResult(generations))
 	}
 
 	return generations, nil
@@ -135,8 +167,21 @@ func New(opts ...Option) (*LLM, error) {
 		return nil, errors.Join(ErrMissingBin, err)
 	}
 
-	c, err := localclient.New(path, options.globalAsArgs, strings.Split(options.args, " ")...)
+	args := strings.Fields(options.args)
+	var clientOpts []localclient.Option
+	if options.poolSize > 0 {
+		pool := localclient.NewPool(path, args, options.poolSize, options.requestTimeout)
+		clientOpts = append(clientOpts, localclient.WithPool(pool))
+	}
+
+	c, err := localclient.New(path, options.globalAsArgs, args, clientOpts...)
 	return &LLM{
 		client: c,
 	}, err
 }
+
+// Close stops the processes of the pool, if the LLM was created with
+// WithPoolSize.
+func (o *LLM) Close() error {
+	return o.client.Close()
+}
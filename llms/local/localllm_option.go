@@ -1,5 +1,7 @@
 package local
 
+import "time"
+
 const (
 	// The name of the environment variable that contains the path to the local LLM binary.
 	localLLMBinVarName = "LOCAL_LLM_BIN"
@@ -8,9 +10,11 @@ const (
 )
 
 type options struct {
-	bin          string
-	args         string
-	globalAsArgs bool // build key-value arguments from global llms.Options
+	bin            string
+	args           string
+	globalAsArgs   bool // build key-value arguments from global llms.Options
+	poolSize       int
+	requestTimeout time.Duration
 }
 
 type Option func(*options)
@@ -38,3 +42,22 @@ func WithGlobalAsArgs() Option {
 		opts.globalAsArgs = true
 	}
 }
+
+// WithPoolSize keeps the given number of long-lived processes of the local LLM
+// binary, instead of running the binary for every prompt. The processes read
+// requests as lines of JSON on stdin and write the responses as lines of JSON
+// on stdout; the protocol is described on Pool. The global llms.Options are
+// sent in each request rather than as CLI arguments.
+func WithPoolSize(size int) Option {
+	return func(opts *options) {
+		opts.poolSize = size
+	}
+}
+
+// WithRequestTimeout bounds the duration of each request sent to the pool of
+// processes. A process that times out is killed and restarted.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(opts *options) {
+		opts.requestTimeout = timeout
+	}
+}
@@ -0,0 +1,39 @@
+package local
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestGlobalAsArgsPerCall(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo not found")
+	}
+
+	llm, err := New(WithBin("echo"), WithArgs("-n"), WithGlobalAsArgs())
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := llm.Call(context.Background(), "hi", llms.WithTopK(3))
+			assert.NoError(t, err)
+			assert.Equal(t, "--top_k=3 hi", out)
+		}()
+	}
+	wg.Wait()
+
+	// The args of a call do not leak into the next one.
+	out, err := llm.Call(context.Background(), "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "hi", out)
+}
@@ -0,0 +1,54 @@
+package llms
+
+import "math"
+
+// TokenLogProb is the log probability of a generated token.
+type TokenLogProb struct {
+	// Token is the text of the token.
+	Token string `json:"token"`
+	// LogProb is the log probability of the token.
+	LogProb float64 `json:"logprob"`
+	// TopLogProbs are the most likely tokens at the position of the token, if
+	// requested with WithTopLogProbs.
+	TopLogProbs []TopLogProb `json:"top_logprobs,omitempty"`
+}
+
+// TopLogProb is one of the most likely tokens at a position.
+type TopLogProb struct {
+	// Token is the text of the token.
+	Token string `json:"token"`
+	// LogProb is the log probability of the token.
+	LogProb float64 `json:"logprob"`
+}
+
+// AllCandidates returns the generation followed by its other candidates.
+func (g *Generation) AllCandidates() []*Generation {
+	first := *g
+	first.Candidates = nil
+	return append([]*Generation{&first}, g.Candidates...)
+}
+
+// SumLogProbs returns the sum of the log probabilities of the generated
+// tokens, that is the log probability of the whole text. It returns false if
+// the generation has no log probabilities.
+func (g *Generation) SumLogProbs() (float64, bool) {
+	if len(g.LogProbs) == 0 {
+		return 0, false
+	}
+	var sum float64
+	for _, lp := range g.LogProbs {
+		sum += lp.LogProb
+	}
+	return sum, true
+}
+
+// Perplexity returns the perplexity of the generated tokens, a measure of the
+// confidence of the model in the text: the lower, the more confident. It
+// returns false if the generation has no log probabilities.
+func (g *Generation) Perplexity() (float64, bool) {
+	sum, ok := g.SumLogProbs()
+	if !ok {
+		return 0, false
+	}
+	return math.Exp(-sum / float64(len(g.LogProbs))), true
+}
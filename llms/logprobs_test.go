@@ -0,0 +1,26 @@
+package llms
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerationLogProbs(t *testing.T) {
+	t.Parallel()
+
+	g := &Generation{Text: "a b"}
+	_, ok := g.SumLogProbs()
+	assert.False(t, ok)
+	_, ok = g.Perplexity()
+	assert.False(t, ok)
+
+	g.LogProbs = []TokenLogProb{{Token: "a", LogProb: math.Log(0.5)}, {Token: " b", LogProb: math.Log(0.5)}}
+	sum, ok := g.SumLogProbs()
+	assert.True(t, ok)
+	assert.InDelta(t, math.Log(0.25), sum, 1e-9)
+	perplexity, ok := g.Perplexity()
+	assert.True(t, ok)
+	assert.InDelta(t, 2, perplexity, 1e-9)
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// Chat wraps an llms.ChatLLM with retries and rate limits.
+type Chat struct {
+	chat   llms.ChatLLM
+	runner *runner
+}
+
+var (
+	_ llms.ChatLLM       = (*Chat)(nil)
+	_ llms.LanguageModel = (*Chat)(nil)
+)
+
+// NewChat returns a new Chat that wraps the given chat LLM.
+func NewChat(chat llms.ChatLLM, opts ...Option) *Chat {
+	return &Chat{
+		chat:   chat,
+		runner: newRunner(opts...),
+	}
+}
+
+// Call requests a chat response for the given messages.
+func (c *Chat) Call(ctx context.Context, messages []schema.ChatMessage, options ...llms.CallOption) (*schema.AIChatMessage, error) { // nolint: lll
+	r, err := c.Generate(ctx, [][]schema.ChatMessage{messages}, options...)
+	if err != nil {
+		return nil, err
+	}
+	if len(r) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	return r[0].Message, nil
+}
+
+// Generate requests chat responses from the wrapped chat LLM, retrying on
+// transient errors.
+func (c *Chat) Generate(ctx context.Context, messageSets [][]schema.ChatMessage, options ...llms.CallOption) ([]*llms.Generation, error) { // nolint:lll
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	texts := make([]string, 0, len(messageSets))
+	for _, messages := range messageSets {
+		for _, m := range messages {
+			texts = append(texts, m.GetContent())
+		}
+	}
+	tokens := c.runner.countTokens(numTokensFunc(c.chat), texts...) + opts.MaxTokens*len(messageSets)
+
+	guard := &streamGuard{}
+	// The options are copied, as the caller's slice may have spare capacity.
+	options = append(append([]llms.CallOption{}, options...), guard.wrap)
+
+	var generations []*llms.Generation
+	err := c.runner.do(ctx, len(messageSets), tokens, func(ctx context.Context) error {
+		var err error
+		generations, err = c.chat.Generate(ctx, messageSets, options...)
+		return guard.check(err)
+	})
+	return generations, err
+}
+
+func (c *Chat) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
+	return llms.GenerateChatPrompt(ctx, c, promptValues, options...)
+}
+
+func (c *Chat) GetNumTokens(text string) int {
+	return c.runner.countTokens(numTokensFunc(c.chat), text)
+}
@@ -0,0 +1,9 @@
+// Package middleware provides wrappers that make calls to LLMs, chat LLMs and
+// embedders more resilient.
+//
+// The wrappers retry failed requests with exponential backoff and jitter,
+// honor the Retry-After header of rate limited responses and enforce
+// token bucket limits on the number of requests and tokens sent per minute.
+// Whether an error is retried is decided by llms.ClassifyError, unless a
+// custom function is passed with WithRetryIf.
+package middleware
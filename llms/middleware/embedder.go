@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/tmc/langchaingo/embeddings"
+)
+
+// Embedder wraps an embeddings.Embedder with retries and rate limits.
+type Embedder struct {
+	embedder embeddings.Embedder
+	runner   *runner
+}
+
+var _ embeddings.Embedder = (*Embedder)(nil)
+
+// NewEmbedder returns a new Embedder that wraps the given embedder.
+func NewEmbedder(embedder embeddings.Embedder, opts ...Option) *Embedder {
+	return &Embedder{
+		embedder: embedder,
+		runner:   newRunner(opts...),
+	}
+}
+
+// EmbedDocuments creates one vector embedding for each of the texts,
+// retrying on transient errors.
+func (e *Embedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	tokens := e.runner.countTokens(nil, texts...)
+
+	var vectors [][]float32
+	err := e.runner.do(ctx, 1, tokens, func(ctx context.Context) error {
+		var err error
+		vectors, err = e.embedder.EmbedDocuments(ctx, texts)
+		return err
+	})
+	return vectors, err
+}
+
+// EmbedQuery embeds a single text, retrying on transient errors.
+func (e *Embedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	tokens := e.runner.countTokens(nil, text)
+
+	var vector []float32
+	err := e.runner.do(ctx, 1, tokens, func(ctx context.Context) error {
+		var err error
+		vector, err = e.embedder.EmbedQuery(ctx, text)
+		return err
+	})
+	return vector, err
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// LLM wraps an llms.LLM with retries and rate limits.
+type LLM struct {
+	llm    llms.LLM
+	runner *runner
+}
+
+var (
+	_ llms.LLM           = (*LLM)(nil)
+	_ llms.LanguageModel = (*LLM)(nil)
+)
+
+// NewLLM returns a new LLM that wraps the given LLM.
+func NewLLM(llm llms.LLM, opts ...Option) *LLM {
+	return &LLM{
+		llm:    llm,
+		runner: newRunner(opts...),
+	}
+}
+
+// Call requests a completion for the given prompt.
+func (l *LLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	r, err := l.Generate(ctx, []string{prompt}, options...)
+	if err != nil {
+		return "", err
+	}
+	if len(r) == 0 {
+		return "", ErrEmptyResponse
+	}
+	return r[0].Text, nil
+}
+
+// Generate requests completions from the wrapped LLM, retrying on transient errors.
+func (l *LLM) Generate(ctx context.Context, prompts []string, options ...llms.CallOption) ([]*llms.Generation, error) {
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	tokens := l.runner.countTokens(numTokensFunc(l.llm), prompts...) + opts.MaxTokens*len(prompts)
+
+	guard := &streamGuard{}
+	// The options are copied, as the caller's slice may have spare capacity.
+	options = append(append([]llms.CallOption{}, options...), guard.wrap)
+
+	var generations []*llms.Generation
+	err := l.runner.do(ctx, len(prompts), tokens, func(ctx context.Context) error {
+		var err error
+		generations, err = l.llm.Generate(ctx, prompts, options...)
+		return guard.check(err)
+	})
+	return generations, err
+}
+
+func (l *LLM) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
+	return llms.GeneratePrompt(ctx, l, promptValues, options...)
+}
+
+func (l *LLM) GetNumTokens(text string) int {
+	return l.runner.countTokens(numTokensFunc(l.llm), text)
+}
+
+// numTokensFunc returns GetNumTokens of the model if it is a language model.
+func numTokensFunc(model any) func(string) int {
+	if lm, ok := model.(llms.LanguageModel); ok {
+		return lm.GetNumTokens
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/fake"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// failingResponder fails with the given errors before succeeding.
+func failingResponder(calls *int32, errs ...error) func(context.Context, string) (string, error) {
+	return func(_ context.Context, prompt string) (string, error) {
+		n := atomic.AddInt32(calls, 1)
+		if int(n) <= len(errs) {
+			return "", errs[n-1]
+		}
+		return "ok: " + prompt, nil
+	}
+}
+
+func TestLLMRetries(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	inner := fake.New(fake.WithResponseFunc(failingResponder(&calls,
+		&llms.StatusError{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"0"}}},
+		&llms.StatusError{StatusCode: http.StatusBadGateway},
+	)))
+	llm := NewLLM(inner, WithBackoff(time.Millisecond, 5*time.Millisecond), WithTokenCounter(func(string) int { return 1 }))
+
+	out, err := llm.Call(context.Background(), "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "ok: hi", out)
+	assert.Equal(t, int32(3), calls)
+}
+
+func TestLLMTerminalError(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	inner := fake.New(fake.WithResponseFunc(failingResponder(&calls,
+		&llms.StatusError{StatusCode: http.StatusUnauthorized},
+	)))
+	llm := NewLLM(inner, WithBackoff(time.Millisecond, time.Millisecond), WithTokenCounter(func(string) int { return 1 }))
+
+	_, err := llm.Call(context.Background(), "hi")
+	require.Error(t, err)
+	assert.Equal(t, llms.ErrorClassClient, llms.ClassifyError(err))
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestChatRetriesExhausted(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	serverErr := &llms.StatusError{StatusCode: http.StatusInternalServerError}
+	inner := fake.NewChat(fake.WithResponseFunc(failingResponder(&calls, serverErr, serverErr, serverErr)))
+	chat := NewChat(inner, WithMaxRetries(1), WithBackoff(time.Millisecond, time.Millisecond))
+
+	_, err := chat.Call(context.Background(), []schema.ChatMessage{schema.HumanChatMessage{Content: "hi"}})
+	assert.ErrorIs(t, err, ErrRetriesExhausted)
+	assert.ErrorIs(t, err, serverErr)
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestTokenBucket(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	b := newTokenBucket(60)
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	assert.Equal(t, time.Duration(0), b.reserve(60))
+	assert.Equal(t, time.Second, b.reserve(1))
+
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, time.Duration(0), b.reserve(2))
+	// Requests larger than the capacity wait for a full bucket.
+	assert.Equal(t, time.Minute, b.reserve(120))
+}
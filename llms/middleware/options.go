@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+const (
+	_defaultMaxRetries     = 3
+	_defaultInitialBackoff = 500 * time.Millisecond
+	_defaultMaxBackoff     = 30 * time.Second
+	_defaultMultiplier     = 2.0
+	_defaultJitter         = 0.2
+)
+
+type options struct {
+	maxRetries        int
+	initialBackoff    time.Duration
+	maxBackoff        time.Duration
+	multiplier        float64
+	jitter            float64
+	retryIf           func(err error) bool
+	requestsPerMinute int
+	tokensPerMinute   int
+	model             string
+	tokenCounter      func(text string) int
+}
+
+// Option is a function that configures the middleware.
+type Option func(*options)
+
+func defaultOptions() options {
+	return options{
+		maxRetries:     _defaultMaxRetries,
+		initialBackoff: _defaultInitialBackoff,
+		maxBackoff:     _defaultMaxBackoff,
+		multiplier:     _defaultMultiplier,
+		jitter:         _defaultJitter,
+		retryIf: func(err error) bool {
+			return llms.ClassifyError(err).Retryable()
+		},
+	}
+}
+
+// WithMaxRetries sets the maximum number of retries after the first attempt.
+// If not set, the default value 3 is used. Use 0 to disable retries.
+func WithMaxRetries(maxRetries int) Option {
+	return func(o *options) {
+		o.maxRetries = maxRetries
+	}
+}
+
+// WithBackoff sets the delay before the first retry and the upper bound for
+// the delay between retries. The delay is multiplied by the backoff
+// multiplier after every attempt. If not set, the delays are 500ms and 30s.
+func WithBackoff(initial, maxBackoff time.Duration) Option {
+	return func(o *options) {
+		o.initialBackoff = initial
+		o.maxBackoff = maxBackoff
+	}
+}
+
+// WithBackoffMultiplier sets the factor the delay grows by after every
+// attempt. If not set, the default value 2 is used.
+func WithBackoffMultiplier(multiplier float64) Option {
+	return func(o *options) {
+		o.multiplier = multiplier
+	}
+}
+
+// WithJitter sets the fraction, between 0 and 1, by which every delay is
+// randomly shortened or lengthened. If not set, the default value 0.2 is used.
+func WithJitter(jitter float64) Option {
+	return func(o *options) {
+		o.jitter = jitter
+	}
+}
+
+// WithRetryIf sets the function that decides whether an error is retried. If
+// not set, errors are retried when llms.ClassifyError reports a retryable class.
+func WithRetryIf(retryIf func(err error) bool) Option {
+	return func(o *options) {
+		o.retryIf = retryIf
+	}
+}
+
+// WithRequestsPerMinute limits the number of requests sent per minute.
+func WithRequestsPerMinute(requestsPerMinute int) Option {
+	return func(o *options) {
+		o.requestsPerMinute = requestsPerMinute
+	}
+}
+
+// WithTokensPerMinute limits the number of tokens sent per minute. The tokens
+// of a request are estimated from its prompt and the requested max tokens.
+func WithTokensPerMinute(tokensPerMinute int) Option {
+	return func(o *options) {
+		o.tokensPerMinute = tokensPerMinute
+	}
+}
+
+// WithModel sets the model name used to estimate the number of tokens with
+// llms.CountTokens.
+func WithModel(model string) Option {
+	return func(o *options) {
+		o.model = model
+	}
+}
+
+// WithTokenCounter sets the function used to estimate the number of tokens of
+// a text. If not set, the wrapped model's GetNumTokens is used when it
+// implements llms.LanguageModel, and llms.CountTokens otherwise.
+func WithTokenCounter(tokenCounter func(text string) int) Option {
+	return func(o *options) {
+		o.tokenCounter = tokenCounter
+	}
+}
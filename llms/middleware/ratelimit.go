@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a token bucket that refills at a constant rate up to its
+// capacity, which is the number of tokens allowed per minute.
+type tokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+func newTokenBucket(perMinute int) *tokenBucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		capacity: float64(perMinute),
+		tokens:   float64(perMinute),
+		last:     time.Now(),
+		now:      time.Now,
+	}
+}
+
+// wait blocks until n tokens are available and takes them from the bucket.
+// Requests for more tokens than the capacity wait for a full bucket.
+func (b *tokenBucket) wait(ctx context.Context, n int) error {
+	if b == nil || n <= 0 {
+		return nil
+	}
+	for {
+		delay := b.reserve(float64(n))
+		if delay == 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes n tokens if they are available and returns 0, otherwise it
+// returns how long to wait until they are.
+func (b *tokenBucket) reserve(n float64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if n > b.capacity {
+		n = b.capacity
+	}
+	now := b.now()
+	b.tokens += now.Sub(b.last).Minutes() * b.capacity
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	if b.tokens >= n {
+		b.tokens -= n
+		return 0
+	}
+	missing := n - b.tokens
+	return time.Duration(missing / b.capacity * float64(time.Minute))
+}
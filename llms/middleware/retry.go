@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+var (
+	// ErrEmptyResponse is returned when the wrapped model returns no generations.
+	ErrEmptyResponse = errors.New("no response")
+	// ErrRetriesExhausted is returned, wrapping the last error, when a request
+	// still fails after the maximum number of retries.
+	ErrRetriesExhausted = errors.New("retries exhausted")
+)
+
+// runner retries requests and enforces the rate limits shared by all wrappers.
+type runner struct {
+	opts     options
+	requests *tokenBucket
+	tokens   *tokenBucket
+}
+
+func newRunner(opts ...Option) *runner {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &runner{
+		opts:     o,
+		requests: newTokenBucket(o.requestsPerMinute),
+		tokens:   newTokenBucket(o.tokensPerMinute),
+	}
+}
+
+// countTokens estimates the number of tokens of the texts.
+func (r *runner) countTokens(fallback func(string) int, texts ...string) int {
+	counter := r.opts.tokenCounter
+	if counter == nil {
+		counter = fallback
+	}
+	if counter == nil {
+		counter = func(text string) int { return llms.CountTokens(r.opts.model, text) }
+	}
+	total := 0
+	for _, text := range texts {
+		total += counter(text)
+	}
+	return total
+}
+
+// do calls fn until it succeeds, returns an error that is not retryable or the
+// retries are exhausted. Every attempt first waits for the rate limits.
+func (r *runner) do(ctx context.Context, requests, tokens int, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err := r.requests.wait(ctx, requests); err != nil {
+			return err
+		}
+		if err := r.tokens.wait(ctx, tokens); err != nil {
+			return err
+		}
+
+		err = fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if noRetry, ok := err.(errNoRetry); ok { //nolint:errorlint
+			return noRetry.err
+		}
+		if ctx.Err() != nil || !r.opts.retryIf(err) {
+			return err
+		}
+		if attempt >= r.opts.maxRetries {
+			return fmt.Errorf("%w: %w", ErrRetriesExhausted, err)
+		}
+
+		timer := time.NewTimer(r.delay(attempt, err))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// delay returns how long to wait before the given retry. It honors the
+// Retry-After header of a rate limited response.
+func (r *runner) delay(attempt int, err error) time.Duration {
+	if retryAfter, ok := retryAfter(err); ok {
+		return retryAfter
+	}
+
+	backoff := float64(r.opts.initialBackoff) * math.Pow(r.opts.multiplier, float64(attempt))
+	if r.opts.jitter > 0 {
+		backoff *= 1 + r.opts.jitter*(2*rand.Float64()-1) //nolint:gosec
+	}
+	if maxBackoff := float64(r.opts.maxBackoff); maxBackoff > 0 && backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return time.Duration(backoff)
+}
+
+// retryAfter parses the Retry-After header of a *llms.StatusError, which is
+// either a number of seconds or an HTTP date.
+func retryAfter(err error) (time.Duration, bool) {
+	var statusErr *llms.StatusError
+	if !errors.As(err, &statusErr) || statusErr.Header == nil {
+		return 0, false
+	}
+	value := statusErr.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		d := time.Until(date)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// streamGuard wraps a streaming function and records whether any chunk was
+// sent. Once a chunk reached the caller, a retry would repeat the output, so
+// errors are no longer retried.
+type streamGuard struct {
+	streamed atomic.Bool
+}
+
+// wrap is a llms.CallOption that wraps the streaming function of the call.
+func (g *streamGuard) wrap(opts *llms.CallOptions) {
+	streamingFunc := opts.StreamingFunc
+	if streamingFunc == nil {
+		return
+	}
+	opts.StreamingFunc = func(ctx context.Context, chunk []byte) error {
+		g.streamed.Store(true)
+		return streamingFunc(ctx, chunk)
+	}
+}
+
+// check marks err as not retryable if chunks were already streamed.
+func (g *streamGuard) check(err error) error {
+	if err != nil && g.streamed.Load() {
+		return errNoRetry{err: err}
+	}
+	return err
+}
+
+// errNoRetry marks an error that must not be retried.
+type errNoRetry struct{ err error }
+
+func (e errNoRetry) Error() string { return e.err.Error() }
+func (e errNoRetry) Unwrap() error { return e.err }
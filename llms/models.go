@@ -0,0 +1,288 @@
+package llms
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModelFeature is a feature a model supports.
+type ModelFeature string
+
+const (
+	ModelFeatureChat       ModelFeature = "chat"
+	ModelFeatureCompletion ModelFeature = "completion"
+	ModelFeatureStreaming  ModelFeature = "streaming"
+	ModelFeatureFunctions  ModelFeature = "functions"
+	ModelFeatureTools      ModelFeature = "tools"
+	ModelFeatureVision     ModelFeature = "vision"
+	ModelFeatureJSONMode   ModelFeature = "json_mode"
+	ModelFeatureLogProbs   ModelFeature = "logprobs"
+	ModelFeatureEmbeddings ModelFeature = "embeddings"
+)
+
+const _defaultContextSize = 2048
+
+// ModelInfo describes a model.
+type ModelInfo struct {
+	// Name is the name of the model. It also describes the models it is a
+	// prefix of, such as "gpt-4" for "gpt-4-0613", unless they are registered.
+	Name string `json:"name" yaml:"name"`
+	// Provider is the provider of the model, such as "openai".
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty"`
+	// ContextSize is the maximum number of tokens of the prompt and the
+	// completion.
+	ContextSize int `json:"context_size,omitempty" yaml:"context_size,omitempty"`
+	// MaxOutputTokens is the maximum number of tokens of the completion, if
+	// lower than the context size.
+	MaxOutputTokens int `json:"max_output_tokens,omitempty" yaml:"max_output_tokens,omitempty"`
+	// Features are the features the model supports.
+	Features []ModelFeature `json:"features,omitempty" yaml:"features,omitempty"`
+	// InputCostPer1KTokens is the price of 1000 prompt tokens in USD.
+	InputCostPer1KTokens float64 `json:"input_cost_per_1k_tokens,omitempty" yaml:"input_cost_per_1k_tokens,omitempty"` // nolint:lll
+	// OutputCostPer1KTokens is the price of 1000 completion tokens in USD.
+	OutputCostPer1KTokens float64 `json:"output_cost_per_1k_tokens,omitempty" yaml:"output_cost_per_1k_tokens,omitempty"` // nolint:lll
+}
+
+// Supports reports whether the model supports the feature.
+func (m ModelInfo) Supports(feature ModelFeature) bool {
+	for _, f := range m.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// merge returns the info with the fields set in override replaced.
+func (m ModelInfo) merge(override ModelInfo) ModelInfo {
+	if override.Provider != "" {
+		m.Provider = override.Provider
+	}
+	if override.ContextSize != 0 {
+		m.ContextSize = override.ContextSize
+	}
+	if override.MaxOutputTokens != 0 {
+		m.MaxOutputTokens = override.MaxOutputTokens
+	}
+	if override.Features != nil {
+		m.Features = override.Features
+	}
+	if override.InputCostPer1KTokens != 0 {
+		m.InputCostPer1KTokens = override.InputCostPer1KTokens
+	}
+	if override.OutputCostPer1KTokens != 0 {
+		m.OutputCostPer1KTokens = override.OutputCostPer1KTokens
+	}
+	return m
+}
+
+// nolint:gochecknoglobals
+var (
+	openAIChatFeatures = []ModelFeature{
+		ModelFeatureChat, ModelFeatureCompletion, ModelFeatureStreaming,
+		ModelFeatureFunctions, ModelFeatureLogProbs,
+	}
+	openAILatestChatFeatures = []ModelFeature{
+		ModelFeatureChat, ModelFeatureCompletion, ModelFeatureStreaming,
+		ModelFeatureFunctions, ModelFeatureTools, ModelFeatureJSONMode, ModelFeatureLogProbs,
+	}
+	openAIVisionFeatures     = []ModelFeature{ModelFeatureChat, ModelFeatureStreaming, ModelFeatureVision}
+	openAICompletionFeatures = []ModelFeature{ModelFeatureCompletion, ModelFeatureStreaming, ModelFeatureLogProbs}
+	anthropicFeatures        = []ModelFeature{ModelFeatureChat, ModelFeatureCompletion, ModelFeatureStreaming}
+	cohereFeatures           = []ModelFeature{ModelFeatureCompletion, ModelFeatureStreaming, ModelFeatureLogProbs}
+)
+
+// nolint:gochecknoglobals,gomnd
+var defaultModels = []ModelInfo{
+	{
+		Name: "gpt-3.5-turbo", Provider: "openai", ContextSize: 4096,
+		Features: openAIChatFeatures, InputCostPer1KTokens: 0.0015, OutputCostPer1KTokens: 0.002,
+	},
+	{
+		Name: "gpt-3.5-turbo-16k", Provider: "openai", ContextSize: 16385,
+		Features: openAIChatFeatures, InputCostPer1KTokens: 0.003, OutputCostPer1KTokens: 0.004,
+	},
+	{
+		Name: "gpt-3.5-turbo-1106", Provider: "openai", ContextSize: 16385, MaxOutputTokens: 4096,
+		Features: openAILatestChatFeatures, InputCostPer1KTokens: 0.001, OutputCostPer1KTokens: 0.002,
+	},
+	{
+		Name: "gpt-3.5-turbo-instruct", Provider: "openai", ContextSize: 4096,
+		Features: openAICompletionFeatures, InputCostPer1KTokens: 0.0015, OutputCostPer1KTokens: 0.002,
+	},
+	{
+		Name: "gpt-4", Provider: "openai", ContextSize: 8192,
+		Features: openAIChatFeatures, InputCostPer1KTokens: 0.03, OutputCostPer1KTokens: 0.06,
+	},
+	{
+		Name: "gpt-4-32k", Provider: "openai", ContextSize: 32768,
+		Features: openAIChatFeatures, InputCostPer1KTokens: 0.06, OutputCostPer1KTokens: 0.12,
+	},
+	{
+		Name: "gpt-4-1106-preview", Provider: "openai", ContextSize: 128000, MaxOutputTokens: 4096,
+		Features: openAILatestChatFeatures, InputCostPer1KTokens: 0.01, OutputCostPer1KTokens: 0.03,
+	},
+	{
+		Name: "gpt-4-vision-preview", Provider: "openai", ContextSize: 128000, MaxOutputTokens: 4096,
+		Features: openAIVisionFeatures, InputCostPer1KTokens: 0.01, OutputCostPer1KTokens: 0.03,
+	},
+	{
+		Name: "text-davinci-003", Provider: "openai", ContextSize: 4097,
+		Features: openAICompletionFeatures, InputCostPer1KTokens: 0.02, OutputCostPer1KTokens: 0.02,
+	},
+	{
+		Name: "text-curie-001", Provider: "openai", ContextSize: 2048,
+		Features: openAICompletionFeatures, InputCostPer1KTokens: 0.002, OutputCostPer1KTokens: 0.002,
+	},
+	{
+		Name: "text-babbage-001", Provider: "openai", ContextSize: 2048,
+		Features: openAICompletionFeatures, InputCostPer1KTokens: 0.0005, OutputCostPer1KTokens: 0.0005,
+	},
+	{
+		Name: "text-ada-001", Provider: "openai", ContextSize: 2048,
+		Features: openAICompletionFeatures, InputCostPer1KTokens: 0.0004, OutputCostPer1KTokens: 0.0004,
+	},
+	{
+		Name: "code-davinci-002", Provider: "openai", ContextSize: 8000,
+		Features: openAICompletionFeatures,
+	},
+	{
+		Name: "code-cushman-001", Provider: "openai", ContextSize: 2048,
+		Features: openAICompletionFeatures,
+	},
+	{
+		Name: "text-embedding-ada-002", Provider: "openai", ContextSize: 8191,
+		Features: []ModelFeature{ModelFeatureEmbeddings}, InputCostPer1KTokens: 0.0001,
+	},
+	{
+		Name: "claude-2", Provider: "anthropic", ContextSize: 100000, MaxOutputTokens: 4096,
+		Features: anthropicFeatures, InputCostPer1KTokens: 0.008, OutputCostPer1KTokens: 0.024,
+	},
+	{
+		Name: "claude-2.1", Provider: "anthropic", ContextSize: 200000, MaxOutputTokens: 4096,
+		Features: anthropicFeatures, InputCostPer1KTokens: 0.008, OutputCostPer1KTokens: 0.024,
+	},
+	{
+		Name: "claude-instant-1", Provider: "anthropic", ContextSize: 100000, MaxOutputTokens: 4096,
+		Features: anthropicFeatures, InputCostPer1KTokens: 0.0008, OutputCostPer1KTokens: 0.0024,
+	},
+	{
+		Name: "command", Provider: "cohere", ContextSize: 4096,
+		Features: cohereFeatures, InputCostPer1KTokens: 0.001, OutputCostPer1KTokens: 0.002,
+	},
+	{
+		Name: "command-light", Provider: "cohere", ContextSize: 4096,
+		Features: cohereFeatures, InputCostPer1KTokens: 0.0003, OutputCostPer1KTokens: 0.0006,
+	},
+	{
+		Name: "embed-english-v3.0", Provider: "cohere", ContextSize: 512,
+		Features: []ModelFeature{ModelFeatureEmbeddings}, InputCostPer1KTokens: 0.0001,
+	},
+	{
+		Name: "text-bison", Provider: "vertexai", ContextSize: 8192, MaxOutputTokens: 1024,
+		Features: []ModelFeature{ModelFeatureCompletion, ModelFeatureStreaming},
+	},
+	{
+		Name: "chat-bison", Provider: "vertexai", ContextSize: 8192, MaxOutputTokens: 1024,
+		Features: []ModelFeature{ModelFeatureChat, ModelFeatureStreaming, ModelFeatureVision},
+	},
+	{
+		Name: "textembedding-gecko", Provider: "vertexai", ContextSize: 3072,
+		Features: []ModelFeature{ModelFeatureEmbeddings},
+	},
+}
+
+// nolint:gochecknoglobals
+var models = struct {
+	sync.RWMutex
+	m map[string]ModelInfo
+}{m: newDefaultModels()}
+
+func newDefaultModels() map[string]ModelInfo {
+	m := make(map[string]ModelInfo, len(defaultModels))
+	for _, info := range defaultModels {
+		m[info.Name] = info
+	}
+	return m
+}
+
+// RegisterModel registers the info of a model. The fields that are set
+// replace the ones of the model if it is already registered.
+func RegisterModel(info ModelInfo) {
+	models.Lock()
+	defer models.Unlock()
+	merged := models.m[info.Name].merge(info)
+	merged.Name = info.Name
+	models.m[info.Name] = merged
+}
+
+// LookupModel returns the info of the model, or of the longest registered
+// model name it starts with, such as "gpt-4" for "gpt-4-0613".
+func LookupModel(name string) (ModelInfo, bool) {
+	models.RLock()
+	defer models.RUnlock()
+	if info, ok := models.m[name]; ok {
+		return info, true
+	}
+	var (
+		match ModelInfo
+		found bool
+	)
+	for prefix, info := range models.m {
+		if prefix != "" && strings.HasPrefix(name, prefix) && len(prefix) > len(match.Name) {
+			match, found = info, true
+		}
+	}
+	return match, found
+}
+
+// Models returns the info of the registered models, sorted by name.
+func Models() []ModelInfo {
+	models.RLock()
+	defer models.RUnlock()
+	infos := make([]ModelInfo, 0, len(models.m))
+	for _, info := range models.m {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// LoadModels registers the models of a JSON or YAML list of ModelInfo,
+// overriding the fields set of the models already registered.
+func LoadModels(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read models: %w", err)
+	}
+	// JSON is valid YAML.
+	var infos []ModelInfo
+	if err := yaml.Unmarshal(data, &infos); err != nil {
+		return fmt.Errorf("decode models: %w", err)
+	}
+	for i, info := range infos {
+		if info.Name == "" {
+			return fmt.Errorf("decode models: model %d has no name", i) // nolint:goerr113
+		}
+	}
+	for _, info := range infos {
+		RegisterModel(info)
+	}
+	return nil
+}
+
+// LoadModelsFile registers the models of a JSON or YAML file, as LoadModels.
+func LoadModelsFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return LoadModels(f)
+}
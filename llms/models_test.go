@@ -0,0 +1,75 @@
+package llms
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetModelContextSize(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 4096, GetModelContextSize("gpt-3.5-turbo"))
+	assert.Equal(t, 16385, GetModelContextSize("gpt-3.5-turbo-16k"))
+	assert.Equal(t, 16385, GetModelContextSize("gpt-3.5-turbo-16k-0613"))
+	assert.Equal(t, 8192, GetModelContextSize("gpt-4-0613"))
+	assert.Equal(t, 32768, GetModelContextSize("gpt-4-32k-0613"))
+	assert.Equal(t, 100000, GetModelContextSize("claude-2"))
+	assert.Equal(t, 8192, GetModelContextSize("chat-bison@001"))
+	assert.Equal(t, 2048, GetModelContextSize("unknown-model"))
+}
+
+func TestLookupModel(t *testing.T) {
+	t.Parallel()
+
+	info, ok := LookupModel("gpt-4-1106-preview")
+	require.True(t, ok)
+	assert.Equal(t, "openai", info.Provider)
+	assert.True(t, info.Supports(ModelFeatureTools))
+	assert.False(t, info.Supports(ModelFeatureEmbeddings))
+	assert.Equal(t, 4096, info.MaxOutputTokens)
+
+	_, ok = LookupModel("unknown-model")
+	assert.False(t, ok)
+}
+
+func TestLoadModels(t *testing.T) {
+	t.Parallel()
+
+	err := LoadModels(strings.NewReader(`
+- name: test-yaml-model
+  provider: local
+  context_size: 32768
+  max_output_tokens: 100
+  features: [chat, streaming]
+  input_cost_per_1k_tokens: 0.5
+- name: test-yaml-model
+  output_cost_per_1k_tokens: 1.5
+`))
+	require.NoError(t, err)
+	info, ok := LookupModel("test-yaml-model")
+	require.True(t, ok)
+	assert.Equal(t, ModelInfo{
+		Name:                  "test-yaml-model",
+		Provider:              "local",
+		ContextSize:           32768,
+		MaxOutputTokens:       100,
+		Features:              []ModelFeature{ModelFeatureChat, ModelFeatureStreaming},
+		InputCostPer1KTokens:  0.5,
+		OutputCostPer1KTokens: 1.5,
+	}, info)
+
+	err = LoadModels(strings.NewReader(`[{"name": "test-json-model", "context_size": 1000}]`))
+	require.NoError(t, err)
+	assert.Equal(t, 1000, GetModelContextSize("test-json-model"))
+
+	RegisterTokenizer("test-json-model", &wordTokenizer{})
+	assert.Equal(t, 997, CalculateMaxTokens("test-json-model", "one two three"))
+	RegisterTokenizer("test-yaml-model", &wordTokenizer{})
+	assert.Equal(t, 100, CalculateMaxTokens("test-yaml-model", "one two three"))
+
+	err = LoadModels(strings.NewReader(`[{"context_size": 1000}]`))
+	require.Error(t, err)
+}
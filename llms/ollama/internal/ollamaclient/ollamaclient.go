@@ -0,0 +1,290 @@
+package ollamaclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+const (
+	defaultBaseURL = "http://localhost:11434"
+	defaultModel   = "llama2"
+)
+
+// ErrEmptyResponse is returned when the Ollama API returns an empty response.
+var ErrEmptyResponse = errors.New("empty response")
+
+// Client is a client for the Ollama API.
+type Client struct {
+	Model      string
+	baseURL    string
+	httpClient Doer
+}
+
+// Option is an option for the Ollama client.
+type Option func(*Client) error
+
+// Doer performs a HTTP request.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// WithHTTPClient allows setting a custom HTTP client.
+func WithHTTPClient(client Doer) Option {
+	return func(c *Client) error {
+		c.httpClient = client
+
+		return nil
+	}
+}
+
+// New returns a new Ollama client.
+func New(baseURL string, model string, opts ...Option) (*Client, error) {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	if model == "" {
+		model = defaultModel
+	}
+	c := &Client{
+		Model:      model,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Options are the model parameters sent along with a request.
+type Options struct {
+	Temperature      float64  `json:"temperature,omitempty"`
+	TopK             int      `json:"top_k,omitempty"`
+	TopP             float64  `json:"top_p,omitempty"`
+	Seed             int      `json:"seed,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+	NumPredict       int      `json:"num_predict,omitempty"`
+	RepeatPenalty    float64  `json:"repeat_penalty,omitempty"`
+	FrequencyPenalty float64  `json:"frequency_penalty,omitempty"`
+	PresencePenalty  float64  `json:"presence_penalty,omitempty"`
+}
+
+// GenerateRequest is a request to the /api/generate endpoint.
+type GenerateRequest struct {
+	Model   string   `json:"model"`
+	Prompt  string   `json:"prompt"`
+	System  string   `json:"system,omitempty"`
+	Format  string   `json:"format,omitempty"`
+	Stream  bool     `json:"stream"`
+	Options *Options `json:"options,omitempty"`
+
+	// StreamingFunc is a function to be called for each chunk of a streaming response.
+	// Return an error to stop streaming early.
+	StreamingFunc func(ctx context.Context, chunk []byte) error `json:"-"`
+}
+
+// GenerateResponse is a response from the /api/generate endpoint.
+type GenerateResponse struct {
+	Model           string `json:"model"`
+	CreatedAt       string `json:"created_at"`
+	Response        string `json:"response"`
+	Done            bool   `json:"done"`
+	Context         []int  `json:"context,omitempty"`
+	TotalDuration   int64  `json:"total_duration,omitempty"`
+	PromptEvalCount int    `json:"prompt_eval_count,omitempty"`
+	EvalCount       int    `json:"eval_count,omitempty"`
+}
+
+// ChatMessage is a message in a chat request.
+type ChatMessage struct {
+	// The role of the author of this message. One of system, user, or assistant.
+	Role string `json:"role"`
+	// The content of the message.
+	Content string `json:"content"`
+}
+
+// ChatRequest is a request to the /api/chat endpoint.
+type ChatRequest struct {
+	Model    string         `json:"model"`
+	Messages []*ChatMessage `json:"messages"`
+	Format   string         `json:"format,omitempty"`
+	Stream   bool           `json:"stream"`
+	Options  *Options       `json:"options,omitempty"`
+
+	// StreamingFunc is a function to be called for each chunk of a streaming response.
+	// Return an error to stop streaming early.
+	StreamingFunc func(ctx context.Context, chunk []byte) error `json:"-"`
+}
+
+// ChatResponse is a response from the /api/chat endpoint.
+type ChatResponse struct {
+	Model           string       `json:"model"`
+	CreatedAt       string       `json:"created_at"`
+	Message         *ChatMessage `json:"message,omitempty"`
+	Done            bool         `json:"done"`
+	TotalDuration   int64        `json:"total_duration,omitempty"`
+	PromptEvalCount int          `json:"prompt_eval_count,omitempty"`
+	EvalCount       int          `json:"eval_count,omitempty"`
+}
+
+// EmbeddingRequest is a request to the /api/embeddings endpoint.
+type EmbeddingRequest struct {
+	Model   string   `json:"model"`
+	Prompt  string   `json:"prompt"`
+	Options *Options `json:"options,omitempty"`
+}
+
+// EmbeddingResponse is a response from the /api/embeddings endpoint.
+type EmbeddingResponse struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+type errorMessage struct {
+	Error string `json:"error"`
+}
+
+// Generate sends a request to the /api/generate endpoint.
+func (c *Client) Generate(ctx context.Context, r *GenerateRequest) (*GenerateResponse, error) {
+	if r.Model == "" {
+		r.Model = c.Model
+	}
+	r.Stream = r.StreamingFunc != nil
+
+	response := &GenerateResponse{}
+	err := c.stream(ctx, "/api/generate", r, func(data []byte) error {
+		var chunk GenerateResponse
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("parse response: %w", err)
+		}
+		text := response.Response + chunk.Response
+		*response = chunk
+		response.Response = text
+		if r.StreamingFunc != nil && chunk.Response != "" {
+			if err := r.StreamingFunc(ctx, []byte(chunk.Response)); err != nil {
+				return fmt.Errorf("streaming func returned an error: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GenerateChat sends a request to the /api/chat endpoint.
+func (c *Client) GenerateChat(ctx context.Context, r *ChatRequest) (*ChatResponse, error) {
+	if r.Model == "" {
+		r.Model = c.Model
+	}
+	r.Stream = r.StreamingFunc != nil
+
+	response := &ChatResponse{}
+	content := ""
+	err := c.stream(ctx, "/api/chat", r, func(data []byte) error {
+		var chunk ChatResponse
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("parse response: %w", err)
+		}
+		*response = chunk
+		if chunk.Message == nil {
+			return nil
+		}
+		content += chunk.Message.Content
+		if r.StreamingFunc != nil && chunk.Message.Content != "" {
+			if err := r.StreamingFunc(ctx, []byte(chunk.Message.Content)); err != nil {
+				return fmt.Errorf("streaming func returned an error: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response.Message == nil && content == "" {
+		return nil, ErrEmptyResponse
+	}
+	response.Message = &ChatMessage{Role: "assistant", Content: content}
+	return response, nil
+}
+
+// CreateEmbedding sends a request to the /api/embeddings endpoint.
+func (c *Client) CreateEmbedding(ctx context.Context, r *EmbeddingRequest) (*EmbeddingResponse, error) {
+	if r.Model == "" {
+		r.Model = c.Model
+	}
+
+	response := &EmbeddingResponse{}
+	err := c.stream(ctx, "/api/embeddings", r, func(data []byte) error {
+		if err := json.Unmarshal(data, response); err != nil {
+			return fmt.Errorf("parse response: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Embedding) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	return response, nil
+}
+
+// stream posts the payload to the given path and calls fn for every JSON
+// object in the (possibly newline delimited) response body.
+func (c *Client) stream(ctx context.Context, path string, payload any, fn func([]byte) error) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	r, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		// No need to check the error here: if it fails, we'll just return the
+		// status code.
+		var errResp errorMessage
+		_ = json.NewDecoder(r.Body).Decode(&errResp)
+		return &llms.StatusError{StatusCode: r.StatusCode, Message: errResp.Error, Header: r.Header}
+	}
+
+	dec := json.NewDecoder(r.Body)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("parse response: %w", err)
+		}
+		var errResp errorMessage
+		if err := json.Unmarshal(raw, &errResp); err == nil && errResp.Error != "" {
+			return fmt.Errorf("API returned an error: %s", errResp.Error) // nolint:goerr113
+		}
+		if err := fn(raw); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Package ollama provides an LLM and a chat LLM backed by a locally hosted
+// Ollama server. See https://github.com/jmorganca/ollama for details.
+package ollama
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/ollama/internal/ollamaclient"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// ErrEmptyResponse is returned when the Ollama server returns an empty response.
+var ErrEmptyResponse = errors.New("no response")
+
+// LLM is an Ollama LLM implementation using the /api/generate endpoint.
+type LLM struct {
+	CallbacksHandler callbacks.Handler
+	client           *ollamaclient.Client
+	options          options
+}
+
+var (
+	_ llms.LLM           = (*LLM)(nil)
+	_ llms.LanguageModel = (*LLM)(nil)
+)
+
+// New returns a new Ollama LLM.
+func New(opts ...Option) (*LLM, error) {
+	o, c, err := newClient(opts...)
+	return &LLM{
+		client:  c,
+		options: o,
+	}, err
+}
+
+func newClient(opts ...Option) (options, *ollamaclient.Client, error) {
+	o := options{
+		serverURL:  os.Getenv(serverURLEnvVarName),
+		model:      os.Getenv(modelEnvVarName),
+		httpClient: http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	c, err := ollamaclient.New(o.serverURL, o.model, ollamaclient.WithHTTPClient(o.httpClient))
+	return o, c, err
+}
+
+// Call requests a completion for the given prompt.
+func (o *LLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	r, err := o.Generate(ctx, []string{prompt}, options...)
+	if err != nil {
+		return "", err
+	}
+	if len(r) == 0 {
+		return "", ErrEmptyResponse
+	}
+	return r[0].Text, nil
+}
+
+func (o *LLM) Generate(ctx context.Context, prompts []string, options ...llms.CallOption) ([]*llms.Generation, error) {
+	if o.CallbacksHandler != nil {
+		o.CallbacksHandler.HandleLLMStart(ctx, prompts)
+	}
+
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	generations := make([]*llms.Generation, 0, len(prompts))
+	for _, prompt := range prompts {
+		prompt = llms.PromptWithResponseFormat(prompt, opts.ResponseFormat)
+		result, err := o.client.Generate(ctx, &ollamaclient.GenerateRequest{
+			Model:         opts.Model,
+			Prompt:        prompt,
+			System:        o.options.system,
+			Format:        format(o.options, opts),
+			Options:       makeClientOptions(opts),
+			StreamingFunc: opts.StreamingFunc,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Response, err = llms.ParseResponse(opts.ResponseFormat, result.Response)
+		if err != nil {
+			return nil, err
+		}
+		usage := llms.Usage{
+			PromptTokens:     result.PromptEvalCount,
+			CompletionTokens: result.EvalCount,
+		}
+		generations = append(generations, &llms.Generation{
+			Text:           result.Response,
+			GenerationInfo: makeGenerationInfo(usage.OrEstimate(result.Model, prompt, result.Response)),
+		})
+	}
+
+	if o.CallbacksHandler != nil {
+		o.CallbacksHandler.HandleLLMEnd(ctx, llms.NewLLMResult(generations))
+	}
+
+	return generations, nil
+}
+
+func (o *LLM) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
+	return llms.GeneratePrompt(ctx, o, promptValues, options...)
+}
+
+func (o *LLM) GetNumTokens(text string) int {
+	return llms.CountTokens(o.client.Model, text)
+}
+
+// CreateEmbedding creates embeddings for the given input texts with the
+// /api/embeddings endpoint, which embeds one text per request.
+func (o *LLM) CreateEmbedding(ctx context.Context, inputTexts []string) ([][]float32, error) {
+	embeddings := make([][]float32, 0, len(inputTexts))
+	for _, text := range inputTexts {
+		result, err := o.client.CreateEmbedding(ctx, &ollamaclient.EmbeddingRequest{Prompt: text})
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, result.Embedding)
+	}
+	return embeddings, nil
+}
+
+// format returns the format of the response, JSON if the call options ask
+// for a JSON response.
+func format(o options, opts llms.CallOptions) string {
+	if opts.ResponseFormat != nil && opts.ResponseFormat.Type != llms.ResponseFormatTypeText {
+		return "json"
+	}
+	return o.format
+}
+
+func makeClientOptions(opts llms.CallOptions) *ollamaclient.Options {
+	return &ollamaclient.Options{
+		Temperature:      opts.Temperature,
+		TopK:             opts.TopK,
+		TopP:             opts.TopP,
+		Seed:             opts.Seed,
+		Stop:             opts.StopWords,
+		NumPredict:       opts.MaxTokens,
+		RepeatPenalty:    opts.RepetitionPenalty,
+		FrequencyPenalty: opts.FrequencyPenalty,
+		PresencePenalty:  opts.PresencePenalty,
+	}
+}
+
+func makeGenerationInfo(usage llms.Usage) map[string]any {
+	return map[string]any{
+		"PromptTokens":     usage.PromptTokens,
+		"CompletionTokens": usage.CompletionTokens,
+		"TotalTokens":      usage.TotalTokens,
+		llms.UsageKey:      usage,
+	}
+}
@@ -0,0 +1,143 @@
+package ollama
+
+import (
+	"context"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/ollama/internal/ollamaclient"
+	"github.com/tmc/langchaingo/schema"
+)
+
+const (
+	RoleSystem    = "system"
+	RoleAssistant = "assistant"
+	RoleUser      = "user"
+)
+
+// Chat is an Ollama chat LLM implementation using the /api/chat endpoint.
+type Chat struct {
+	CallbacksHandler callbacks.Handler
+	client           *ollamaclient.Client
+	options          options
+}
+
+var (
+	_ llms.ChatLLM       = (*Chat)(nil)
+	_ llms.LanguageModel = (*Chat)(nil)
+)
+
+// NewChat returns a new Ollama chat LLM.
+func NewChat(opts ...Option) (*Chat, error) {
+	o, c, err := newClient(opts...)
+	return &Chat{
+		client:  c,
+		options: o,
+	}, err
+}
+
+// Call requests a chat response for the given messages.
+func (o *Chat) Call(ctx context.Context, messages []schema.ChatMessage, options ...llms.CallOption) (*schema.AIChatMessage, error) { // nolint: lll
+	r, err := o.Generate(ctx, [][]schema.ChatMessage{messages}, options...)
+	if err != nil {
+		return nil, err
+	}
+	if len(r) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	return r[0].Message, nil
+}
+
+// Generate requests a chat response for each of the sets of messages.
+func (o *Chat) Generate(ctx context.Context, messageSets [][]schema.ChatMessage, options ...llms.CallOption) ([]*llms.Generation, error) { // nolint:lll
+	if o.CallbacksHandler != nil {
+		o.CallbacksHandler.HandleLLMStart(ctx, getPromptsFromMessageSets(messageSets))
+	}
+
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	generations := make([]*llms.Generation, 0, len(messageSets))
+	for _, messageSet := range messageSets {
+		messageSet = llms.MessagesWithResponseFormat(messageSet, opts.ResponseFormat)
+		result, err := o.client.GenerateChat(ctx, &ollamaclient.ChatRequest{
+			Model:         opts.Model,
+			Messages:      o.messagesToClientMessages(messageSet),
+			Format:        format(o.options, opts),
+			Options:       makeClientOptions(opts),
+			StreamingFunc: opts.StreamingFunc,
+		})
+		if err != nil {
+			return nil, err
+		}
+		content, err := llms.ParseResponse(opts.ResponseFormat, result.Message.Content)
+		if err != nil {
+			return nil, err
+		}
+		msg := &schema.AIChatMessage{
+			Content: content,
+		}
+		usage := llms.Usage{
+			PromptTokens:     result.PromptEvalCount,
+			CompletionTokens: result.EvalCount,
+		}
+		generations = append(generations, &llms.Generation{
+			Message:        msg,
+			Text:           msg.Content,
+			GenerationInfo: makeGenerationInfo(usage.OrEstimateChat(result.Model, messageSet, msg.Content)),
+		})
+	}
+
+	if o.CallbacksHandler != nil {
+		o.CallbacksHandler.HandleLLMEnd(ctx, llms.NewLLMResult(generations))
+	}
+
+	return generations, nil
+}
+
+func (o *Chat) GetNumTokens(text string) int {
+	return llms.CountTokens(o.client.Model, text)
+}
+
+func (o *Chat) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
+	return llms.GenerateChatPrompt(ctx, o, promptValues, options...)
+}
+
+func getPromptsFromMessageSets(messageSets [][]schema.ChatMessage) []string {
+	prompts := make([]string, 0, len(messageSets))
+	for i := 0; i < len(messageSets); i++ {
+		curPrompt := ""
+		for j := 0; j < len(messageSets[i]); j++ {
+			curPrompt += messageSets[i][j].GetContent()
+		}
+		prompts = append(prompts, curPrompt)
+	}
+
+	return prompts
+}
+
+func (o *Chat) messagesToClientMessages(messages []schema.ChatMessage) []*ollamaclient.ChatMessage {
+	msgs := make([]*ollamaclient.ChatMessage, 0, len(messages)+1)
+	if o.options.system != "" {
+		msgs = append(msgs, &ollamaclient.ChatMessage{Role: RoleSystem, Content: o.options.system})
+	}
+	for _, m := range messages {
+		msg := &ollamaclient.ChatMessage{
+			Content: m.GetContent(),
+		}
+		switch m.GetType() {
+		case schema.ChatMessageTypeSystem:
+			msg.Role = RoleSystem
+		case schema.ChatMessageTypeAI:
+			msg.Role = RoleAssistant
+		case schema.ChatMessageTypeHuman, schema.ChatMessageTypeGeneric, schema.ChatMessageTypeFunction,
+			schema.ChatMessageTypeTool:
+			msg.Role = RoleUser
+		}
+		msgs = append(msgs, msg)
+	}
+
+	return msgs
+}
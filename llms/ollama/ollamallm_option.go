@@ -0,0 +1,59 @@
+package ollama
+
+import "github.com/tmc/langchaingo/llms/ollama/internal/ollamaclient"
+
+const (
+	serverURLEnvVarName = "OLLAMA_SERVER_URL" //nolint:gosec
+	modelEnvVarName     = "OLLAMA_MODEL"      //nolint:gosec
+)
+
+type options struct {
+	serverURL  string
+	model      string
+	format     string
+	system     string
+	httpClient ollamaclient.Doer
+}
+
+type Option func(*options)
+
+// WithServerURL passes the URL of the Ollama server to the client. If not set, the
+// url is read from the OLLAMA_SERVER_URL environment variable. If still not set,
+// then the default value http://localhost:11434 is used.
+func WithServerURL(serverURL string) Option {
+	return func(opts *options) {
+		opts.serverURL = serverURL
+	}
+}
+
+// WithModel passes the Ollama model to the client. If not set, the model
+// is read from the OLLAMA_MODEL environment variable.
+func WithModel(model string) Option {
+	return func(opts *options) {
+		opts.model = model
+	}
+}
+
+// WithFormat sets the format of the response. The only value currently
+// accepted by Ollama is "json".
+func WithFormat(format string) Option {
+	return func(opts *options) {
+		opts.format = format
+	}
+}
+
+// WithSystemPrompt sets the system prompt used by the LLM for every completion.
+// It overrides the one defined in the model's Modelfile.
+func WithSystemPrompt(system string) Option {
+	return func(opts *options) {
+		opts.system = system
+	}
+}
+
+// WithHTTPClient allows setting a custom HTTP client. If not set, the default value
+// is http.DefaultClient.
+func WithHTTPClient(client ollamaclient.Doer) Option {
+	return func(opts *options) {
+		opts.httpClient = client
+	}
+}
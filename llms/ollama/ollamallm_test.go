@@ -0,0 +1,147 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		stream, _ := req["stream"].(bool)
+		options, _ := req["options"].(map[string]any)
+
+		switch r.URL.Path {
+		case "/api/generate":
+			if req["model"] == "missing" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":"model 'missing' not found"}`))
+				return
+			}
+			if req["model"] == "busy" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			if stream {
+				_, _ = w.Write([]byte(`{"response":"Hello"}` + "\n"))
+				_, _ = w.Write([]byte(`{"response":" world"}` + "\n"))
+				_, _ = w.Write([]byte(`{"response":"","done":true,"prompt_eval_count":3,"eval_count":2}` + "\n"))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"model":             req["model"],
+				"response":          "echo: " + req["prompt"].(string),
+				"done":              true,
+				"prompt_eval_count": 3,
+				"eval_count":        options["num_predict"],
+			})
+		case "/api/chat":
+			messages, _ := req["messages"].([]any)
+			last, _ := messages[len(messages)-1].(map[string]any)
+			if stream {
+				_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"Hi"}}` + "\n"))
+				_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":" there"}}` + "\n"))
+				_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":""},"done":true}` + "\n"))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"message": map[string]any{
+					"role":    "assistant",
+					"content": last["role"].(string) + ": " + last["content"].(string),
+				},
+				"done": true,
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestLLMCall(t *testing.T) {
+	t.Parallel()
+	server := newTestServer(t)
+	t.Cleanup(server.Close)
+
+	llm, err := New(WithServerURL(server.URL), WithModel("llama2"))
+	require.NoError(t, err)
+
+	gens, err := llm.Generate(context.Background(), []string{"hi"}, llms.WithMaxTokens(7))
+	require.NoError(t, err)
+	require.Len(t, gens, 1)
+	assert.Equal(t, "echo: hi", gens[0].Text)
+	assert.Equal(t, 10, gens[0].GenerationInfo["TotalTokens"])
+	usage, ok := llms.GetUsage(gens[0])
+	require.True(t, ok)
+	assert.Equal(t, 10, usage.TotalTokens)
+	assert.False(t, usage.Estimated)
+
+	_, err = llm.Call(context.Background(), "hi", llms.WithModel("missing"))
+	assert.ErrorContains(t, err, "model 'missing' not found")
+	var statusErr *llms.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+
+	_, err = llm.Call(context.Background(), "hi", llms.WithModel("busy"))
+	assert.Equal(t, llms.ErrorClassServer, llms.ClassifyError(err))
+
+	// The embeddings endpoint is not served by the test server.
+	_, err = llm.CreateEmbedding(context.Background(), []string{"hi"})
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+}
+
+func TestLLMStreaming(t *testing.T) {
+	t.Parallel()
+	server := newTestServer(t)
+	t.Cleanup(server.Close)
+
+	llm, err := New(WithServerURL(server.URL))
+	require.NoError(t, err)
+
+	var chunks []string
+	out, err := llm.Call(context.Background(), "hi", llms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+		chunks = append(chunks, string(chunk))
+		return nil
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello world", out)
+	assert.Equal(t, []string{"Hello", " world"}, chunks)
+}
+
+func TestChatCall(t *testing.T) {
+	t.Parallel()
+	server := newTestServer(t)
+	t.Cleanup(server.Close)
+
+	chat, err := NewChat(WithServerURL(server.URL))
+	require.NoError(t, err)
+
+	msg, err := chat.Call(context.Background(), []schema.ChatMessage{
+		schema.SystemChatMessage{Content: "be brief"},
+		schema.HumanChatMessage{Content: "hello"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "user: hello", msg.Content)
+
+	var chunks []string
+	msg, err = chat.Call(context.Background(), []schema.ChatMessage{
+		schema.HumanChatMessage{Content: "hello"},
+	}, llms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+		chunks = append(chunks, string(chunk))
+		return nil
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "Hi there", msg.Content)
+	assert.Equal(t, []string{"Hi", " there"}, chunks)
+}
@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"fmt"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Capability is a feature of the OpenAI API that a compatible server may not
+// implement.
+type Capability string
+
+const (
+	// CapabilityChat is the chat completions endpoint, used by Chat.
+	CapabilityChat Capability = "chat"
+	// CapabilityCompletions is the generation of completions for a prompt,
+	// used by LLM.
+	CapabilityCompletions Capability = "completions"
+	// CapabilityEmbeddings is the embeddings endpoint.
+	CapabilityEmbeddings Capability = "embeddings"
+	// CapabilityFunctions is function and tool calling.
+	CapabilityFunctions Capability = "functions"
+	// CapabilityStreaming is the streaming of responses.
+	CapabilityStreaming Capability = "streaming"
+)
+
+// capabilities is the set of declared capabilities. A nil set supports every
+// capability.
+type capabilities map[Capability]bool
+
+func newCapabilities(declared []Capability) capabilities {
+	if len(declared) == 0 {
+		return nil
+	}
+	c := make(capabilities, len(declared))
+	for _, capability := range declared {
+		c[capability] = true
+	}
+	return c
+}
+
+// check returns an error wrapping ErrUnsupportedCapability if a capability is
+// not supported.
+func (c capabilities) check(required ...Capability) error {
+	if c == nil {
+		return nil
+	}
+	for _, capability := range required {
+		if !c[capability] {
+			return fmt.Errorf("%w: %s", ErrUnsupportedCapability, capability)
+		}
+	}
+	return nil
+}
+
+// checkCall checks the capability of the endpoint and the capabilities
+// needed by the call options.
+func (c capabilities) checkCall(endpoint Capability, opts llms.CallOptions) error {
+	required := []Capability{endpoint}
+	if opts.StreamingFunc != nil {
+		required = append(required, CapabilityStreaming)
+	}
+	if len(opts.Functions) > 0 || len(opts.Tools) > 0 {
+		required = append(required, CapabilityFunctions)
+	}
+	return c.check(required...)
+}
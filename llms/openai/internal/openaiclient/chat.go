@@ -5,11 +5,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strings"
+
+	"github.com/tmc/langchaingo/llms"
 )
 
 const (
@@ -28,6 +29,8 @@ type ChatRequest struct {
 	Stream           bool           `json:"stream,omitempty"`
 	FrequencyPenalty float64        `json:"frequency_penalty,omitempty"`
 	PresencePenalty  float64        `json:"presence_penalty,omitempty"`
+	LogProbs         bool           `json:"logprobs,omitempty"`
+	TopLogProbs      int            `json:"top_logprobs,omitempty"`
 
 	// Function definitions to include in the request.
 	Functions []FunctionDefinition `json:"functions,omitempty"`
@@ -37,11 +40,34 @@ type ChatRequest struct {
 	// `{"name": "my_function"}`
 	FunctionCallBehavior FunctionCallBehavior `json:"function_call,omitempty"`
 
+	// Tools is a list of tools the model may call.
+	Tools []Tool `json:"tools,omitempty"`
+	// ToolChoice controls which tool is called by the model. It is either
+	// "none", "auto" or a ToolChoice.
+	ToolChoice any `json:"tool_choice,omitempty"`
+
+	// ResponseFormat is the format of the response.
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+
 	// StreamingFunc is a function to be called for each chunk of a streaming response.
 	// Return an error to stop streaming early.
 	StreamingFunc func(ctx context.Context, chunk []byte) error `json:"-"`
 }
 
+// ResponseFormat is the format of the response, "text", "json_object" or
+// "json_schema".
+type ResponseFormat struct {
+	Type string `json:"type"`
+	// JSONSchema is the schema of the response for the "json_schema" type.
+	JSONSchema *ResponseFormatJSONSchema `json:"json_schema,omitempty"`
+}
+
+// ResponseFormatJSONSchema is the schema of a response.
+type ResponseFormatJSONSchema struct {
+	Name   string `json:"name"`
+	Schema any    `json:"schema"`
+}
+
 // ChatMessage is a message in a chat request.
 type ChatMessage struct {
 	// The role of the author of this message. One of system, user, or assistant.
@@ -54,6 +80,54 @@ type ChatMessage struct {
 
 	// FunctionCall represents a function call to be made in the message.
 	FunctionCall *FunctionCall `json:"function_call,omitempty"`
+
+	// ToolCalls are the tool calls made by the model in the message.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID is the ID of the tool call a tool message is the result of.
+	ToolCallID string `json:"tool_call_id,omitempty"`
+
+	// MultiContent is the content of a multimodal message. If set, it is sent
+	// as the content instead of Content.
+	MultiContent []ContentPart `json:"-"`
+}
+
+// MarshalJSON encodes the message, sending MultiContent as the content if set.
+func (m ChatMessage) MarshalJSON() ([]byte, error) {
+	type message ChatMessage
+	if len(m.MultiContent) == 0 {
+		return json.Marshal(message(m))
+	}
+	return json.Marshal(struct {
+		message
+		Content []ContentPart `json:"content"`
+	}{
+		message: message(m),
+		Content: m.MultiContent,
+	})
+}
+
+// ContentPartType is the type of a content part.
+type ContentPartType string
+
+const (
+	ContentPartTypeText     ContentPartType = "text"
+	ContentPartTypeImageURL ContentPartType = "image_url"
+)
+
+// ContentPart is a part of the content of a multimodal message.
+type ContentPart struct {
+	Type ContentPartType `json:"type"`
+	// Text is the text of a text part.
+	Text string `json:"text,omitempty"`
+	// ImageURL is the image of an image part.
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL is the URL of an image, which may be a data URL.
+type ImageURL struct {
+	URL string `json:"url"`
+	// Detail is the level of detail of the image: low, high or auto.
+	Detail string `json:"detail,omitempty"`
 }
 
 // ChatChoice is a choice in a chat response.
@@ -61,6 +135,28 @@ type ChatChoice struct {
 	Index        int         `json:"index"`
 	Message      ChatMessage `json:"message"`
 	FinishReason string      `json:"finish_reason"`
+	LogProbs     *LogProbs   `json:"logprobs,omitempty"`
+}
+
+// LogProbs are the log probabilities of the tokens of a choice.
+type LogProbs struct {
+	Content []TokenLogProb `json:"content"`
+}
+
+// TokenLogProb is the log probability of a token.
+type TokenLogProb struct {
+	Token   string  `json:"token"`
+	LogProb float64 `json:"logprob"`
+	Bytes   []int   `json:"bytes,omitempty"`
+	// TopLogProbs are the most likely tokens at the position of the token.
+	TopLogProbs []TopLogProb `json:"top_logprobs,omitempty"`
+}
+
+// TopLogProb is one of the most likely tokens at a position.
+type TopLogProb struct {
+	Token   string  `json:"token"`
+	LogProb float64 `json:"logprob"`
+	Bytes   []int   `json:"bytes,omitempty"`
 }
 
 // ChatUsage is the usage of a chat completion request.
@@ -93,11 +189,13 @@ type StreamedChatResponsePayload struct {
 	Choices []struct {
 		Index float64 `json:"index,omitempty"`
 		Delta struct {
-			Role         string        `json:"role,omitempty"`
-			Content      string        `json:"content,omitempty"`
-			FunctionCall *FunctionCall `json:"function_call,omitempty"`
+			Role         string          `json:"role,omitempty"`
+			Content      string          `json:"content,omitempty"`
+			FunctionCall *FunctionCall   `json:"function_call,omitempty"`
+			ToolCalls    []ToolCallDelta `json:"tool_calls,omitempty"`
 		} `json:"delta,omitempty"`
-		FinishReason string `json:"finish_reason,omitempty"`
+		FinishReason string    `json:"finish_reason,omitempty"`
+		LogProbs     *LogProbs `json:"logprobs,omitempty"`
 	} `json:"choices,omitempty"`
 }
 
@@ -131,6 +229,59 @@ type FunctionCall struct {
 	Arguments string `json:"arguments"`
 }
 
+// ToolType is the type of a tool.
+type ToolType string
+
+const (
+	// ToolTypeFunction is the type of tools that are functions.
+	ToolTypeFunction ToolType = "function"
+)
+
+// Tool is a tool that can be called by the model.
+type Tool struct {
+	// Type is the type of the tool.
+	Type ToolType `json:"type"`
+	// Function is the definition of the function, if the tool is a function.
+	Function FunctionDefinition `json:"function"`
+}
+
+// ToolChoice is a specific tool the model must call.
+type ToolChoice struct {
+	// Type is the type of the tool.
+	Type ToolType `json:"type"`
+	// Function names the function to call, if the tool is a function.
+	Function *FunctionReference `json:"function,omitempty"`
+}
+
+// FunctionReference is a reference to a function by name.
+type FunctionReference struct {
+	// Name is the name of the function.
+	Name string `json:"name"`
+}
+
+// ToolCall is a call to a tool made by the model.
+type ToolCall struct {
+	// ID is the ID of the tool call.
+	ID string `json:"id"`
+	// Type is the type of the tool.
+	Type ToolType `json:"type"`
+	// Function is the function called, if the tool is a function.
+	Function FunctionCall `json:"function"`
+}
+
+// ToolCallDelta is a chunk of a streamed tool call. The chunks of a tool call
+// share its index; the ID, type and name are only sent in the first chunk.
+type ToolCallDelta struct {
+	// Index is the index of the tool call in the message.
+	Index int `json:"index"`
+	// ID is the ID of the tool call.
+	ID string `json:"id,omitempty"`
+	// Type is the type of the tool.
+	Type ToolType `json:"type,omitempty"`
+	// Function is the chunk of the function call.
+	Function FunctionCall `json:"function"`
+}
+
 func (c *Client) createChat(ctx context.Context, payload *ChatRequest) (*ChatResponse, error) {
 	if payload.StreamingFunc != nil {
 		payload.Stream = true
@@ -143,9 +294,6 @@ func (c *Client) createChat(ctx context.Context, payload *ChatRequest) (*ChatRes
 
 	// Build request
 	body := bytes.NewReader(payloadBytes)
-	if c.baseURL == "" {
-		c.baseURL = defaultBaseURL
-	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.buildURL("/chat/completions", c.Model), body)
 	if err != nil {
 		return nil, err
@@ -161,16 +309,16 @@ func (c *Client) createChat(ctx context.Context, payload *ChatRequest) (*ChatRes
 	defer r.Body.Close()
 
 	if r.StatusCode != http.StatusOK {
-		msg := fmt.Sprintf("API returned unexpected status code: %d", r.StatusCode)
-
 		// No need to check the error here: if it fails, we'll just return the
 		// status code.
 		var errResp errorMessage
-		if err := json.NewDecoder(r.Body).Decode(&errResp); err != nil {
-			return nil, errors.New(msg) // nolint:goerr113
-		}
+		_ = json.NewDecoder(r.Body).Decode(&errResp)
 
-		return nil, fmt.Errorf("%s: %s", msg, errResp.Error.Message) // nolint:goerr113
+		return nil, &llms.StatusError{
+			StatusCode: r.StatusCode,
+			Message:    errResp.Error.Message,
+			Header:     r.Header,
+		}
 	}
 	if payload.StreamingFunc != nil {
 		return parseStreamingChatResponse(ctx, r, payload)
@@ -222,6 +370,12 @@ func parseStreamingChatResponse(ctx context.Context, r *http.Response, payload *
 		chunk := []byte(streamResponse.Choices[0].Delta.Content)
 		response.Choices[0].Message.Content += streamResponse.Choices[0].Delta.Content
 		response.Choices[0].FinishReason = streamResponse.Choices[0].FinishReason
+		if logProbs := streamResponse.Choices[0].LogProbs; logProbs != nil {
+			if response.Choices[0].LogProbs == nil {
+				response.Choices[0].LogProbs = &LogProbs{}
+			}
+			response.Choices[0].LogProbs.Content = append(response.Choices[0].LogProbs.Content, logProbs.Content...)
+		}
 		if streamResponse.Choices[0].Delta.FunctionCall != nil {
 			if response.Choices[0].Message.FunctionCall == nil {
 				response.Choices[0].Message.FunctionCall = streamResponse.Choices[0].Delta.FunctionCall
@@ -230,6 +384,12 @@ func parseStreamingChatResponse(ctx context.Context, r *http.Response, payload *
 			}
 			chunk, _ = json.Marshal(response.Choices[0].Message.FunctionCall) // nolint:errchkjson
 		}
+		if len(streamResponse.Choices[0].Delta.ToolCalls) > 0 {
+			response.Choices[0].Message.ToolCalls = appendToolCallDeltas(
+				response.Choices[0].Message.ToolCalls, streamResponse.Choices[0].Delta.ToolCalls,
+			)
+			chunk, _ = json.Marshal(response.Choices[0].Message.ToolCalls) // nolint:errchkjson
+		}
 
 		if payload.StreamingFunc != nil {
 			err := payload.StreamingFunc(ctx, chunk)
@@ -240,3 +400,23 @@ func parseStreamingChatResponse(ctx context.Context, r *http.Response, payload *
 	}
 	return &response, nil
 }
+
+// appendToolCallDeltas merges the streamed chunks of tool calls into the tool
+// calls received so far.
+func appendToolCallDeltas(toolCalls []ToolCall, deltas []ToolCallDelta) []ToolCall {
+	for _, delta := range deltas {
+		for len(toolCalls) <= delta.Index {
+			toolCalls = append(toolCalls, ToolCall{})
+		}
+		toolCall := &toolCalls[delta.Index]
+		if delta.ID != "" {
+			toolCall.ID = delta.ID
+		}
+		if delta.Type != "" {
+			toolCall.Type = delta.Type
+		}
+		toolCall.Function.Name += delta.Function.Name
+		toolCall.Function.Arguments += delta.Function.Arguments
+	}
+	return toolCalls
+}
@@ -3,11 +3,13 @@ package openaiclient
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseStreamingChatResponse_FinishReason(t *testing.T) {
@@ -30,3 +32,60 @@ func TestParseStreamingChatResponse_FinishReason(t *testing.T) {
 	assert.NotNil(t, resp)
 	assert.Equal(t, "stop", resp.Choices[0].FinishReason)
 }
+
+func TestParseStreamingChatResponse_ToolCalls(t *testing.T) {
+	t.Parallel()
+	mockBody := `data: {"choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}
+
+data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_2","type":"function","function":{"name":"get_time","arguments":"{\"tz\""}}]}}]}
+
+data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"location\":\"Paris\"}"}}]}}]}
+
+data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":":\"UTC\"}"}}]},"finish_reason":"tool_calls"}]}
+
+data: [DONE]
+`
+	r := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(mockBody)),
+	}
+
+	var chunks int
+	req := &ChatRequest{
+		StreamingFunc: func(ctx context.Context, chunk []byte) error {
+			chunks++
+			return nil
+		},
+	}
+
+	resp, err := parseStreamingChatResponse(context.Background(), r, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, chunks)
+	assert.Equal(t, "tool_calls", resp.Choices[0].FinishReason)
+	assert.Equal(t, []ToolCall{
+		{ID: "call_1", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_weather", Arguments: `{"location":"Paris"}`}},
+		{ID: "call_2", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_time", Arguments: `{"tz":"UTC"}`}},
+	}, resp.Choices[0].Message.ToolCalls)
+}
+
+func TestChatMessageMarshalMultiContent(t *testing.T) {
+	t.Parallel()
+
+	b, err := json.Marshal(&ChatMessage{Role: "user", Content: "Hi"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"role":"user","content":"Hi"}`, string(b))
+
+	b, err = json.Marshal(&ChatMessage{
+		Role: "user",
+		MultiContent: []ContentPart{
+			{Type: ContentPartTypeText, Text: "What is in this image?"},
+			{Type: ContentPartTypeImageURL, ImageURL: &ImageURL{URL: "https://example.com/cat.png", Detail: "low"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"role":"user","content":[
+		{"type":"text","text":"What is in this image?"},
+		{"type":"image_url","image_url":{"url":"https://example.com/cat.png","detail":"low"}}
+	]}`, string(b))
+}
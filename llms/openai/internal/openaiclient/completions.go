@@ -1,7 +1,14 @@
 package openaiclient
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/tmc/langchaingo/llms"
 )
 
 // CompletionRequest is a request to complete a completion.
@@ -15,20 +22,43 @@ type CompletionRequest struct {
 	PresencePenalty  float64  `json:"presence_penalty,omitempty"`
 	TopP             float64  `json:"top_p,omitempty"`
 	StopWords        []string `json:"stop,omitempty"`
+	LogProbs         bool     `json:"logprobs,omitempty"`
+	TopLogProbs      int      `json:"top_logprobs,omitempty"`
+
+	// ResponseFormat is the format of the response.
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
 
 	// StreamingFunc is a function to be called for each chunk of a streaming response.
 	// Return an error to stop streaming early.
 	StreamingFunc func(ctx context.Context, chunk []byte) error `json:"-"`
 }
 
+// BatchCompletionRequest is a request to complete several prompts at once,
+// with the completions endpoint of the completion models.
+type BatchCompletionRequest struct {
+	Model            string   `json:"model"`
+	Prompts          []string `json:"prompt"`
+	Temperature      float64  `json:"temperature,omitempty"`
+	MaxTokens        int      `json:"max_tokens,omitempty"`
+	N                int      `json:"n,omitempty"`
+	FrequencyPenalty float64  `json:"frequency_penalty,omitempty"`
+	PresencePenalty  float64  `json:"presence_penalty,omitempty"`
+	TopP             float64  `json:"top_p,omitempty"`
+	StopWords        []string `json:"stop,omitempty"`
+	// LogProbs is the number of most likely tokens to return the log
+	// probabilities of at each position, along with the chosen token. If nil,
+	// no log probabilities are returned.
+	LogProbs *int `json:"logprobs,omitempty"`
+}
+
 type CompletionResponse struct {
 	ID      string  `json:"id,omitempty"`
 	Created float64 `json:"created,omitempty"`
 	Choices []struct {
-		FinishReason string      `json:"finish_reason,omitempty"`
-		Index        float64     `json:"index,omitempty"`
-		Logprobs     interface{} `json:"logprobs,omitempty"`
-		Text         string      `json:"text,omitempty"`
+		FinishReason string              `json:"finish_reason,omitempty"`
+		Index        int                 `json:"index"`
+		Logprobs     *CompletionLogProbs `json:"logprobs,omitempty"`
+		Text         string              `json:"text,omitempty"`
 	} `json:"choices,omitempty"`
 	Model  string `json:"model,omitempty"`
 	Object string `json:"object,omitempty"`
@@ -39,6 +69,39 @@ type CompletionResponse struct {
 	} `json:"usage,omitempty"`
 }
 
+// CompletionLogProbs are the log probabilities of the tokens of a choice of
+// the completions endpoint.
+type CompletionLogProbs struct {
+	Tokens        []string             `json:"tokens"`
+	TokenLogProbs []float64            `json:"token_logprobs"`
+	TopLogProbs   []map[string]float64 `json:"top_logprobs"`
+}
+
+// toLogProbs converts the log probabilities to the format of the chat
+// completions endpoint.
+func (l *CompletionLogProbs) toLogProbs() *LogProbs {
+	if l == nil {
+		return nil
+	}
+	logProbs := &LogProbs{Content: make([]TokenLogProb, 0, len(l.Tokens))}
+	for i, token := range l.Tokens {
+		t := TokenLogProb{Token: token}
+		if i < len(l.TokenLogProbs) {
+			t.LogProb = l.TokenLogProbs[i]
+		}
+		if i < len(l.TopLogProbs) {
+			for topToken, logProb := range l.TopLogProbs[i] {
+				t.TopLogProbs = append(t.TopLogProbs, TopLogProb{Token: topToken, LogProb: logProb})
+			}
+			sort.Slice(t.TopLogProbs, func(a, b int) bool {
+				return t.TopLogProbs[a].LogProb > t.TopLogProbs[b].LogProb
+			})
+		}
+		logProbs.Content = append(logProbs.Content, t)
+	}
+	return logProbs
+}
+
 type errorMessage struct {
 	Error struct {
 		Message string `json:"message"`
@@ -84,6 +147,93 @@ func (c *Client) createCompletion(ctx context.Context, payload *CompletionReques
 		StopWords:        payload.StopWords,
 		FrequencyPenalty: payload.FrequencyPenalty,
 		PresencePenalty:  payload.PresencePenalty,
+		LogProbs:         payload.LogProbs,
+		TopLogProbs:      payload.TopLogProbs,
+		ResponseFormat:   payload.ResponseFormat,
 		StreamingFunc:    payload.StreamingFunc,
 	})
 }
+
+// CreateCompletions completes the prompts of the request at once. It returns
+// a completion for each prompt, whose Usage is not set: the API only reports
+// the usage of the whole request.
+func (c *Client) CreateCompletions(ctx context.Context, r *BatchCompletionRequest) ([]*Completion, error) {
+	if r.MaxTokens == 0 {
+		r.MaxTokens = 256
+	}
+	if r.Model == "" {
+		r.Model = c.Model
+	}
+	resp, err := c.createCompletions(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	// The choices of the prompt i have the indexes i*n to i*n+n-1.
+	n := r.N
+	if n < 1 {
+		n = 1
+	}
+	sort.Slice(resp.Choices, func(i, j int) bool { return resp.Choices[i].Index < resp.Choices[j].Index })
+	completions := make([]*Completion, len(r.Prompts))
+	for _, choice := range resp.Choices {
+		i := choice.Index / n
+		if i < 0 || i >= len(completions) {
+			continue
+		}
+		completion := &Completion{
+			Text:         choice.Text,
+			FinishReason: choice.FinishReason,
+			LogProbs:     choice.Logprobs.toLogProbs(),
+		}
+		if completions[i] == nil {
+			completions[i] = completion
+			continue
+		}
+		completions[i].Candidates = append(completions[i].Candidates, completion)
+	}
+	for _, completion := range completions {
+		if completion == nil {
+			return nil, ErrEmptyResponse
+		}
+	}
+	return completions, nil
+}
+
+func (c *Client) createCompletions(ctx context.Context, payload *BatchCompletionRequest) (*CompletionResponse, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.buildURL("/completions", payload.Model), bytes.NewReader(payloadBytes)) // nolint:lll
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	c.setHeaders(req)
+
+	r, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		// No need to check the error here: if it fails, we'll just return the
+		// status code.
+		var errResp errorMessage
+		_ = json.NewDecoder(r.Body).Decode(&errResp)
+
+		return nil, &llms.StatusError{
+			StatusCode: r.StatusCode,
+			Message:    errResp.Error.Message,
+			Header:     r.Header,
+		}
+	}
+
+	var response CompletionResponse
+	if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &response, nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
@@ -22,6 +23,9 @@ const (
 	APITypeOpenAI  APIType = "OPEN_AI"
 	APITypeAzure   APIType = "AZURE"
 	APITypeAzureAD APIType = "AZURE_AD"
+	// APITypeOpenAICompatible is a server implementing the OpenAI API, such
+	// as vLLM or LocalAI. The token is optional.
+	APITypeOpenAICompatible APIType = "OPEN_AI_COMPATIBLE"
 )
 
 // Client is a client for the OpenAI API.
@@ -36,11 +40,33 @@ type Client struct {
 	// required when APIType is APITypeAzure or APITypeAzureAD
 	apiVersion      string
 	embeddingsModel string
+
+	headers     map[string]string
+	queryParams url.Values
 }
 
 // Option is an option for the OpenAI client.
 type Option func(*Client) error
 
+// WithHeaders sets extra headers sent with every request.
+func WithHeaders(headers map[string]string) Option {
+	return func(c *Client) error {
+		c.headers = headers
+		return nil
+	}
+}
+
+// WithQueryParams sets extra query parameters added to the URL of every request.
+func WithQueryParams(params map[string]string) Option {
+	return func(c *Client) error {
+		c.queryParams = make(url.Values, len(params))
+		for k, v := range params {
+			c.queryParams.Set(k, v)
+		}
+		return nil
+	}
+}
+
 // Doer performs a HTTP request.
 type Doer interface {
 	Do(req *http.Request) (*http.Response, error)
@@ -67,13 +93,22 @@ func New(token string, model string, baseURL string, organization string,
 			return nil, err
 		}
 	}
+	if c.baseURL == "" {
+		c.baseURL = defaultBaseURL
+	}
 
 	return c, nil
 }
 
 // Completion is a completion.
 type Completion struct {
-	Text string `json:"text"`
+	Text         string    `json:"text"`
+	FinishReason string    `json:"finish_reason"`
+	Usage        ChatUsage `json:"usage"`
+	// LogProbs are the log probabilities of the tokens, if requested.
+	LogProbs *LogProbs `json:"logprobs,omitempty"`
+	// Candidates are the other completions, if more than one was requested.
+	Candidates []*Completion `json:"candidates,omitempty"`
 }
 
 // CreateCompletion creates a completion.
@@ -85,9 +120,24 @@ func (c *Client) CreateCompletion(ctx context.Context, r *CompletionRequest) (*C
 	if len(resp.Choices) == 0 {
 		return nil, ErrEmptyResponse
 	}
-	return &Completion{
-		Text: resp.Choices[0].Message.Content,
-	}, nil
+	completion := &Completion{
+		Text:         resp.Choices[0].Message.Content,
+		FinishReason: resp.Choices[0].FinishReason,
+		Usage: ChatUsage{
+			PromptTokens:     int(resp.Usage.PromptTokens),
+			CompletionTokens: int(resp.Usage.CompletionTokens),
+			TotalTokens:      int(resp.Usage.TotalTokens),
+		},
+		LogProbs: resp.Choices[0].LogProbs,
+	}
+	for _, choice := range resp.Choices[1:] {
+		completion.Candidates = append(completion.Candidates, &Completion{
+			Text:         choice.Message.Content,
+			FinishReason: choice.FinishReason,
+			LogProbs:     choice.LogProbs,
+		})
+	}
+	return completion, nil
 }
 
 // EmbeddingRequest is a request to create an embedding.
@@ -150,23 +200,38 @@ func IsAzure(apiType APIType) bool {
 
 func (c *Client) setHeaders(req *http.Request) {
 	req.Header.Set("Content-Type", "application/json")
-	if c.apiType == APITypeOpenAI || c.apiType == APITypeAzureAD {
-		req.Header.Set("Authorization", "Bearer "+c.token)
-	} else {
+	switch {
+	case c.token == "" && c.apiType == APITypeOpenAICompatible:
+	case c.apiType == APITypeAzure:
 		req.Header.Set("api-key", c.token)
+	default:
+		req.Header.Set("Authorization", "Bearer "+c.token)
 	}
 	if c.organization != "" {
 		req.Header.Set("OpenAI-Organization", c.organization)
 	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
 }
 
 func (c *Client) buildURL(suffix string, model string) string {
+	var u string
 	if IsAzure(c.apiType) {
-		return c.buildAzureURL(suffix, model)
+		u = c.buildAzureURL(suffix, model)
+	} else {
+		// open ai implement:
+		u = fmt.Sprintf("%s%s", c.baseURL, suffix)
 	}
 
-	// open ai implement:
-	return fmt.Sprintf("%s%s", c.baseURL, suffix)
+	if len(c.queryParams) == 0 {
+		return u
+	}
+	sep := "?"
+	if strings.Contains(u, "?") {
+		sep = "&"
+	}
+	return u + sep + c.queryParams.Encode()
 }
 
 func (c *Client) buildAzureURL(suffix string, model string) string {
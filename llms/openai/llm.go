@@ -12,12 +12,14 @@ var (
 	ErrEmptyResponse              = errors.New("no response")
 	ErrMissingToken               = errors.New("missing the OpenAI API key, set it in the OPENAI_API_KEY environment variable") //nolint:lll
 	ErrMissingAzureEmbeddingModel = errors.New("embeddings model needs to be provided when using Azure API")
+	ErrMissingBaseURL             = errors.New("base URL needs to be provided when using an OpenAI compatible API")
+	ErrUnsupportedCapability      = errors.New("capability not supported by the API")
 
 	ErrUnexpectedResponseLength = errors.New("unexpected length of response")
 )
 
 // newClient is wrapper for openaiclient internal package.
-func newClient(opts ...Option) (*openaiclient.Client, error) {
+func newClient(opts ...Option) (*openaiclient.Client, capabilities, error) {
 	options := &options{
 		token:        os.Getenv(tokenEnvVarName),
 		model:        os.Getenv(modelEnvVarName),
@@ -35,14 +37,27 @@ func newClient(opts ...Option) (*openaiclient.Client, error) {
 	if openaiclient.IsAzure(openaiclient.APIType(options.apiType)) && options.apiVersion == "" {
 		options.apiVersion = DefaultAPIVersion
 		if options.embeddingModel == "" {
-			return nil, ErrMissingAzureEmbeddingModel
+			return nil, nil, ErrMissingAzureEmbeddingModel
 		}
 	}
 
-	if len(options.token) == 0 {
-		return nil, ErrMissingToken
+	if options.apiType == APITypeOpenAICompatible {
+		if options.baseURL == "" {
+			return nil, nil, ErrMissingBaseURL
+		}
+	} else if len(options.token) == 0 {
+		return nil, nil, ErrMissingToken
 	}
 
-	return openaiclient.New(options.token, options.model, options.baseURL, options.organization,
-		openaiclient.APIType(options.apiType), options.apiVersion, options.httpClient, options.embeddingModel)
+	var clientOpts []openaiclient.Option
+	if len(options.headers) > 0 {
+		clientOpts = append(clientOpts, openaiclient.WithHeaders(options.headers))
+	}
+	if len(options.queryParams) > 0 {
+		clientOpts = append(clientOpts, openaiclient.WithQueryParams(options.queryParams))
+	}
+	c, err := openaiclient.New(options.token, options.model, options.baseURL, options.organization,
+		openaiclient.APIType(options.apiType), options.apiVersion, options.httpClient, options.embeddingModel,
+		clientOpts...)
+	return c, newCapabilities(options.capabilities), err
 }
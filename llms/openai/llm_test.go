@@ -0,0 +1,309 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/jsonschema"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+func TestOpenAICompatible(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "2024-01", r.URL.Query().Get("version"))
+		assert.Equal(t, "secret", r.Header.Get("X-Proxy-Key"))
+		assert.Empty(t, r.Header.Get("Authorization"))
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{
+				"message":       map[string]any{"role": "assistant", "content": "Hello"},
+				"finish_reason": "stop",
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	chat, err := NewChat(
+		WithAPIType(APITypeOpenAICompatible),
+		WithToken(""),
+		WithBaseURL(server.URL+"/v1"),
+		WithHeaders(map[string]string{"X-Proxy-Key": "secret"}),
+		WithQueryParams(map[string]string{"version": "2024-01"}),
+		WithCapabilities(CapabilityChat),
+	)
+	require.NoError(t, err)
+
+	messages := []schema.ChatMessage{schema.HumanChatMessage{Content: "Hi"}}
+	msg, err := chat.Call(context.Background(), messages)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", msg.Content)
+
+	_, err = chat.Call(context.Background(), messages,
+		llms.WithStreamingFunc(func(context.Context, []byte) error { return nil }))
+	require.ErrorIs(t, err, ErrUnsupportedCapability)
+	assert.ErrorContains(t, err, "streaming")
+
+	_, err = chat.Call(context.Background(), messages,
+		llms.WithFunctions([]llms.FunctionDefinition{{Name: "f"}}))
+	require.ErrorIs(t, err, ErrUnsupportedCapability)
+
+	_, err = chat.CreateEmbedding(context.Background(), []string{"Hi"})
+	require.ErrorIs(t, err, ErrUnsupportedCapability)
+}
+
+func TestOpenAICompatibleRequiresBaseURL(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(WithAPIType(APITypeOpenAICompatible), WithBaseURL(""))
+	require.ErrorIs(t, err, ErrMissingBaseURL)
+}
+
+func TestChatResponseFormat(t *testing.T) {
+	t.Parallel()
+
+	var (
+		responseFormat  any
+		requestMessages []any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		responseFormat = req["response_format"]
+		requestMessages, _ = req["messages"].([]any)
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{
+				"message":       map[string]any{"role": "assistant", "content": `{"name":"Ann"}`},
+				"finish_reason": "stop",
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	chat, err := NewChat(WithToken("token"), WithBaseURL(server.URL))
+	require.NoError(t, err)
+	messages := []schema.ChatMessage{schema.HumanChatMessage{Content: "Who? Answer in JSON."}}
+
+	msg, err := chat.Call(context.Background(), messages, llms.WithJSONMode())
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"Ann"}`, msg.Content)
+	assert.Equal(t, map[string]any{"type": "json_object"}, responseFormat)
+	assert.Len(t, requestMessages, 1)
+
+	// The API rejects JSON mode unless a message mentions JSON.
+	_, err = chat.Call(context.Background(), []schema.ChatMessage{schema.HumanChatMessage{Content: "Who?"}},
+		llms.WithJSONMode())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"type": "json_object"}, responseFormat)
+	require.Len(t, requestMessages, 2)
+	assert.Equal(t, "system", requestMessages[0].(map[string]any)["role"])
+	assert.Contains(t, requestMessages[0].(map[string]any)["content"], "JSON")
+
+	_, err = chat.Call(context.Background(), messages, llms.WithJSONSchema("person", jsonschema.Definition{
+		Type:     jsonschema.Object,
+		Required: []string{"age"},
+	}))
+	var formatErr *llms.ResponseFormatError
+	require.ErrorAs(t, err, &formatErr)
+	assert.Equal(t, "json_schema", responseFormat.(map[string]any)["type"])
+	assert.Equal(t, "person", responseFormat.(map[string]any)["json_schema"].(map[string]any)["name"])
+}
+
+func TestChatCandidatesLogProbs(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		choice := func(index int, content string, logprob float64) map[string]any {
+			return map[string]any{
+				"index":         index,
+				"message":       map[string]any{"role": "assistant", "content": content},
+				"finish_reason": "stop",
+				"logprobs": map[string]any{"content": []any{map[string]any{
+					"token":        content,
+					"logprob":      logprob,
+					"top_logprobs": []any{map[string]any{"token": content, "logprob": logprob}},
+				}}},
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{choice(0, "Yes", -0.1), choice(1, "No", -2.3)},
+			"usage":   map[string]any{"prompt_tokens": 5, "completion_tokens": 2, "total_tokens": 7},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	chat, err := NewChat(WithToken("token"), WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	generations, err := chat.Generate(context.Background(), [][]schema.ChatMessage{{
+		schema.HumanChatMessage{Content: "Is it?"},
+	}}, llms.WithN(2), llms.WithTopLogProbs(1))
+	require.NoError(t, err)
+	assert.Equal(t, true, request["logprobs"])
+	assert.Equal(t, float64(1), request["top_logprobs"])
+	assert.Equal(t, float64(2), request["n"])
+
+	require.Len(t, generations, 1)
+	generation := generations[0]
+	assert.Equal(t, "Yes", generation.Text)
+	assert.Equal(t, []llms.TokenLogProb{{
+		Token:       "Yes",
+		LogProb:     -0.1,
+		TopLogProbs: []llms.TopLogProb{{Token: "Yes", LogProb: -0.1}},
+	}}, generation.LogProbs)
+
+	require.Len(t, generation.Candidates, 1)
+	assert.Equal(t, "No", generation.Candidates[0].Message.Content)
+	assert.Equal(t, -2.3, generation.Candidates[0].LogProbs[0].LogProb)
+
+	candidates := generation.AllCandidates()
+	require.Len(t, candidates, 2)
+	assert.Empty(t, candidates[0].Candidates)
+	assert.Equal(t, "Yes", candidates[0].Text)
+}
+
+func TestGenerateConcurrently(t *testing.T) {
+	t.Parallel()
+
+	var running, peak, requested atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		var req chatRequestPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		prompt := req.Messages[0].Content
+		requested.Add(1)
+		switch prompt {
+		case "busy":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		case "bad":
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{
+				"message":       map[string]any{"role": "assistant", "content": "echo " + prompt},
+				"finish_reason": "stop",
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	llm, err := New(WithToken("token"), WithBaseURL(server.URL), WithModel("gpt-4"))
+	require.NoError(t, err)
+
+	prompts := make([]string, 8)
+	for i := range prompts {
+		prompts[i] = fmt.Sprint(i)
+	}
+	generations, err := llm.Generate(context.Background(), prompts, llms.WithMaxConcurrency(3))
+	require.NoError(t, err)
+	require.Len(t, generations, len(prompts))
+	for i, g := range generations {
+		assert.Equal(t, "echo "+prompts[i], g.Text)
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+
+	// Retryable errors are aggregated.
+	_, err = llm.Generate(context.Background(), []string{"a", "busy", "b", "busy"}, llms.WithMaxConcurrency(2))
+	var statusErr *llms.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.ErrorContains(t, err, "prompt 1: ")
+	assert.ErrorContains(t, err, "prompt 3: ")
+
+	// The prompts after a terminal error are not sent.
+	requested.Store(0)
+	_, err = llm.Generate(context.Background(), []string{"bad", "a", "b"}, llms.WithMaxConcurrency(1))
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+	assert.Equal(t, int32(1), requested.Load())
+}
+
+// chatRequestPayload is the part of a chat request the tests read.
+type chatRequestPayload struct {
+	Messages []struct {
+		Content string `json:"content"`
+	} `json:"messages"`
+}
+
+func TestGenerateBatches(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu         sync.Mutex
+		batchSizes []int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/completions", r.URL.Path)
+		var req struct {
+			Model    string   `json:"model"`
+			Prompts  []string `json:"prompt"`
+			N        int      `json:"n"`
+			LogProbs *int     `json:"logprobs"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "gpt-3.5-turbo-instruct", req.Model)
+		assert.Equal(t, 2, req.N)
+		mu.Lock()
+		batchSizes = append(batchSizes, len(req.Prompts))
+		mu.Unlock()
+
+		// The choices are returned out of order.
+		var choices []any
+		for i := len(req.Prompts)*req.N - 1; i >= 0; i-- {
+			prompt := req.Prompts[i/req.N]
+			choices = append(choices, map[string]any{
+				"index":         i,
+				"text":          fmt.Sprintf("%s/%d", prompt, i%req.N),
+				"finish_reason": "stop",
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"choices": choices})
+	}))
+	t.Cleanup(server.Close)
+
+	llm, err := New(WithToken("token"), WithBaseURL(server.URL), WithModel("gpt-3.5-turbo-instruct"))
+	require.NoError(t, err)
+
+	prompts := make([]string, 25)
+	for i := range prompts {
+		prompts[i] = fmt.Sprint(i)
+	}
+	generations, err := llm.Generate(context.Background(), prompts, llms.WithN(2), llms.WithMaxConcurrency(2))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{20, 5}, batchSizes)
+
+	require.Len(t, generations, len(prompts))
+	for i, g := range generations {
+		assert.Equal(t, prompts[i]+"/0", g.Text)
+		require.Len(t, g.Candidates, 1)
+		assert.Equal(t, prompts[i]+"/1", g.Candidates[0].Text)
+		usage, ok := llms.GetUsage(g)
+		require.True(t, ok)
+		assert.True(t, usage.Estimated)
+		assert.Equal(t, "stop", usage.FinishReason)
+	}
+}
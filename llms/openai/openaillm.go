@@ -2,16 +2,23 @@ package openai
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/internal/parallel"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/openai/internal/openaiclient"
 	"github.com/tmc/langchaingo/schema"
 )
 
+// _maxBatchSize is the maximum number of prompts of a request to the
+// completions endpoint.
+const _maxBatchSize = 20
+
 type LLM struct {
 	CallbacksHandler callbacks.Handler
 	client           *openaiclient.Client
+	capabilities     capabilities
 }
 
 var (
@@ -21,9 +28,10 @@ var (
 
 // New returns a new OpenAI LLM.
 func New(opts ...Option) (*LLM, error) {
-	c, err := newClient(opts...)
+	c, capabilities, err := newClient(opts...)
 	return &LLM{
-		client: c,
+		client:       c,
+		capabilities: capabilities,
 	}, err
 }
 
@@ -48,12 +56,103 @@ func (o *LLM) Generate(ctx context.Context, prompts []string, options ...llms.Ca
 	for _, opt := range options {
 		opt(&opts)
 	}
+	if err := o.capabilities.checkCall(CapabilityCompletions, opts); err != nil {
+		return nil, err
+	}
+
+	var (
+		generations []*llms.Generation
+		err         error
+	)
+	if o.supportsBatches(opts) {
+		generations, err = o.generateBatches(ctx, prompts, opts)
+	} else {
+		generations = make([]*llms.Generation, len(prompts))
+		err = parallel.Run(ctx, len(prompts), opts.Concurrency(), func(ctx context.Context, i int) error {
+			generation, err := o.generate(ctx, prompts[i], opts)
+			if err != nil {
+				if len(prompts) > 1 {
+					err = fmt.Errorf("prompt %d: %w", i, err)
+				}
+				return err
+			}
+			generations[i] = generation
+			return nil
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if o.CallbacksHandler != nil {
+		o.CallbacksHandler.HandleLLMEnd(ctx, llms.NewLLMResult(generations))
+	}
+
+	return generations, nil
+}
+
+// supportsBatches reports whether the prompts can be completed in batches,
+// which only the completions endpoint of the completion models supports.
+func (o *LLM) supportsBatches(opts llms.CallOptions) bool {
+	if opts.StreamingFunc != nil {
+		return false
+	}
+	model := opts.Model
+	if model == "" {
+		model = o.client.Model
+	}
+	info, ok := llms.LookupModel(model)
+	return ok && info.Supports(llms.ModelFeatureCompletion) && !info.Supports(llms.ModelFeatureChat)
+}
+
+// generate generates the completion of a prompt.
+func (o *LLM) generate(ctx context.Context, prompt string, opts llms.CallOptions) (*llms.Generation, error) {
+	req := &openaiclient.CompletionRequest{
+		Model:            opts.Model,
+		Prompt:           prompt,
+		MaxTokens:        opts.MaxTokens,
+		StopWords:        opts.StopWords,
+		Temperature:      opts.Temperature,
+		N:                opts.N,
+		FrequencyPenalty: opts.FrequencyPenalty,
+		PresencePenalty:  opts.PresencePenalty,
+		TopP:             opts.TopP,
+		LogProbs:         opts.LogProbs,
+		TopLogProbs:      opts.TopLogProbs,
+		ResponseFormat:   responseFormatToClientResponseFormat(opts.ResponseFormat),
+		StreamingFunc:    opts.StreamingFunc,
+	}
+	result, err := o.client.CreateCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	usage := llms.Usage{
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		TotalTokens:      result.Usage.TotalTokens,
+		FinishReason:     result.FinishReason,
+	}
+	return completionToGeneration(result, req.Model, prompt, usage, opts.ResponseFormat)
+}
 
-	generations := make([]*llms.Generation, 0, len(prompts))
-	for _, prompt := range prompts {
-		result, err := o.client.CreateCompletion(ctx, &openaiclient.CompletionRequest{
+// generateBatches generates the completions of the prompts in batches of
+// _maxBatchSize prompts.
+func (o *LLM) generateBatches(ctx context.Context, prompts []string, opts llms.CallOptions) ([]*llms.Generation, error) { //nolint:lll
+	generations := make([]*llms.Generation, len(prompts))
+	batches := (len(prompts) + _maxBatchSize - 1) / _maxBatchSize
+	err := parallel.Run(ctx, batches, opts.Concurrency(), func(ctx context.Context, b int) error {
+		start := b * _maxBatchSize
+		end := start + _maxBatchSize
+		if end > len(prompts) {
+			end = len(prompts)
+		}
+		batch := make([]string, 0, end-start)
+		for _, prompt := range prompts[start:end] {
+			batch = append(batch, llms.PromptWithResponseFormat(prompt, opts.ResponseFormat))
+		}
+		req := &openaiclient.BatchCompletionRequest{
 			Model:            opts.Model,
-			Prompt:           prompt,
+			Prompts:          batch,
 			MaxTokens:        opts.MaxTokens,
 			StopWords:        opts.StopWords,
 			Temperature:      opts.Temperature,
@@ -61,21 +160,65 @@ func (o *LLM) Generate(ctx context.Context, prompts []string, options ...llms.Ca
 			FrequencyPenalty: opts.FrequencyPenalty,
 			PresencePenalty:  opts.PresencePenalty,
 			TopP:             opts.TopP,
-			StreamingFunc:    opts.StreamingFunc,
-		})
+		}
+		if opts.LogProbs {
+			req.LogProbs = &opts.TopLogProbs
+		}
+		results, err := o.client.CreateCompletions(ctx, req)
 		if err != nil {
-			return nil, err
+			if batches > 1 {
+				err = fmt.Errorf("prompts %d to %d: %w", start, end-1, err)
+			}
+			return err
 		}
-		generations = append(generations, &llms.Generation{
-			Text: result.Text,
-		})
+		for i, result := range results {
+			// The API only reports the usage of the whole batch.
+			usage := llms.Usage{FinishReason: result.FinishReason}
+			generation, err := completionToGeneration(result, req.Model, batch[i], usage, opts.ResponseFormat)
+			if err != nil {
+				return fmt.Errorf("prompt %d: %w", start+i, err)
+			}
+			generations[start+i] = generation
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return generations, nil
+}
 
-	if o.CallbacksHandler != nil {
-		o.CallbacksHandler.HandleLLMEnd(ctx, llms.LLMResult{Generations: [][]*llms.Generation{generations}})
+// completionToGeneration converts a completion of the prompt to a generation,
+// checking that its text matches the response format.
+func completionToGeneration(
+	result *openaiclient.Completion,
+	model, prompt string,
+	usage llms.Usage,
+	format *llms.ResponseFormat,
+) (*llms.Generation, error) {
+	text, err := llms.ParseResponse(format, result.Text)
+	if err != nil {
+		return nil, err
 	}
-
-	return generations, nil
+	generation := &llms.Generation{
+		Text: text,
+		GenerationInfo: map[string]any{
+			llms.UsageKey: usage.OrEstimate(model, prompt, text),
+		},
+		LogProbs: logProbsFromClient(result.LogProbs),
+	}
+	for _, candidate := range result.Candidates {
+		text, err := llms.ParseResponse(format, candidate.Text)
+		if err != nil {
+			return nil, err
+		}
+		generation.Candidates = append(generation.Candidates, &llms.Generation{
+			Text:           text,
+			GenerationInfo: map[string]any{"FinishReason": candidate.FinishReason},
+			LogProbs:       logProbsFromClient(candidate.LogProbs),
+		})
+	}
+	return generation, nil
 }
 
 func (o *LLM) GeneratePrompt(ctx context.Context, promptValues []schema.PromptValue, options ...llms.CallOption) (llms.LLMResult, error) { //nolint:lll
@@ -88,6 +231,9 @@ func (o *LLM) GetNumTokens(text string) int {
 
 // CreateEmbedding creates embeddings for the given input texts.
 func (o *LLM) CreateEmbedding(ctx context.Context, inputTexts []string) ([][]float32, error) {
+	if err := o.capabilities.check(CapabilityEmbeddings); err != nil {
+		return nil, err
+	}
 	embeddings, err := o.client.CreateEmbedding(ctx, &openaiclient.EmbeddingRequest{
 		Input: inputTexts,
 		Model: o.client.Model,
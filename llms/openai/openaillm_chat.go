@@ -2,9 +2,12 @@ package openai
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/internal/parallel"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/openai/internal/openaiclient"
 	"github.com/tmc/langchaingo/schema"
@@ -15,6 +18,7 @@ type ChatMessage = openaiclient.ChatMessage
 type Chat struct {
 	CallbacksHandler callbacks.Handler
 	client           *openaiclient.Client
+	capabilities     capabilities
 }
 
 const (
@@ -22,6 +26,7 @@ const (
 	RoleAssistant = "assistant"
 	RoleUser      = "user"
 	RoleFunction  = "function"
+	RoleTool      = "tool"
 )
 
 var (
@@ -31,9 +36,10 @@ var (
 
 // NewChat returns a new OpenAI chat LLM.
 func NewChat(opts ...Option) (*Chat, error) {
-	c, err := newClient(opts...)
+	c, capabilities, err := newClient(opts...)
 	return &Chat{
-		client: c,
+		client:       c,
+		capabilities: capabilities,
 	}, err
 }
 
@@ -49,8 +55,7 @@ func (o *Chat) Call(ctx context.Context, messages []schema.ChatMessage, options
 	return r[0].Message, nil
 }
 
-//nolint:funlen
-func (o *Chat) Generate(ctx context.Context, messageSets [][]schema.ChatMessage, options ...llms.CallOption) ([]*llms.Generation, error) { // nolint:lll,cyclop
+func (o *Chat) Generate(ctx context.Context, messageSets [][]schema.ChatMessage, options ...llms.CallOption) ([]*llms.Generation, error) { // nolint:lll
 	if o.CallbacksHandler != nil {
 		o.CallbacksHandler.HandleLLMStart(ctx, getPromptsFromMessageSets(messageSets))
 	}
@@ -59,60 +64,142 @@ func (o *Chat) Generate(ctx context.Context, messageSets [][]schema.ChatMessage,
 	for _, opt := range options {
 		opt(&opts)
 	}
-	generations := make([]*llms.Generation, 0, len(messageSets))
-	for _, messageSet := range messageSets {
-		req := &openaiclient.ChatRequest{
-			Model:            opts.Model,
-			StopWords:        opts.StopWords,
-			Messages:         messagesToClientMessages(messageSet),
-			StreamingFunc:    opts.StreamingFunc,
-			Temperature:      opts.Temperature,
-			MaxTokens:        opts.MaxTokens,
-			N:                opts.N, // TODO: note, we are not returning multiple completions
-			FrequencyPenalty: opts.FrequencyPenalty,
-			PresencePenalty:  opts.PresencePenalty,
-
-			FunctionCallBehavior: openaiclient.FunctionCallBehavior(opts.FunctionCallBehavior),
-		}
-		for _, fn := range opts.Functions {
-			req.Functions = append(req.Functions, openaiclient.FunctionDefinition{
-				Name:        fn.Name,
-				Description: fn.Description,
-				Parameters:  fn.Parameters,
-			})
+	if err := o.capabilities.checkCall(CapabilityChat, opts); err != nil {
+		return nil, err
+	}
+	generations := make([]*llms.Generation, len(messageSets))
+	err := parallel.Run(ctx, len(messageSets), opts.Concurrency(), func(ctx context.Context, i int) error {
+		generation, err := o.generate(ctx, messageSets[i], opts)
+		if err != nil {
+			if len(messageSets) > 1 {
+				err = fmt.Errorf("message set %d: %w", i, err)
+			}
+			return err
 		}
-		result, err := o.client.CreateChat(ctx, req)
+		generations[i] = generation
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if o.CallbacksHandler != nil {
+		o.CallbacksHandler.HandleLLMEnd(ctx, llms.NewLLMResult(generations))
+	}
+
+	return generations, nil
+}
+
+// generate generates the chat completion of a message set.
+func (o *Chat) generate(ctx context.Context, messageSet []schema.ChatMessage, opts llms.CallOptions) (*llms.Generation, error) { //nolint:lll
+	req := &openaiclient.ChatRequest{
+		Model:            opts.Model,
+		StopWords:        opts.StopWords,
+		Messages:         messagesToClientMessages(messagesWithJSONInstructions(messageSet, opts.ResponseFormat)),
+		StreamingFunc:    opts.StreamingFunc,
+		Temperature:      opts.Temperature,
+		MaxTokens:        opts.MaxTokens,
+		N:                opts.N,
+		FrequencyPenalty: opts.FrequencyPenalty,
+		PresencePenalty:  opts.PresencePenalty,
+		LogProbs:         opts.LogProbs,
+		TopLogProbs:      opts.TopLogProbs,
+
+		FunctionCallBehavior: openaiclient.FunctionCallBehavior(opts.FunctionCallBehavior),
+	}
+	for _, fn := range opts.Functions {
+		req.Functions = append(req.Functions, openaiclient.FunctionDefinition{
+			Name:        fn.Name,
+			Description: fn.Description,
+			Parameters:  fn.Parameters,
+		})
+	}
+	req.Tools, req.ToolChoice = toolsToClientTools(opts.Tools, opts.ToolChoice)
+	req.ResponseFormat = responseFormatToClientResponseFormat(opts.ResponseFormat)
+	result, err := o.client.CreateChat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Choices) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	generation, err := choiceToGeneration(result.Choices[0], opts.ResponseFormat)
+	if err != nil {
+		return nil, err
+	}
+	for _, choice := range result.Choices[1:] {
+		candidate, err := choiceToGeneration(choice, opts.ResponseFormat)
 		if err != nil {
 			return nil, err
 		}
-		if len(result.Choices) == 0 {
-			return nil, ErrEmptyResponse
-		}
-		generationInfo := make(map[string]any, reflect.ValueOf(result.Usage).NumField())
-		generationInfo["CompletionTokens"] = result.Usage.CompletionTokens
-		generationInfo["PromptTokens"] = result.Usage.PromptTokens
-		generationInfo["TotalTokens"] = result.Usage.TotalTokens
-		msg := &schema.AIChatMessage{
-			Content: result.Choices[0].Message.Content,
+		candidate.GenerationInfo = map[string]any{"FinishReason": choice.FinishReason}
+		generation.Candidates = append(generation.Candidates, candidate)
+	}
+	generationInfo := make(map[string]any, reflect.ValueOf(result.Usage).NumField())
+	generationInfo["CompletionTokens"] = result.Usage.CompletionTokens
+	generationInfo["PromptTokens"] = result.Usage.PromptTokens
+	generationInfo["TotalTokens"] = result.Usage.TotalTokens
+	usage := llms.Usage{
+		PromptTokens:     int(result.Usage.PromptTokens),
+		CompletionTokens: int(result.Usage.CompletionTokens),
+		TotalTokens:      int(result.Usage.TotalTokens),
+		FinishReason:     result.Choices[0].FinishReason,
+	}
+	generationInfo[llms.UsageKey] = usage.OrEstimateChat(req.Model, messageSet, generation.Text)
+	generation.GenerationInfo = generationInfo
+	return generation, nil
+}
+
+// choiceToGeneration converts a choice of a chat response to a generation,
+// checking that its content matches the response format.
+func choiceToGeneration(choice *openaiclient.ChatChoice, format *llms.ResponseFormat) (*llms.Generation, error) {
+	if len(choice.Message.ToolCalls) == 0 && choice.FinishReason != "function_call" {
+		content, err := llms.ParseResponse(format, choice.Message.Content)
+		if err != nil {
+			return nil, err
 		}
-		if result.Choices[0].FinishReason == "function_call" {
-			msg.FunctionCall = &schema.FunctionCall{
-				Name:      result.Choices[0].Message.FunctionCall.Name,
-				Arguments: result.Choices[0].Message.FunctionCall.Arguments,
-			}
+		choice.Message.Content = content
+	}
+	msg := &schema.AIChatMessage{
+		Content: choice.Message.Content,
+	}
+	if choice.FinishReason == "function_call" {
+		msg.FunctionCall = &schema.FunctionCall{
+			Name:      choice.Message.FunctionCall.Name,
+			Arguments: choice.Message.FunctionCall.Arguments,
 		}
-		generations = append(generations, &llms.Generation{
-			Message:        msg,
-			Text:           msg.Content,
-			GenerationInfo: generationInfo,
+	}
+	for _, tc := range choice.Message.ToolCalls {
+		msg.ToolCalls = append(msg.ToolCalls, schema.ToolCall{
+			ID:   tc.ID,
+			Type: string(tc.Type),
+			FunctionCall: &schema.FunctionCall{
+				Name:      tc.Function.Name,
+				Arguments: tc.Function.Arguments,
+			},
 		})
 	}
+	return &llms.Generation{
+		Message:  msg,
+		Text:     msg.Content,
+		LogProbs: logProbsFromClient(choice.LogProbs),
+	}, nil
+}
 
-	if o.CallbacksHandler != nil {
-		o.CallbacksHandler.HandleLLMEnd(ctx, llms.LLMResult{Generations: [][]*llms.Generation{generations}})
+// logProbsFromClient converts the log probabilities of a choice.
+func logProbsFromClient(logProbs *openaiclient.LogProbs) []llms.TokenLogProb {
+	if logProbs == nil {
+		return nil
 	}
-
-	return generations, nil
+	tokens := make([]llms.TokenLogProb, 0, len(logProbs.Content))
+	for _, lp := range logProbs.Content {
+		token := llms.TokenLogProb{Token: lp.Token, LogProb: lp.LogProb}
+		for _, top := range lp.TopLogProbs {
+			token.TopLogProbs = append(token.TopLogProbs, llms.TopLogProb{Token: top.Token, LogProb: top.LogProb})
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
 }
 
 func (o *Chat) GetNumTokens(text string) int {
@@ -125,6 +212,9 @@ func (o *Chat) GeneratePrompt(ctx context.Context, promptValues []schema.PromptV
 
 // CreateEmbedding creates embeddings for the given input texts.
 func (o *Chat) CreateEmbedding(ctx context.Context, inputTexts []string) ([][]float32, error) {
+	if err := o.capabilities.check(CapabilityEmbeddings); err != nil {
+		return nil, err
+	}
 	embeddings, err := o.client.CreateEmbedding(ctx, &openaiclient.EmbeddingRequest{
 		Input: inputTexts,
 	})
@@ -171,12 +261,126 @@ func messagesToClientMessages(messages []schema.ChatMessage) []*openaiclient.Cha
 			msg.Role = "user"
 		case schema.ChatMessageTypeFunction:
 			msg.Role = "function"
+		case schema.ChatMessageTypeTool:
+			msg.Role = "tool"
 		}
 		if n, ok := m.(schema.Named); ok {
 			msg.Name = n.GetName()
 		}
+		switch m := m.(type) {
+		case schema.AIChatMessage:
+			if m.FunctionCall != nil {
+				msg.FunctionCall = &openaiclient.FunctionCall{
+					Name:      m.FunctionCall.Name,
+					Arguments: m.FunctionCall.Arguments,
+				}
+			}
+			for _, tc := range m.ToolCalls {
+				toolCall := openaiclient.ToolCall{ID: tc.ID, Type: openaiclient.ToolType(tc.Type)}
+				if tc.FunctionCall != nil {
+					toolCall.Function = openaiclient.FunctionCall{
+						Name:      tc.FunctionCall.Name,
+						Arguments: tc.FunctionCall.Arguments,
+					}
+				}
+				msg.ToolCalls = append(msg.ToolCalls, toolCall)
+			}
+		case schema.ToolChatMessage:
+			msg.ToolCallID = m.ID
+		}
+		if mp, ok := m.(schema.MultiPart); ok && schema.HasMedia(mp.GetParts()) {
+			msg.MultiContent = partsToClientParts(mp.GetParts())
+		}
 		msgs[i] = msg
 	}
 
 	return msgs
 }
+
+func partsToClientParts(parts []schema.ContentPart) []openaiclient.ContentPart {
+	clientParts := make([]openaiclient.ContentPart, 0, len(parts))
+	for _, part := range parts {
+		switch p := part.(type) {
+		case schema.TextContent:
+			clientParts = append(clientParts, openaiclient.ContentPart{
+				Type: openaiclient.ContentPartTypeText,
+				Text: p.Text,
+			})
+		case schema.ImageURLContent:
+			clientParts = append(clientParts, openaiclient.ContentPart{
+				Type:     openaiclient.ContentPartTypeImageURL,
+				ImageURL: &openaiclient.ImageURL{URL: p.URL, Detail: p.Detail},
+			})
+		case schema.BinaryContent:
+			clientParts = append(clientParts, openaiclient.ContentPart{
+				Type:     openaiclient.ContentPartTypeImageURL,
+				ImageURL: &openaiclient.ImageURL{URL: p.DataURL()},
+			})
+		}
+	}
+	return clientParts
+}
+
+// messagesWithJSONInstructions returns the messages with the instructions of
+// the response format added if it is JSON mode and no message mentions JSON,
+// as the API rejects JSON mode requests whose messages don't.
+func messagesWithJSONInstructions(messages []schema.ChatMessage, format *llms.ResponseFormat) []schema.ChatMessage {
+	if format == nil || format.Type != llms.ResponseFormatTypeJSON {
+		return messages
+	}
+	for _, m := range messages {
+		if strings.Contains(strings.ToLower(m.GetContent()), "json") {
+			return messages
+		}
+	}
+	return llms.MessagesWithResponseFormat(messages, format)
+}
+
+func responseFormatToClientResponseFormat(format *llms.ResponseFormat) *openaiclient.ResponseFormat {
+	if format == nil {
+		return nil
+	}
+	clientFormat := &openaiclient.ResponseFormat{Type: string(format.Type)}
+	if format.Type == llms.ResponseFormatTypeJSONSchema && format.Schema != nil {
+		name := format.Name
+		if name == "" {
+			name = "response"
+		}
+		clientFormat.JSONSchema = &openaiclient.ResponseFormatJSONSchema{Name: name, Schema: format.Schema}
+	}
+	return clientFormat
+}
+
+func toolsToClientTools(tools []llms.Tool, choice any) ([]openaiclient.Tool, any) {
+	clientTools := make([]openaiclient.Tool, 0, len(tools))
+	for _, tool := range tools {
+		clientTool := openaiclient.Tool{Type: openaiclient.ToolType(tool.Type)}
+		if tool.Function != nil {
+			clientTool.Function = openaiclient.FunctionDefinition{
+				Name:        tool.Function.Name,
+				Description: tool.Function.Description,
+				Parameters:  tool.Function.Parameters,
+			}
+		}
+		clientTools = append(clientTools, clientTool)
+	}
+
+	switch c := choice.(type) {
+	case llms.ToolChoice:
+		choice = toolChoiceToClientToolChoice(c)
+	case *llms.ToolChoice:
+		choice = nil
+		if c != nil {
+			choice = toolChoiceToClientToolChoice(*c)
+		}
+	}
+	return clientTools, choice
+}
+
+func toolChoiceToClientToolChoice(choice llms.ToolChoice) openaiclient.ToolChoice {
+	clientChoice := openaiclient.ToolChoice{Type: openaiclient.ToolType(choice.Type)}
+	if choice.Function != nil {
+		clientChoice.Function = &openaiclient.FunctionReference{Name: choice.Function.Name}
+	}
+	return clientChoice
+}
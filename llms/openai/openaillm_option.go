@@ -15,6 +15,10 @@ const (
 	APITypeOpenAI  APIType = APIType(openaiclient.APITypeOpenAI)
 	APITypeAzure           = APIType(openaiclient.APITypeAzure)
 	APITypeAzureAD         = APIType(openaiclient.APITypeAzureAD)
+	// APITypeOpenAICompatible is a server implementing the OpenAI API, such as
+	// vLLM, LocalAI, LM Studio or the llama.cpp server. It requires a base URL
+	// but no token.
+	APITypeOpenAICompatible = APIType(openaiclient.APITypeOpenAICompatible)
 )
 
 const (
@@ -32,6 +36,10 @@ type options struct {
 	// required when APIType is APITypeAzure or APITypeAzureAD
 	apiVersion     string
 	embeddingModel string
+
+	headers      map[string]string
+	queryParams  map[string]string
+	capabilities []Capability
 }
 
 type Option func(*options)
@@ -99,3 +107,27 @@ func WithHTTPClient(client openaiclient.Doer) Option {
 		opts.httpClient = client
 	}
 }
+
+// WithHeaders sets extra headers sent with every request, e.g. the
+// authentication header of a proxy.
+func WithHeaders(headers map[string]string) Option {
+	return func(opts *options) {
+		opts.headers = headers
+	}
+}
+
+// WithQueryParams sets extra query parameters added to the URL of every request.
+func WithQueryParams(params map[string]string) Option {
+	return func(opts *options) {
+		opts.queryParams = params
+	}
+}
+
+// WithCapabilities declares the capabilities supported by the API. Calls that
+// need another capability fail with ErrUnsupportedCapability instead of being
+// sent. If not set, all capabilities are assumed to be supported.
+func WithCapabilities(capabilities ...Capability) Option {
+	return func(opts *options) {
+		opts.capabilities = capabilities
+	}
+}
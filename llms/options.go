@@ -1,6 +1,10 @@
 package llms
 
-import "context"
+import (
+	"context"
+
+	"github.com/tmc/langchaingo/jsonschema"
+)
 
 // CallOption is a function that configures a CallOptions.
 type CallOption func(*CallOptions)
@@ -36,6 +40,14 @@ type CallOptions struct {
 	FrequencyPenalty float64 `json:"frequency_penalty"`
 	// PresencePenalty is the presence penalty for sampling.
 	PresencePenalty float64 `json:"presence_penalty"`
+	// LogProbs is whether to return the log probabilities of the generated tokens.
+	LogProbs bool `json:"logprobs"`
+	// TopLogProbs is the number of most likely tokens to return at each position,
+	// along with their log probabilities.
+	TopLogProbs int `json:"top_logprobs"`
+	// MaxConcurrency is the maximum number of prompts generated concurrently.
+	// If not set, the default set with SetDefaultMaxConcurrency is used.
+	MaxConcurrency int `json:"max_concurrency"`
 
 	// Function defitions to include in the request.
 	Functions []FunctionDefinition `json:"functions"`
@@ -44,6 +56,40 @@ type CallOptions struct {
 	// If a specific function should be invoked, use the format:
 	// `{"name": "my_function"}`
 	FunctionCallBehavior FunctionCallBehavior `json:"function_call"`
+
+	// Tools is a list of tools the model may call.
+	Tools []Tool `json:"tools"`
+	// ToolChoice controls which tool is called by the model. It is either
+	// "none", "auto" or a ToolChoice that names a function.
+	ToolChoice any `json:"tool_choice"`
+
+	// ResponseFormat is the format of the response expected from the model.
+	ResponseFormat *ResponseFormat `json:"response_format"`
+}
+
+// ToolTypeFunction is the type of tools that are functions.
+const ToolTypeFunction = "function"
+
+// Tool is a tool that can be called by the model.
+type Tool struct {
+	// Type is the type of the tool. Only ToolTypeFunction is supported.
+	Type string `json:"type"`
+	// Function is the definition of the function, if the tool is a function.
+	Function *FunctionDefinition `json:"function,omitempty"`
+}
+
+// ToolChoice is a specific tool the model must call.
+type ToolChoice struct {
+	// Type is the type of the tool.
+	Type string `json:"type"`
+	// Function names the function to call, if the tool is a function.
+	Function *FunctionReference `json:"function,omitempty"`
+}
+
+// FunctionReference is a reference to a function by name.
+type FunctionReference struct {
+	// Name is the name of the function.
+	Name string `json:"name"`
 }
 
 // FunctionDefinition is a definition of a function that can be called by the model.
@@ -171,6 +217,30 @@ func WithPresencePenalty(presencePenalty float64) CallOption {
 	}
 }
 
+// WithLogProbs will add an option to return the log probabilities of the generated tokens.
+func WithLogProbs() CallOption {
+	return func(o *CallOptions) {
+		o.LogProbs = true
+	}
+}
+
+// WithTopLogProbs will add an option to return the log probabilities of the n most likely
+// tokens at each position. It implies WithLogProbs.
+func WithTopLogProbs(n int) CallOption {
+	return func(o *CallOptions) {
+		o.LogProbs = true
+		o.TopLogProbs = n
+	}
+}
+
+// WithMaxConcurrency will add an option to set the maximum number of prompts
+// generated concurrently.
+func WithMaxConcurrency(n int) CallOption {
+	return func(o *CallOptions) {
+		o.MaxConcurrency = n
+	}
+}
+
 // WithFunctionCallBehavior will add an option to set the behavior to use when calling functions.
 func WithFunctionCallBehavior(behavior FunctionCallBehavior) CallOption {
 	return func(o *CallOptions) {
@@ -184,3 +254,40 @@ func WithFunctions(functions []FunctionDefinition) CallOption {
 		o.Functions = functions
 	}
 }
+
+// WithTools will add an option to set the tools the model may call.
+func WithTools(tools []Tool) CallOption {
+	return func(o *CallOptions) {
+		o.Tools = tools
+	}
+}
+
+// WithToolChoice will add an option to set which tool the model calls. Pass
+// "none", "auto" or a ToolChoice.
+func WithToolChoice(choice any) CallOption {
+	return func(o *CallOptions) {
+		o.ToolChoice = choice
+	}
+}
+
+// WithResponseFormat will add an option to set the format of the response.
+func WithResponseFormat(format *ResponseFormat) CallOption {
+	return func(o *CallOptions) {
+		o.ResponseFormat = format
+	}
+}
+
+// WithJSONMode will add an option to request a response that is a JSON object.
+func WithJSONMode() CallOption {
+	return WithResponseFormat(&ResponseFormat{Type: ResponseFormatTypeJSON})
+}
+
+// WithJSONSchema will add an option to request a JSON response matching the
+// schema.
+func WithJSONSchema(name string, schema jsonschema.Definition) CallOption {
+	return WithResponseFormat(&ResponseFormat{
+		Type:   ResponseFormatTypeJSONSchema,
+		Name:   name,
+		Schema: &schema,
+	})
+}
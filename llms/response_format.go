@@ -0,0 +1,141 @@
+package llms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/jsonschema"
+	"github.com/tmc/langchaingo/schema"
+)
+
+var (
+	// ErrInvalidJSON is returned when a response expected in JSON is not valid JSON.
+	ErrInvalidJSON = errors.New("invalid JSON")
+	// ErrNotJSONObject is returned when a response expected to be a JSON object
+	// is another JSON value.
+	ErrNotJSONObject = errors.New("not a JSON object")
+)
+
+// ResponseFormatType is the type of response expected from the model.
+type ResponseFormatType string
+
+const (
+	// ResponseFormatTypeText is a free text response.
+	ResponseFormatTypeText ResponseFormatType = "text"
+	// ResponseFormatTypeJSON is a response that is a JSON object.
+	ResponseFormatTypeJSON ResponseFormatType = "json_object"
+	// ResponseFormatTypeJSONSchema is a JSON response matching a schema.
+	ResponseFormatTypeJSONSchema ResponseFormatType = "json_schema"
+)
+
+// ResponseFormat is the format of the response expected from the model.
+type ResponseFormat struct {
+	// Type is the type of the response.
+	Type ResponseFormatType `json:"type"`
+	// Name is the name of the schema, for ResponseFormatTypeJSONSchema.
+	Name string `json:"name,omitempty"`
+	// Schema is the schema of the response, for ResponseFormatTypeJSONSchema.
+	Schema *jsonschema.Definition `json:"schema,omitempty"`
+}
+
+// ResponseFormatError is returned when a response does not match the
+// requested format.
+type ResponseFormatError struct {
+	// Text is the text of the response.
+	Text string
+	// Err is the validation failure, a *jsonschema.ValidationError if the
+	// response does not match the schema.
+	Err error
+}
+
+func (e *ResponseFormatError) Error() string {
+	return fmt.Sprintf("response does not match the requested format: %v", e.Err)
+}
+
+func (e *ResponseFormatError) Unwrap() error {
+	return e.Err
+}
+
+// isJSON reports whether the format asks for a JSON response.
+func (f *ResponseFormat) isJSON() bool {
+	return f != nil && (f.Type == ResponseFormatTypeJSON || f.Type == ResponseFormatTypeJSONSchema)
+}
+
+// Instructions returns instructions asking for a response in the format, for
+// the models that cannot be constrained to it. It is empty for text responses.
+func (f *ResponseFormat) Instructions() string {
+	if !f.isJSON() {
+		return ""
+	}
+	if f.Type == ResponseFormatTypeJSON || f.Schema == nil {
+		return "Respond only with a valid JSON object, without any other text."
+	}
+	b, err := json.Marshal(f.Schema)
+	if err != nil {
+		return "Respond only with a valid JSON object, without any other text."
+	}
+	return fmt.Sprintf("Respond only with a valid JSON value matching this JSON schema, without any other text:\n%s",
+		string(b))
+}
+
+// PromptWithResponseFormat returns the prompt followed by the instructions of
+// the response format, if any.
+func PromptWithResponseFormat(prompt string, f *ResponseFormat) string {
+	instructions := f.Instructions()
+	if instructions == "" {
+		return prompt
+	}
+	return prompt + "\n\n" + instructions
+}
+
+// MessagesWithResponseFormat returns the messages with the instructions of the
+// response format, if any, added to the leading system message.
+func MessagesWithResponseFormat(messages []schema.ChatMessage, f *ResponseFormat) []schema.ChatMessage {
+	instructions := f.Instructions()
+	if instructions == "" {
+		return messages
+	}
+	if len(messages) > 0 && messages[0].GetType() == schema.ChatMessageTypeSystem {
+		system := schema.SystemChatMessage{Content: messages[0].GetContent() + "\n\n" + instructions}
+		return append([]schema.ChatMessage{system}, messages[1:]...)
+	}
+	return append([]schema.ChatMessage{schema.SystemChatMessage{Content: instructions}}, messages...)
+}
+
+// ParseResponse checks that the text of a response matches the format. It
+// returns the JSON value with the surrounding whitespace and markdown code
+// fence removed, or a *ResponseFormatError. Text responses are returned as is.
+func ParseResponse(f *ResponseFormat, text string) (string, error) {
+	if !f.isJSON() {
+		return text, nil
+	}
+	trimmed := trimCodeFence(text)
+	if !json.Valid([]byte(trimmed)) {
+		return "", &ResponseFormatError{Text: text, Err: ErrInvalidJSON}
+	}
+	if f.Type == ResponseFormatTypeJSON && !strings.HasPrefix(trimmed, "{") {
+		return "", &ResponseFormatError{Text: text, Err: ErrNotJSONObject}
+	}
+	if f.Type == ResponseFormatTypeJSONSchema && f.Schema != nil {
+		if err := f.Schema.Validate([]byte(trimmed)); err != nil {
+			return "", &ResponseFormatError{Text: text, Err: err}
+		}
+	}
+	return trimmed, nil
+}
+
+// trimCodeFence removes the whitespace and markdown code fence around text.
+func trimCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") || !strings.HasSuffix(text, "```") || len(text) < 6 {
+		return text
+	}
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "```"), "```")
+	// Remove the language of the fence, e.g. ```json.
+	if i := strings.IndexByte(text, '\n'); i >= 0 && !strings.ContainsAny(text[:i], "{[\"") {
+		text = text[i+1:]
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,75 @@
+package llms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/jsonschema"
+	"github.com/tmc/langchaingo/schema"
+)
+
+func TestParseResponse(t *testing.T) {
+	t.Parallel()
+
+	format := &ResponseFormat{
+		Type: ResponseFormatTypeJSONSchema,
+		Schema: &jsonschema.Definition{
+			Type:       jsonschema.Object,
+			Properties: map[string]jsonschema.Definition{"name": {Type: jsonschema.String}},
+			Required:   []string{"name"},
+		},
+	}
+
+	text, err := ParseResponse(format, "```json\n{\"name\": \"Ann\"}\n```")
+	require.NoError(t, err)
+	assert.Equal(t, `{"name": "Ann"}`, text)
+
+	_, err = ParseResponse(format, `{"age": 3}`)
+	var formatErr *ResponseFormatError
+	require.ErrorAs(t, err, &formatErr)
+	assert.Equal(t, `{"age": 3}`, formatErr.Text)
+	var validationErr *jsonschema.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "$", validationErr.Path)
+
+	_, err = ParseResponse(&ResponseFormat{Type: ResponseFormatTypeJSON}, "Sure! Here it is.")
+	require.ErrorIs(t, err, ErrInvalidJSON)
+
+	for _, value := range []string{"42", `"x"`, `[{"name": "Ann"}]`, "null"} {
+		_, err = ParseResponse(&ResponseFormat{Type: ResponseFormatTypeJSON}, value)
+		require.ErrorIs(t, err, ErrNotJSONObject, value)
+	}
+	text, err = ParseResponse(&ResponseFormat{Type: ResponseFormatTypeJSON}, ` {"name": "Ann"} `)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name": "Ann"}`, text)
+
+	text, err = ParseResponse(nil, " free text ")
+	require.NoError(t, err)
+	assert.Equal(t, " free text ", text)
+}
+
+func TestMessagesWithResponseFormat(t *testing.T) {
+	t.Parallel()
+
+	format := &ResponseFormat{Type: ResponseFormatTypeJSON}
+	instructions := format.Instructions()
+	require.NotEmpty(t, instructions)
+
+	messages := MessagesWithResponseFormat([]schema.ChatMessage{
+		schema.SystemChatMessage{Content: "Be brief."},
+		schema.HumanChatMessage{Content: "Hi"},
+	}, format)
+	assert.Equal(t, []schema.ChatMessage{
+		schema.SystemChatMessage{Content: "Be brief.\n\n" + instructions},
+		schema.HumanChatMessage{Content: "Hi"},
+	}, messages)
+
+	messages = MessagesWithResponseFormat([]schema.ChatMessage{schema.HumanChatMessage{Content: "Hi"}}, format)
+	assert.Equal(t, []schema.ChatMessage{
+		schema.SystemChatMessage{Content: instructions},
+		schema.HumanChatMessage{Content: "Hi"},
+	}, messages)
+
+	assert.Equal(t, "Hi", PromptWithResponseFormat("Hi", nil))
+}
@@ -0,0 +1,164 @@
+package llms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/tmc/langchaingo/schema"
+)
+
+// ErrEmptyStream is sent in a StreamEventError when the model returns no
+// generation.
+var ErrEmptyStream = errors.New("no generation")
+
+// StreamEventType is the type of a StreamEvent.
+type StreamEventType string
+
+const (
+	// StreamEventText is a chunk of the generated text.
+	StreamEventText StreamEventType = "text"
+	// StreamEventFunctionCall is a chunk of a function call or of tool calls.
+	StreamEventFunctionCall StreamEventType = "function_call"
+	// StreamEventUsage carries the usage of the generation.
+	StreamEventUsage StreamEventType = "usage"
+	// StreamEventDone carries the complete generation. It is the last event of
+	// a successful stream.
+	StreamEventDone StreamEventType = "done"
+	// StreamEventError carries the error that ended the stream. It is the last
+	// event of a failed stream.
+	StreamEventError StreamEventType = "error"
+)
+
+// StreamEvent is an event of a stream returned by Stream or StreamChat.
+type StreamEvent struct {
+	Type StreamEventType
+	// Text is the text delta of a StreamEventText.
+	Text string
+	// FunctionCall is the function call received so far, for a
+	// StreamEventFunctionCall of a function call.
+	FunctionCall *schema.FunctionCall
+	// ToolCalls are the tool calls received so far, for a
+	// StreamEventFunctionCall of tool calls.
+	ToolCalls []schema.ToolCall
+	// Usage is the usage of a StreamEventUsage.
+	Usage *Usage
+	// Generation is the complete generation of a StreamEventDone.
+	Generation *Generation
+	// Err is the error of a StreamEventError.
+	Err error
+}
+
+// Stream generates a completion for the prompt and returns a channel of the
+// events of the stream. The channel is closed after a StreamEventDone or a
+// StreamEventError. The caller must read the channel until it is closed, or
+// cancel the context to stop the generation.
+//
+// The chunks are received through the StreamingFunc of the options. LLMs that
+// do not stream send the whole text in a single chunk.
+func Stream(ctx context.Context, llm LLM, prompt string, options ...CallOption) <-chan StreamEvent {
+	return stream(ctx, options, func(options []CallOption) ([]*Generation, error) {
+		return llm.Generate(ctx, []string{prompt}, options...)
+	})
+}
+
+// StreamChat is like Stream for a chat LLM.
+func StreamChat(ctx context.Context, chat ChatLLM, messages []schema.ChatMessage, options ...CallOption) <-chan StreamEvent { // nolint:lll
+	return stream(ctx, options, func(options []CallOption) ([]*Generation, error) {
+		return chat.Generate(ctx, [][]schema.ChatMessage{messages}, options...)
+	})
+}
+
+func stream(ctx context.Context, options []CallOption, generate func([]CallOption) ([]*Generation, error)) <-chan StreamEvent { // nolint:lll
+	events := make(chan StreamEvent, 1)
+
+	opts := CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	calls := len(opts.Functions) > 0 || len(opts.Tools) > 0
+
+	send := func(event StreamEvent) error {
+		select {
+		case events <- event:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	go func() {
+		defer close(events)
+
+		streamed := false
+		streamingFunc := func(ctx context.Context, chunk []byte) error {
+			streamed = true
+			if opts.StreamingFunc != nil {
+				if err := opts.StreamingFunc(ctx, chunk); err != nil {
+					return err
+				}
+			}
+			return send(chunkEvent(chunk, calls))
+		}
+
+		// The options are copied, as the caller may reuse its slice while the
+		// generation runs.
+		generations, err := generate(append(append([]CallOption{}, options...), WithStreamingFunc(streamingFunc)))
+		if err == nil && len(generations) == 0 {
+			err = ErrEmptyStream
+		}
+		if err != nil {
+			_ = send(StreamEvent{Type: StreamEventError, Err: err})
+			return
+		}
+
+		g := generations[0]
+		if !streamed {
+			for _, event := range generationEvents(g) {
+				if send(event) != nil {
+					return
+				}
+			}
+		}
+		if u, ok := GetUsage(g); ok {
+			if send(StreamEvent{Type: StreamEventUsage, Usage: &u}) != nil {
+				return
+			}
+		}
+		_ = send(StreamEvent{Type: StreamEventDone, Generation: g})
+	}()
+
+	return events
+}
+
+// chunkEvent returns the event of a streamed chunk. If the request may call
+// functions, chunks that decode to a function call or to tool calls are
+// reported as such, since providers stream them as JSON.
+func chunkEvent(chunk []byte, calls bool) StreamEvent {
+	if calls {
+		var toolCalls []schema.ToolCall
+		if err := json.Unmarshal(chunk, &toolCalls); err == nil && len(toolCalls) > 0 {
+			return StreamEvent{Type: StreamEventFunctionCall, ToolCalls: toolCalls}
+		}
+		var functionCall schema.FunctionCall
+		if err := json.Unmarshal(chunk, &functionCall); err == nil && functionCall.Name != "" {
+			return StreamEvent{Type: StreamEventFunctionCall, FunctionCall: &functionCall}
+		}
+	}
+	return StreamEvent{Type: StreamEventText, Text: string(chunk)}
+}
+
+// generationEvents returns the events of a generation that was not streamed.
+func generationEvents(g *Generation) []StreamEvent {
+	var events []StreamEvent
+	if g.Text != "" {
+		events = append(events, StreamEvent{Type: StreamEventText, Text: g.Text})
+	}
+	if g.Message != nil && g.Message.FunctionCall != nil {
+		events = append(events, StreamEvent{Type: StreamEventFunctionCall, FunctionCall: g.Message.FunctionCall})
+	}
+	if g.Message != nil && len(g.Message.ToolCalls) > 0 {
+		events = append(events, StreamEvent{Type: StreamEventFunctionCall, ToolCalls: g.Message.ToolCalls})
+	}
+	return events
+}
@@ -0,0 +1,128 @@
+package llms_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/fake"
+	"github.com/tmc/langchaingo/schema"
+)
+
+func collect(events <-chan llms.StreamEvent) []llms.StreamEvent {
+	var all []llms.StreamEvent
+	for event := range events {
+		all = append(all, event)
+	}
+	return all
+}
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	var chunks []string
+	llm := fake.New(fake.WithResponses("hello streaming world"))
+	events := collect(llms.Stream(context.Background(), llm, "hi",
+		llms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+			chunks = append(chunks, string(chunk))
+			return nil
+		})))
+
+	require.Len(t, events, 5)
+	text := ""
+	for _, event := range events[:3] {
+		assert.Equal(t, llms.StreamEventText, event.Type)
+		text += event.Text
+	}
+	assert.Equal(t, "hello streaming world", text)
+	assert.Equal(t, []string{"hello", " streaming", " world"}, chunks)
+
+	assert.Equal(t, llms.StreamEventUsage, events[3].Type)
+	assert.Equal(t, 3, events[3].Usage.CompletionTokens)
+	assert.Equal(t, llms.StreamEventDone, events[4].Type)
+	assert.Equal(t, "hello streaming world", events[4].Generation.Text)
+}
+
+func TestStreamError(t *testing.T) {
+	t.Parallel()
+
+	errBoom := errors.New("boom")
+	llm := fake.New(fake.WithResponseFunc(func(context.Context, string) (string, error) {
+		return "", errBoom
+	}))
+	events := collect(llms.Stream(context.Background(), llm, "hi"))
+
+	require.Len(t, events, 1)
+	assert.Equal(t, llms.StreamEventError, events[0].Type)
+	assert.ErrorIs(t, events[0].Err, errBoom)
+}
+
+// nonStreamingChat is a chat LLM that ignores the streaming function.
+type nonStreamingChat struct{}
+
+func (nonStreamingChat) Call(context.Context, []schema.ChatMessage, ...llms.CallOption) (*schema.AIChatMessage, error) { // nolint:lll
+	return nil, nil
+}
+
+func (nonStreamingChat) Generate(context.Context, [][]schema.ChatMessage, ...llms.CallOption) ([]*llms.Generation, error) { // nolint:lll
+	return []*llms.Generation{{
+		Message: &schema.AIChatMessage{
+			FunctionCall: &schema.FunctionCall{Name: "get_weather", Arguments: `{}`},
+		},
+	}}, nil
+}
+
+func TestStreamChatWithoutStreaming(t *testing.T) {
+	t.Parallel()
+
+	events := collect(llms.StreamChat(context.Background(), nonStreamingChat{}, []schema.ChatMessage{
+		schema.HumanChatMessage{Content: "hi"},
+	}))
+
+	require.Len(t, events, 2)
+	assert.Equal(t, llms.StreamEventFunctionCall, events[0].Type)
+	assert.Equal(t, "get_weather", events[0].FunctionCall.Name)
+	assert.Equal(t, llms.StreamEventDone, events[1].Type)
+}
+
+func TestStreamFunctionCallChunks(t *testing.T) {
+	t.Parallel()
+
+	chat := fake.NewChat(fake.WithResponses(`{"name":"get_weather","arguments":"{}"}`), fake.WithChunkSize(1000))
+	events := collect(llms.StreamChat(context.Background(), chat, []schema.ChatMessage{
+		schema.HumanChatMessage{Content: "hi"},
+	}, llms.WithFunctions([]llms.FunctionDefinition{{Name: "get_weather"}})))
+
+	require.NotEmpty(t, events)
+	assert.Equal(t, llms.StreamEventFunctionCall, events[0].Type)
+	assert.Equal(t, "get_weather", events[0].FunctionCall.Name)
+	assert.Equal(t, llms.StreamEventDone, events[len(events)-1].Type)
+}
+
+func TestStreamCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := llms.Stream(ctx, fake.New(fake.WithResponses("one two three four five")), "hi")
+	<-events
+	cancel()
+	// The stream ends once the context is canceled.
+	for range events { // nolint:revive
+	}
+}
+
+func TestStreamDoesNotModifyOptions(t *testing.T) {
+	t.Parallel()
+
+	llm := fake.New(fake.WithResponses("hello"))
+	// The spare capacity of the options must not be written to.
+	options := make([]llms.CallOption, 1, 2)
+	options[0] = llms.WithTemperature(0)
+	events := collect(llms.Stream(context.Background(), llm, "hi", options...))
+	require.NotEmpty(t, events)
+	assert.Equal(t, llms.StreamEventDone, events[len(events)-1].Type)
+	assert.Nil(t, options[:2][1])
+}
@@ -0,0 +1,112 @@
+package llms
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+// ErrTokenizerNotFound is returned when no tokenizer is known for a model.
+var ErrTokenizerNotFound = errors.New("tokenizer not found")
+
+// Tokenizer converts text to the tokens of a model and back.
+type Tokenizer interface {
+	// Encode returns the token ids of the text.
+	Encode(text string) []int
+	// Decode returns the text of the token ids.
+	Decode(ids []int) string
+	// Count returns the number of tokens of the text.
+	Count(text string) int
+}
+
+// nolint:gochecknoglobals
+var tokenizers = struct {
+	sync.RWMutex
+	m map[string]Tokenizer
+}{m: make(map[string]Tokenizer)}
+
+// RegisterTokenizer registers the tokenizer of a model, replacing any previous
+// one. The name also matches the models it is a prefix of, such as
+// "llama-2" for "llama-2-7b-chat", the longest registered name winning.
+func RegisterTokenizer(model string, t Tokenizer) {
+	tokenizers.Lock()
+	defer tokenizers.Unlock()
+	tokenizers.m[model] = t
+}
+
+// LookupTokenizer returns the tokenizer registered for the model with
+// RegisterTokenizer.
+func LookupTokenizer(model string) (Tokenizer, bool) {
+	tokenizers.RLock()
+	defer tokenizers.RUnlock()
+	if t, ok := tokenizers.m[model]; ok {
+		return t, true
+	}
+	var (
+		match string
+		found Tokenizer
+	)
+	for name, t := range tokenizers.m {
+		if name != "" && strings.HasPrefix(model, name) && len(name) > len(match) {
+			match, found = name, t
+		}
+	}
+	return found, found != nil
+}
+
+// GetTokenizer returns the tokenizer registered for the model with
+// RegisterTokenizer, or the tiktoken encoding of the model.
+func GetTokenizer(model string) (Tokenizer, error) {
+	if t, ok := LookupTokenizer(model); ok {
+		return t, nil
+	}
+	e, err := tiktoken.EncodingForModel(model)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrTokenizerNotFound, model, err)
+	}
+	return NewTiktokenTokenizer(e), nil
+}
+
+// TiktokenTokenizer is a Tokenizer using a tiktoken encoding.
+type TiktokenTokenizer struct {
+	encoding          *tiktoken.Tiktoken
+	allowedSpecial    []string
+	disallowedSpecial []string
+}
+
+var _ Tokenizer = (*TiktokenTokenizer)(nil)
+
+// NewTiktokenTokenizer returns a tokenizer using the encoding. The special
+// tokens of the encoding are encoded as text.
+func NewTiktokenTokenizer(encoding *tiktoken.Tiktoken) *TiktokenTokenizer {
+	return &TiktokenTokenizer{encoding: encoding}
+}
+
+// WithSpecialTokens returns a copy of the tokenizer encoding the allowed
+// special tokens as such, and failing on the disallowed ones, as described by
+// tiktoken.
+func (t *TiktokenTokenizer) WithSpecialTokens(allowed, disallowed []string) *TiktokenTokenizer {
+	return &TiktokenTokenizer{
+		encoding:          t.encoding,
+		allowedSpecial:    allowed,
+		disallowedSpecial: disallowed,
+	}
+}
+
+// Encode returns the token ids of the text.
+func (t *TiktokenTokenizer) Encode(text string) []int {
+	return t.encoding.Encode(text, t.allowedSpecial, t.disallowedSpecial)
+}
+
+// Decode returns the text of the token ids.
+func (t *TiktokenTokenizer) Decode(ids []int) string {
+	return t.encoding.Decode(ids)
+}
+
+// Count returns the number of tokens of the text.
+func (t *TiktokenTokenizer) Count(text string) int {
+	return len(t.Encode(text))
+}
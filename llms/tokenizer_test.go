@@ -0,0 +1,61 @@
+package llms
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wordTokenizer is a tokenizer with a token per word.
+type wordTokenizer struct {
+	words []string
+}
+
+func (t *wordTokenizer) Encode(text string) []int {
+	var ids []int
+	for _, word := range strings.Fields(text) {
+		ids = append(ids, len(t.words))
+		t.words = append(t.words, word)
+	}
+	return ids
+}
+
+func (t *wordTokenizer) Decode(ids []int) string {
+	words := make([]string, 0, len(ids))
+	for _, id := range ids {
+		words = append(words, t.words[id])
+	}
+	return strings.Join(words, " ")
+}
+
+func (t *wordTokenizer) Count(text string) int {
+	return len(strings.Fields(text))
+}
+
+func TestRegisterTokenizer(t *testing.T) {
+	t.Parallel()
+
+	words := &wordTokenizer{}
+	longer := &wordTokenizer{}
+	RegisterTokenizer("test-llama", words)
+	RegisterTokenizer("test-llama-3", longer)
+
+	tk, ok := LookupTokenizer("test-llama-2-7b")
+	require.True(t, ok)
+	assert.Same(t, words, tk)
+	tk, ok = LookupTokenizer("test-llama-3-8b")
+	require.True(t, ok)
+	assert.Same(t, longer, tk)
+	_, ok = LookupTokenizer("test-mistral")
+	assert.False(t, ok)
+
+	assert.Equal(t, 3, CountTokens("test-llama-2-7b", "one two  three"))
+
+	tk, err := GetTokenizer("test-llama")
+	require.NoError(t, err)
+	assert.Same(t, words, tk)
+	_, err = GetTokenizer("test-mistral")
+	require.ErrorIs(t, err, ErrTokenizerNotFound)
+}
@@ -0,0 +1,153 @@
+package llms
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/tmc/langchaingo/schema"
+)
+
+// UsageKey is the key under which the Usage of a generation is stored in
+// Generation.GenerationInfo, and the total Usage of a result is stored in
+// LLMResult.LLMOutput.
+const UsageKey = "Usage"
+
+// Usage is the token usage of a generation, normalized across providers.
+type Usage struct {
+	// PromptTokens is the number of tokens in the prompt.
+	PromptTokens int `json:"prompt_tokens"`
+	// CompletionTokens is the number of tokens in the completion.
+	CompletionTokens int `json:"completion_tokens"`
+	// TotalTokens is the sum of the prompt and completion tokens.
+	TotalTokens int `json:"total_tokens"`
+	// FinishReason is the reason the model stopped generating, as reported by
+	// the provider.
+	FinishReason string `json:"finish_reason,omitempty"`
+	// Estimated is true if the token counts were estimated with CountTokens
+	// because the provider did not report them.
+	Estimated bool `json:"estimated,omitempty"`
+	// Model is the model of the generation, if known.
+	Model string `json:"model,omitempty"`
+}
+
+// EstimateUsage estimates the usage of a generation with CountTokens.
+func EstimateUsage(model, prompt, completion string) Usage {
+	promptTokens := CountTokens(model, prompt)
+	completionTokens := CountTokens(model, completion)
+	return Usage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+		Estimated:        true,
+		Model:            model,
+	}
+}
+
+// OrEstimate returns the usage if the provider reported any tokens, or else
+// the usage estimated with EstimateUsage, keeping the finish reason. The model
+// of the usage defaults to model.
+func (u Usage) OrEstimate(model, prompt, completion string) Usage {
+	if u.TotalTokens > 0 || u.PromptTokens > 0 || u.CompletionTokens > 0 {
+		if u.TotalTokens == 0 {
+			u.TotalTokens = u.PromptTokens + u.CompletionTokens
+		}
+		if u.Model == "" {
+			u.Model = model
+		}
+		return u
+	}
+	estimated := EstimateUsage(model, prompt, completion)
+	estimated.FinishReason = u.FinishReason
+	if u.Model != "" {
+		estimated.Model = u.Model
+	}
+	return estimated
+}
+
+// OrEstimateChat is like OrEstimate for a chat generation, estimating the
+// prompt tokens from the content of the messages.
+func (u Usage) OrEstimateChat(model string, messages []schema.ChatMessage, completion string) Usage {
+	contents := make([]string, 0, len(messages))
+	for _, m := range messages {
+		contents = append(contents, m.GetContent())
+	}
+	return u.OrEstimate(model, strings.Join(contents, "\n"), completion)
+}
+
+// Add returns the sum of the usages. The result is estimated if either usage
+// is, has no finish reason, and has a model only if both usages have the same.
+func (u Usage) Add(other Usage) Usage {
+	sum := Usage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+		Estimated:        u.Estimated || other.Estimated,
+	}
+	if u.Model == other.Model {
+		sum.Model = u.Model
+	}
+	return sum
+}
+
+// SetUsage stores the usage in the generation info of the generation.
+func SetUsage(g *Generation, u Usage) {
+	if g.GenerationInfo == nil {
+		g.GenerationInfo = make(map[string]any)
+	}
+	g.GenerationInfo[UsageKey] = u
+}
+
+// GetUsage returns the usage stored in the generation info of the generation.
+// It also accepts the usage of a generation decoded from JSON.
+func GetUsage(g *Generation) (Usage, bool) {
+	if g == nil {
+		return Usage{}, false
+	}
+	switch u := g.GenerationInfo[UsageKey].(type) {
+	case Usage:
+		return u, true
+	case *Usage:
+		return *u, u != nil
+	case map[string]any:
+		b, err := json.Marshal(u)
+		if err != nil {
+			return Usage{}, false
+		}
+		var usage Usage
+		if err := json.Unmarshal(b, &usage); err != nil {
+			return Usage{}, false
+		}
+		return usage, true
+	default:
+		return Usage{}, false
+	}
+}
+
+// TotalUsage returns the sum of the usages of the generations.
+func TotalUsage(generations []*Generation) Usage {
+	var (
+		total Usage
+		found bool
+	)
+	for _, g := range generations {
+		u, ok := GetUsage(g)
+		switch {
+		case !ok:
+		case !found:
+			total, found = u, true
+			total.FinishReason = ""
+		default:
+			total = total.Add(u)
+		}
+	}
+	return total
+}
+
+// NewLLMResult returns a result holding the generations, with their total
+// usage stored in the LLMOutput.
+func NewLLMResult(generations []*Generation) LLMResult {
+	return LLMResult{
+		Generations: [][]*Generation{generations},
+		LLMOutput:   map[string]any{UsageKey: TotalUsage(generations)},
+	}
+}
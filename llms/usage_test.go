@@ -0,0 +1,93 @@
+package llms
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/schema"
+)
+
+func TestUsageOrEstimate(t *testing.T) {
+	t.Parallel()
+
+	reported := Usage{PromptTokens: 3, CompletionTokens: 2, FinishReason: "stop"}.OrEstimate("", "a b c", "d e")
+	assert.Equal(t, Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5, FinishReason: "stop"}, reported)
+
+	estimated := Usage{FinishReason: "length"}.OrEstimate("", "a prompt", "a completion")
+	assert.True(t, estimated.Estimated)
+	assert.Equal(t, "length", estimated.FinishReason)
+	assert.Equal(t, estimated.PromptTokens+estimated.CompletionTokens, estimated.TotalTokens)
+
+	assert.Equal(t, "gpt-4", Usage{TotalTokens: 1}.OrEstimate("gpt-4", "", "").Model)
+	assert.Equal(t, "gpt-4-0613", Usage{TotalTokens: 1, Model: "gpt-4-0613"}.OrEstimate("gpt-4", "", "").Model)
+}
+
+func TestUsageAddModel(t *testing.T) {
+	t.Parallel()
+
+	a := Usage{TotalTokens: 1, Model: "gpt-4"}
+	assert.Equal(t, "gpt-4", a.Add(Usage{TotalTokens: 2, Model: "gpt-4"}).Model)
+	assert.Empty(t, a.Add(Usage{TotalTokens: 2, Model: "claude-2"}).Model)
+
+	g1, g2 := &Generation{}, &Generation{}
+	SetUsage(g1, Usage{TotalTokens: 1, Model: "gpt-4", FinishReason: "stop"})
+	SetUsage(g2, Usage{TotalTokens: 2, Model: "gpt-4"})
+	assert.Equal(t, Usage{TotalTokens: 3, Model: "gpt-4"}, TotalUsage([]*Generation{g1, g2}))
+}
+
+func TestGetUsage(t *testing.T) {
+	t.Parallel()
+
+	g := &Generation{}
+	_, ok := GetUsage(g)
+	assert.False(t, ok)
+
+	SetUsage(g, Usage{PromptTokens: 1, CompletionTokens: 2, TotalTokens: 3})
+	u, ok := GetUsage(g)
+	require.True(t, ok)
+	assert.Equal(t, 3, u.TotalTokens)
+
+	// Usage survives a round trip through JSON, e.g. in a cache.
+	b, err := json.Marshal(g)
+	require.NoError(t, err)
+	var decoded Generation
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	u, ok = GetUsage(&decoded)
+	require.True(t, ok)
+	assert.Equal(t, 3, u.TotalTokens)
+}
+
+type usageLLM struct{}
+
+func (usageLLM) Call(context.Context, string, ...CallOption) (string, error) { return "", nil }
+
+func (usageLLM) Generate(_ context.Context, prompts []string, _ ...CallOption) ([]*Generation, error) {
+	generations := make([]*Generation, 0, len(prompts))
+	for range prompts {
+		g := &Generation{}
+		SetUsage(g, Usage{PromptTokens: 2, CompletionTokens: 1, TotalTokens: 3})
+		generations = append(generations, g)
+	}
+	return generations, nil
+}
+
+func TestGeneratePromptTotalUsage(t *testing.T) {
+	t.Parallel()
+
+	result, err := GeneratePrompt(context.Background(), usageLLM{}, []schema.PromptValue{
+		stringPromptValue("a"), stringPromptValue("b"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Usage{PromptTokens: 4, CompletionTokens: 2, TotalTokens: 6}, result.LLMOutput[UsageKey])
+}
+
+type stringPromptValue string
+
+func (v stringPromptValue) String() string { return string(v) }
+
+func (v stringPromptValue) Messages() []schema.ChatMessage {
+	return []schema.ChatMessage{schema.HumanChatMessage{Content: string(v)}}
+}
@@ -2,14 +2,20 @@ package vertexaiclient
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/http"
 	"runtime"
+	"sync"
 
 	aiplatform "cloud.google.com/go/aiplatform/apiv1"
 	"cloud.google.com/go/aiplatform/apiv1/aiplatformpb"
+	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/schema"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -45,6 +51,14 @@ const (
 type PaLMClient struct {
 	client    *aiplatform.PredictionClient
 	projectID string
+
+	// The streaming API is not part of the gRPC client, streaming requests are
+	// sent to the REST API with an HTTP client created on first use.
+	clientOptions  []option.ClientOption
+	httpClient     *http.Client
+	httpClientErr  error
+	httpClientOnce sync.Once
+	baseURL        string
 }
 
 // New returns a new Vertex AI based PaLM API client.
@@ -65,8 +79,10 @@ func New(projectID string, opts ...option.ClientOption) (*PaLMClient, error) {
 		return nil, err
 	}
 	return &PaLMClient{
-		client:    client,
-		projectID: projectID,
+		client:        client,
+		projectID:     projectID,
+		clientOptions: opts,
+		baseURL:       defaultStreamingBaseURL,
 	}, nil
 }
 
@@ -81,6 +97,10 @@ type CompletionRequest struct {
 	TopP          int      `json:"top_p,omitempty"`
 	TopK          int      `json:"top_k,omitempty"`
 	StopSequences []string `json:"stop_sequences"`
+
+	// StreamingFunc is a function to be called for each chunk of a streaming response.
+	// Return an error to stop streaming early.
+	StreamingFunc func(ctx context.Context, chunk []byte) error `json:"-"`
 }
 
 // Completion is a completion.
@@ -97,6 +117,9 @@ func (c *PaLMClient) CreateCompletion(ctx context.Context, r *CompletionRequest)
 		"top_k":           r.TopK,
 		"stopSequences":   convertArray(r.StopSequences),
 	}
+	if r.StreamingFunc != nil {
+		return c.streamCompletions(ctx, r, params)
+	}
 	predictions, err := c.batchPredict(ctx, TextModelName, r.Prompts, params)
 	if err != nil {
 		return nil, err
@@ -164,6 +187,10 @@ type ChatRequest struct {
 	TopP           int            `json:"top_p,omitempty"`
 	TopK           int            `json:"top_k,omitempty"`
 	CandidateCount int            `json:"candidate_count,omitempty"`
+
+	// StreamingFunc is a function to be called for each chunk of a streaming response.
+	// Return an error to stop streaming early.
+	StreamingFunc func(ctx context.Context, chunk []byte) error `json:"-"`
 }
 
 // ChatMessage is a message in a chat.
@@ -172,6 +199,17 @@ type ChatMessage struct {
 	Content string `json:"content"`
 	// The name of the author of this message. user or bot
 	Author string `json:"author,omitempty"`
+	// The images of the message, for the models accepting them.
+	Images []Image `json:"images,omitempty"`
+}
+
+// Image is an image given inline or by its Cloud Storage URI.
+type Image struct {
+	MIMEType string `json:"mimeType,omitempty"`
+	// Data is the content of an inline image.
+	Data []byte `json:"bytesBase64Encoded,omitempty"`
+	// GCSURI is the gs:// URI of an image stored in Cloud Storage.
+	GCSURI string `json:"gcsUri,omitempty"`
 }
 
 // Statically assert that the types implement the interface.
@@ -199,6 +237,9 @@ type ChatResponse struct {
 
 // CreateChat creates chat request.
 func (c *PaLMClient) CreateChat(ctx context.Context, r *ChatRequest) (*ChatResponse, error) {
+	if r.StreamingFunc != nil {
+		return c.streamChat(ctx, r)
+	}
 	responses, err := c.chat(ctx, r)
 	if err != nil {
 		return nil, err
@@ -232,6 +273,10 @@ func (c *PaLMClient) CreateChat(ctx context.Context, r *ChatRequest) (*ChatRespo
 }
 
 func mergeParams(defaultParams, params map[string]interface{}) *structpb.Struct {
+	return convertToOutputStruct(defaultParams, mergeParamValues(params))
+}
+
+func mergeParamValues(params map[string]interface{}) map[string]interface{} {
 	mergedParams := cloneDefaultParameters()
 	for paramKey, paramValue := range params {
 		switch value := paramValue.(type) {
@@ -249,7 +294,7 @@ func mergeParams(defaultParams, params map[string]interface{}) *structpb.Struct
 			mergedParams[paramKey] = value
 		}
 	}
-	return convertToOutputStruct(defaultParams, mergedParams)
+	return mergedParams
 }
 
 func convertToOutputStruct(defaultParams map[string]interface{}, mergedParams map[string]interface{}) *structpb.Struct {
@@ -292,7 +337,7 @@ func (c *PaLMClient) batchPredict(ctx context.Context, model string, prompts []s
 		Parameters: structpb.NewStructValue(mergedParams),
 	})
 	if err != nil {
-		return nil, err
+		return nil, predictError(err)
 	}
 	if len(resp.Predictions) == 0 {
 		return nil, ErrEmptyResponse
@@ -301,24 +346,8 @@ func (c *PaLMClient) batchPredict(ctx context.Context, model string, prompts []s
 }
 
 func (c *PaLMClient) chat(ctx context.Context, r *ChatRequest) ([]*structpb.Value, error) {
-	params := map[string]interface{}{
-		"temperature": r.Temperature,
-		"top_p":       r.TopP,
-		"top_k":       r.TopK,
-	}
-	mergedParams := mergeParams(defaultParameters, params)
-	messages := []interface{}{}
-	for _, msg := range r.Messages {
-		msgMap := map[string]interface{}{
-			"author":  msg.Author,
-			"content": msg.Content,
-		}
-		messages = append(messages, msgMap)
-	}
-	instance, err := structpb.NewStruct(map[string]interface{}{
-		"context":  r.Context,
-		"messages": messages,
-	})
+	mergedParams := mergeParams(defaultParameters, chatParams(r))
+	instance, err := structpb.NewStruct(chatInstance(r))
 	if err != nil {
 		return nil, err
 	}
@@ -331,7 +360,7 @@ func (c *PaLMClient) chat(ctx context.Context, r *ChatRequest) ([]*structpb.Valu
 		Parameters: structpb.NewStructValue(mergedParams),
 	})
 	if err != nil {
-		return nil, err
+		return nil, predictError(err)
 	}
 	if len(resp.Predictions) == 0 {
 		return nil, ErrEmptyResponse
@@ -339,6 +368,88 @@ func (c *PaLMClient) chat(ctx context.Context, r *ChatRequest) ([]*structpb.Valu
 	return resp.Predictions, nil
 }
 
+// grpcHTTPStatus maps the gRPC codes of the errors of the API to the HTTP
+// status codes of the same errors of its REST API.
+var grpcHTTPStatus = map[codes.Code]int{ //nolint:gochecknoglobals
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.Aborted:            http.StatusConflict,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+}
+
+// predictError returns the error of a prediction as a *llms.StatusError if
+// it is a gRPC status error, so that it is classified as the errors of the
+// HTTP APIs.
+func predictError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	code, ok := grpcHTTPStatus[st.Code()]
+	if !ok {
+		return err
+	}
+	return &llms.StatusError{
+		StatusCode: code,
+		Message:    st.Message(),
+		Err:        err,
+	}
+}
+
+func chatParams(r *ChatRequest) map[string]interface{} {
+	return map[string]interface{}{
+		"temperature": r.Temperature,
+		"top_p":       r.TopP,
+		"top_k":       r.TopK,
+	}
+}
+
+func chatInstance(r *ChatRequest) map[string]interface{} {
+	messages := []interface{}{}
+	for _, msg := range r.Messages {
+		msgMap := map[string]interface{}{
+			"author":  msg.Author,
+			"content": msg.Content,
+		}
+		if len(msg.Images) > 0 {
+			msgMap["images"] = imagesToValues(msg.Images)
+		}
+		messages = append(messages, msgMap)
+	}
+	return map[string]interface{}{
+		"context":  r.Context,
+		"messages": messages,
+	}
+}
+
+func imagesToValues(images []Image) []interface{} {
+	values := make([]interface{}, 0, len(images))
+	for _, image := range images {
+		value := map[string]interface{}{}
+		if image.MIMEType != "" {
+			value["mimeType"] = image.MIMEType
+		}
+		if image.GCSURI != "" {
+			value["gcsUri"] = image.GCSURI
+		} else {
+			value["bytesBase64Encoded"] = base64.StdEncoding.EncodeToString(image.Data)
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
 func (c *PaLMClient) projectLocationPublisherModelPath(projectID, location, publisher, model string) string {
 	return fmt.Sprintf("projects/%s/locations/%s/publishers/%s/models/%s", projectID, location, publisher, model)
 }
@@ -0,0 +1,30 @@
+package vertexaiclient
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPredictError(t *testing.T) {
+	t.Parallel()
+
+	err := predictError(status.Error(codes.ResourceExhausted, "quota exceeded"))
+	var statusErr *llms.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusTooManyRequests, statusErr.StatusCode)
+	assert.Equal(t, "quota exceeded", statusErr.Message)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, llms.ErrorClassRateLimited, llms.ClassifyError(err))
+
+	assert.Equal(t, llms.ErrorClassClient, llms.ClassifyError(predictError(status.Error(codes.InvalidArgument, "bad"))))
+
+	errOther := errors.New("other")
+	assert.Equal(t, errOther, predictError(errOther))
+}
@@ -0,0 +1,242 @@
+package vertexaiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+)
+
+const (
+	defaultStreamingBaseURL = "https://us-central1-aiplatform.googleapis.com/v1"
+	cloudPlatformScope      = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// ErrStream is returned when the stream of a response reports an error.
+var ErrStream = errors.New("stream error")
+
+// tensor is the JSON encoding of the Tensor values of the streaming API.
+type tensor struct {
+	StructVal map[string]*tensor `json:"structVal,omitempty"`
+	ListVal   []*tensor          `json:"listVal,omitempty"`
+	StringVal []string           `json:"stringVal,omitempty"`
+	FloatVal  []float64          `json:"floatVal,omitempty"`
+	IntVal    []int64            `json:"intVal,omitempty"`
+	BoolVal   []bool             `json:"boolVal,omitempty"`
+}
+
+type streamingPredictRequest struct {
+	Inputs     []*tensor `json:"inputs"`
+	Parameters *tensor   `json:"parameters"`
+}
+
+type streamingPredictResponse struct {
+	Outputs []*tensor `json:"outputs"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// toTensor converts a value made of maps, slices and scalars to a tensor.
+func toTensor(v interface{}) *tensor {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		t := &tensor{StructVal: make(map[string]*tensor, len(v))}
+		for key, value := range v {
+			t.StructVal[key] = toTensor(value)
+		}
+		return t
+	case []interface{}:
+		t := &tensor{ListVal: make([]*tensor, 0, len(v))}
+		for _, value := range v {
+			t.ListVal = append(t.ListVal, toTensor(value))
+		}
+		return t
+	case string:
+		return &tensor{StringVal: []string{v}}
+	case float64:
+		return &tensor{FloatVal: []float64{v}}
+	case int:
+		return &tensor{IntVal: []int64{int64(v)}}
+	case bool:
+		return &tensor{BoolVal: []bool{v}}
+	}
+	return &tensor{}
+}
+
+// fromTensor converts a tensor back to maps, slices and scalars.
+func fromTensor(t *tensor) interface{} {
+	switch {
+	case t == nil:
+		return nil
+	case t.StructVal != nil:
+		m := make(map[string]interface{}, len(t.StructVal))
+		for key, value := range t.StructVal {
+			m[key] = fromTensor(value)
+		}
+		return m
+	case t.ListVal != nil:
+		l := make([]interface{}, 0, len(t.ListVal))
+		for _, value := range t.ListVal {
+			l = append(l, fromTensor(value))
+		}
+		return l
+	case len(t.StringVal) > 0:
+		return strings.Join(t.StringVal, "")
+	case len(t.FloatVal) > 0:
+		return t.FloatVal[0]
+	case len(t.IntVal) > 0:
+		return t.IntVal[0]
+	case len(t.BoolVal) > 0:
+		return t.BoolVal[0]
+	}
+	return nil
+}
+
+func (c *PaLMClient) streamCompletions(ctx context.Context, r *CompletionRequest, params map[string]interface{}) ([]*Completion, error) { // nolint:lll
+	completions := make([]*Completion, 0, len(r.Prompts))
+	for _, prompt := range r.Prompts {
+		completion := &Completion{}
+		instance := map[string]interface{}{"content": prompt}
+		err := c.streamPredict(ctx, TextModelName, instance, params, func(output map[string]interface{}) error {
+			chunk, ok := output["content"].(string)
+			if !ok {
+				return fmt.Errorf("%w: %v", ErrMissingValue, "content")
+			}
+			completion.Text += chunk
+			return streamChunk(ctx, r.StreamingFunc, chunk)
+		})
+		if err != nil {
+			return nil, err
+		}
+		completions = append(completions, completion)
+	}
+	return completions, nil
+}
+
+func (c *PaLMClient) streamChat(ctx context.Context, r *ChatRequest) (*ChatResponse, error) {
+	var candidate *ChatMessage
+	err := c.streamPredict(ctx, ChatModelName, chatInstance(r), chatParams(r), func(output map[string]interface{}) error {
+		candidates, ok := output["candidates"].([]interface{})
+		if !ok {
+			return fmt.Errorf("%w: %v", ErrMissingValue, "candidates")
+		}
+		if len(candidates) == 0 {
+			return nil
+		}
+		value, ok := candidates[0].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%w: %v is not a map[string]interface{}", ErrInvalidValue, "candidate")
+		}
+		chunk, _ := value["content"].(string)
+		if candidate == nil {
+			author, _ := value["author"].(string)
+			candidate = &ChatMessage{Author: author}
+		}
+		candidate.Content += chunk
+		return streamChunk(ctx, r.StreamingFunc, chunk)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if candidate == nil {
+		return nil, ErrEmptyResponse
+	}
+	return &ChatResponse{Candidates: []ChatMessage{*candidate}}, nil
+}
+
+func streamChunk(ctx context.Context, streamingFunc func(ctx context.Context, chunk []byte) error, chunk string) error {
+	if err := streamingFunc(ctx, []byte(chunk)); err != nil {
+		return fmt.Errorf("streaming func returned an error: %w", err)
+	}
+	return nil
+}
+
+// streamPredict sends a streaming prediction request for the instance and
+// calls fn with each output of the stream.
+func (c *PaLMClient) streamPredict(
+	ctx context.Context,
+	model string,
+	instance, params map[string]interface{},
+	fn func(output map[string]interface{}) error,
+) error {
+	httpClient, err := c.streamingHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	payloadBytes, err := json.Marshal(streamingPredictRequest{
+		Inputs:     []*tensor{toTensor(instance)},
+		Parameters: toTensor(mergeParamValues(params)),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+	url := fmt.Sprintf("%s/%s:serverStreamingPredict",
+		c.baseURL, c.projectLocationPublisherModelPath(c.projectID, "us-central1", "google", model))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &llms.StatusError{
+			StatusCode: resp.StatusCode,
+			Message:    string(body),
+			Header:     resp.Header,
+		}
+	}
+
+	// The responses are streamed as the elements of a JSON array.
+	dec := json.NewDecoder(resp.Body)
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("parse stream: %w", err)
+	}
+	for dec.More() {
+		var response streamingPredictResponse
+		if err := dec.Decode(&response); err != nil {
+			return fmt.Errorf("parse stream: %w", err)
+		}
+		if response.Error != nil {
+			return fmt.Errorf("%w: %d: %s", ErrStream, response.Error.Code, response.Error.Message)
+		}
+		for _, output := range response.Outputs {
+			value, ok := fromTensor(output).(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%w: %v is not a map[string]interface{}", ErrInvalidValue, "output")
+			}
+			if err := fn(value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *PaLMClient) streamingHTTPClient() (*http.Client, error) {
+	c.httpClientOnce.Do(func() {
+		if c.httpClient != nil {
+			return
+		}
+		opts := append([]option.ClientOption{option.WithScopes(cloudPlatformScope)}, c.clientOptions...)
+		c.httpClient, _, c.httpClientErr = htransport.NewClient(context.Background(), opts...)
+	})
+	return c.httpClient, c.httpClientErr
+}
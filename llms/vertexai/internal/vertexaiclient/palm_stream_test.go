@@ -0,0 +1,89 @@
+package vertexaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T) *PaLMClient {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req streamingPredictRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.Inputs, 1)
+		assert.Equal(t, []float64{0.2}, req.Parameters.StructVal["temperature"].FloatVal)
+
+		switch r.URL.Path {
+		case "/projects/p/locations/us-central1/publishers/google/models/text-bison:serverStreamingPredict":
+			assert.Equal(t, []string{"Hi"}, req.Inputs[0].StructVal["content"].StringVal)
+			_, _ = w.Write([]byte(`[{"outputs":[{"structVal":{"content":{"stringVal":["Hello"]}}}]},
+{"outputs":[{"structVal":{"content":{"stringVal":[" world"]}}}]}]`))
+		case "/projects/p/locations/us-central1/publishers/google/models/chat-bison:serverStreamingPredict":
+			messages := req.Inputs[0].StructVal["messages"].ListVal
+			require.Len(t, messages, 1)
+			assert.Equal(t, []string{"Hi"}, messages[0].StructVal["content"].StringVal)
+			_, _ = w.Write([]byte(`[{"outputs":[{"structVal":{"candidates":{"listVal":[{"structVal":{"author":{"stringVal":["1"]},"content":{"stringVal":["Hello"]}}}]}}}]},
+{"outputs":[{"structVal":{"candidates":{"listVal":[{"structVal":{"author":{"stringVal":["1"]},"content":{"stringVal":[" there"]}}}]}}}]}]`)) // nolint:lll
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return &PaLMClient{
+		projectID:  "p",
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+	}
+}
+
+func TestStreamCompletion(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(t)
+
+	var chunks []string
+	completions, err := client.CreateCompletion(context.Background(), &CompletionRequest{
+		Prompts: []string{"Hi"},
+		StreamingFunc: func(_ context.Context, chunk []byte) error {
+			chunks = append(chunks, string(chunk))
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*Completion{{Text: "Hello world"}}, completions)
+	assert.Equal(t, []string{"Hello", " world"}, chunks)
+
+	errStop := errors.New("stop")
+	_, err = client.CreateCompletion(context.Background(), &CompletionRequest{
+		Prompts: []string{"Hi"},
+		StreamingFunc: func(context.Context, []byte) error {
+			return errStop
+		},
+	})
+	require.ErrorIs(t, err, errStop)
+}
+
+func TestStreamChat(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(t)
+
+	var chunks []string
+	resp, err := client.CreateChat(context.Background(), &ChatRequest{
+		Messages: []*ChatMessage{{Author: "user", Content: "Hi"}},
+		StreamingFunc: func(_ context.Context, chunk []byte) error {
+			chunks = append(chunks, string(chunk))
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []ChatMessage{{Author: "1", Content: "Hello there"}}, resp.Candidates)
+	assert.Equal(t, []string{"Hello", " there"}, chunks)
+}
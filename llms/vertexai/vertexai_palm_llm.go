@@ -15,6 +15,7 @@ var (
 	ErrMissingProjectID         = errors.New("missing the GCP Project ID, set it in the GOOGLE_CLOUD_PROJECT environment variable") //nolint:lll
 	ErrUnexpectedResponseLength = errors.New("unexpected length of response")
 	ErrNotImplemented           = errors.New("not implemented")
+	ErrUnsupportedImageURL      = errors.New("image URLs must be data URLs or Cloud Storage URIs")
 )
 
 type LLM struct {
@@ -48,25 +49,37 @@ func (o *LLM) Generate(ctx context.Context, prompts []string, options ...llms.Ca
 	for _, opt := range options {
 		opt(&opts)
 	}
+	formatted := make([]string, 0, len(prompts))
+	for _, prompt := range prompts {
+		formatted = append(formatted, llms.PromptWithResponseFormat(prompt, opts.ResponseFormat))
+	}
 	results, err := o.client.CreateCompletion(ctx, &vertexaiclient.CompletionRequest{
-		Prompts:       prompts,
+		Prompts:       formatted,
 		MaxTokens:     opts.MaxTokens,
 		Temperature:   opts.Temperature,
 		StopSequences: opts.StopWords,
+		StreamingFunc: opts.StreamingFunc,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	generations := []*llms.Generation{}
-	for _, r := range results {
+	for i, r := range results {
+		r.Text, err = llms.ParseResponse(opts.ResponseFormat, r.Text)
+		if err != nil {
+			return nil, err
+		}
 		generations = append(generations, &llms.Generation{
 			Text: r.Text,
+			GenerationInfo: map[string]any{
+				llms.UsageKey: llms.EstimateUsage(vertexaiclient.TextModelName, formatted[i], r.Text),
+			},
 		})
 	}
 
 	if o.CallbacksHandler != nil {
-		o.CallbacksHandler.HandleLLMEnd(ctx, llms.LLMResult{Generations: [][]*llms.Generation{generations}})
+		o.CallbacksHandler.HandleLLMEnd(ctx, llms.NewLLMResult(generations))
 	}
 	return generations, nil
 }
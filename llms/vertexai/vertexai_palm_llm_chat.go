@@ -2,6 +2,8 @@ package vertexai
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/vertexai/internal/vertexaiclient"
@@ -42,22 +44,24 @@ func (o *Chat) Generate(ctx context.Context, messageSets [][]schema.ChatMessage,
 	for _, opt := range options {
 		opt(&opts)
 	}
-	if opts.StreamingFunc != nil {
-		return nil, ErrNotImplemented
-	}
 
 	generations := make([]*llms.Generation, 0, len(messageSets))
 	for _, messages := range messageSets {
+		messages = llms.MessagesWithResponseFormat(messages, opts.ResponseFormat)
 		chatContext := parseContext(messages)
 		if len(chatContext) > 0 {
 			// remove system context from messages
 			messages = messages[1:]
 		}
-		msgs := toClientChatMessage(messages)
+		msgs, err := toClientChatMessage(messages)
+		if err != nil {
+			return nil, err
+		}
 		result, err := o.client.CreateChat(ctx, &vertexaiclient.ChatRequest{
-			Temperature: opts.Temperature,
-			Messages:    msgs,
-			Context:     chatContext,
+			Temperature:   opts.Temperature,
+			Messages:      msgs,
+			Context:       chatContext,
+			StreamingFunc: opts.StreamingFunc,
 		})
 		if err != nil {
 			return nil, err
@@ -65,11 +69,20 @@ func (o *Chat) Generate(ctx context.Context, messageSets [][]schema.ChatMessage,
 		if len(result.Candidates) == 0 {
 			return nil, ErrEmptyResponse
 		}
+		text, err := llms.ParseResponse(opts.ResponseFormat, result.Candidates[0].Content)
+		if err != nil {
+			return nil, err
+		}
 		generations = append(generations, &llms.Generation{
 			Message: &schema.AIChatMessage{
-				Content: result.Candidates[0].Content,
+				Content: text,
+			},
+			Text: text,
+			GenerationInfo: map[string]any{
+				llms.UsageKey: llms.Usage{}.OrEstimateChat(
+					vertexaiclient.ChatModelName, messages, text,
+				),
 			},
-			Text: result.Candidates[0].Content,
 		})
 	}
 
@@ -84,7 +97,7 @@ func (o *Chat) GetNumTokens(text string) int {
 	return llms.CountTokens(vertexaiclient.TextModelName, text)
 }
 
-func toClientChatMessage(messages []schema.ChatMessage) []*vertexaiclient.ChatMessage {
+func toClientChatMessage(messages []schema.ChatMessage) ([]*vertexaiclient.ChatMessage, error) {
 	msgs := make([]*vertexaiclient.ChatMessage, len(messages))
 
 	for i, m := range messages {
@@ -104,13 +117,48 @@ func toClientChatMessage(messages []schema.ChatMessage) []*vertexaiclient.ChatMe
 			msg.Author = userAuthor
 		case schema.ChatMessageTypeFunction:
 			msg.Author = userAuthor
+		case schema.ChatMessageTypeTool:
+			msg.Author = userAuthor
 		}
 		if n, ok := m.(schema.Named); ok {
 			msg.Author = n.GetName()
 		}
+		if mp, ok := m.(schema.MultiPart); ok {
+			images, err := partsToClientImages(mp.GetParts())
+			if err != nil {
+				return nil, err
+			}
+			msg.Images = images
+		}
 		msgs[i] = msg
 	}
-	return msgs
+	return msgs, nil
+}
+
+// partsToClientImages returns the images of the parts. The images must be
+// inline, given by data URLs or Cloud Storage URIs.
+func partsToClientImages(parts []schema.ContentPart) ([]vertexaiclient.Image, error) {
+	var images []vertexaiclient.Image
+	for _, part := range parts {
+		switch p := part.(type) {
+		case schema.BinaryContent:
+			images = append(images, vertexaiclient.Image{MIMEType: p.MIMEType, Data: p.Data})
+		case schema.ImageURLContent:
+			switch {
+			case strings.HasPrefix(p.URL, "gs://"):
+				images = append(images, vertexaiclient.Image{GCSURI: p.URL})
+			case strings.HasPrefix(p.URL, "data:"):
+				content, err := schema.ParseDataURL(p.URL)
+				if err != nil {
+					return nil, err
+				}
+				images = append(images, vertexaiclient.Image{MIMEType: content.MIMEType, Data: content.Data})
+			default:
+				return nil, fmt.Errorf("%w: %s", ErrUnsupportedImageURL, p.URL)
+			}
+		}
+	}
+	return images, nil
 }
 
 func parseContext(messages []schema.ChatMessage) string {
@@ -43,3 +43,24 @@ func TestChatPromptTemplate(t *testing.T) {
 	})
 	assert.Error(t, err)
 }
+
+func TestChatPromptTemplateImages(t *testing.T) {
+	t.Parallel()
+
+	human := NewHumanMessagePromptTemplate("What is in this {{.kind}}?", []string{"kind"})
+	human.Images = []ImagePromptTemplate{NewImagePromptTemplate("{{.image}}", []string{"image"})}
+	template := NewChatPromptTemplate([]MessageFormatter{human})
+	assert.ElementsMatch(t, []string{"kind", "image"}, template.GetInputVariables())
+
+	messages, err := template.FormatMessages(map[string]any{
+		"kind":  "picture",
+		"image": "https://example.com/cat.png",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []schema.ChatMessage{
+		schema.HumanChatMessage{
+			Content: "What is in this picture?",
+			Parts:   []schema.ContentPart{schema.ImageURLContent{URL: "https://example.com/cat.png"}},
+		},
+	}, messages)
+}
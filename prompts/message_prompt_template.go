@@ -55,6 +55,9 @@ func NewAIMessagePromptTemplate(template string, inputVariables []string) AIMess
 // HumanMessagePromptTemplate is a message formatter that returns a human message.
 type HumanMessagePromptTemplate struct {
 	Prompt PromptTemplate
+
+	// Images are the templates of the images following the text of the message.
+	Images []ImagePromptTemplate
 }
 
 var _ MessageFormatter = HumanMessagePromptTemplate{}
@@ -62,12 +65,30 @@ var _ MessageFormatter = HumanMessagePromptTemplate{}
 // FormatMessages formats the message with the values given.
 func (p HumanMessagePromptTemplate) FormatMessages(values map[string]any) ([]schema.ChatMessage, error) {
 	text, err := p.Prompt.Format(values)
-	return []schema.ChatMessage{schema.HumanChatMessage{Content: text}}, err
+	if err != nil {
+		return []schema.ChatMessage{schema.HumanChatMessage{Content: text}}, err
+	}
+	msg := schema.HumanChatMessage{Content: text}
+	for _, image := range p.Images {
+		part, err := image.FormatPart(values)
+		if err != nil {
+			return nil, err
+		}
+		msg.Parts = append(msg.Parts, part)
+	}
+	return []schema.ChatMessage{msg}, nil
 }
 
 // GetInputVariables returns the input variables the prompt expects.
 func (p HumanMessagePromptTemplate) GetInputVariables() []string {
-	return p.Prompt.InputVariables
+	if len(p.Images) == 0 {
+		return p.Prompt.InputVariables
+	}
+	inputVariables := append([]string{}, p.Prompt.InputVariables...)
+	for _, image := range p.Images {
+		inputVariables = append(inputVariables, image.URL.InputVariables...)
+	}
+	return inputVariables
 }
 
 // NewHumanMessagePromptTemplate creates a new human message prompt template.
@@ -77,6 +98,31 @@ func NewHumanMessagePromptTemplate(template string, inputVariables []string) Hum
 	}
 }
 
+// ImagePromptTemplate is a template of an image part of a message.
+type ImagePromptTemplate struct {
+	// URL is the template of the URL of the image, which may be a data URL.
+	URL PromptTemplate
+	// Detail is the level of detail of the image for the models supporting it.
+	Detail string
+}
+
+// FormatPart formats the URL of the image with the values given.
+func (p ImagePromptTemplate) FormatPart(values map[string]any) (schema.ContentPart, error) { //nolint:ireturn
+	url, err := p.URL.Format(values)
+	if err != nil {
+		return nil, err
+	}
+	return schema.ImageURLContent{URL: url, Detail: p.Detail}, nil
+}
+
+// NewImagePromptTemplate creates a new image prompt template. Binary images
+// can be passed as values with schema.BinaryContent.DataURL.
+func NewImagePromptTemplate(urlTemplate string, inputVariables []string) ImagePromptTemplate {
+	return ImagePromptTemplate{
+		URL: NewPromptTemplate(urlTemplate, inputVariables),
+	}
+}
+
 // GenericMessagePromptTemplate is a message formatter that returns message with the specified speaker.
 type GenericMessagePromptTemplate struct {
 	Prompt PromptTemplate
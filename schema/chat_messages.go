@@ -24,6 +24,8 @@ const (
 	ChatMessageTypeGeneric ChatMessageType = "generic"
 	// ChatMessageTypeFunction is a message sent by a function.
 	ChatMessageTypeFunction ChatMessageType = "function"
+	// ChatMessageTypeTool is a message sent by a tool.
+	ChatMessageTypeTool ChatMessageType = "tool"
 )
 
 // ChatMessage represents a message in a chat.
@@ -46,6 +48,9 @@ var (
 	_ ChatMessage = SystemChatMessage{}
 	_ ChatMessage = GenericChatMessage{}
 	_ ChatMessage = FunctionChatMessage{}
+	_ ChatMessage = ToolChatMessage{}
+
+	_ MultiPart = HumanChatMessage{}
 )
 
 // AIChatMessage is a message sent by an AI.
@@ -55,6 +60,10 @@ type AIChatMessage struct {
 
 	// FunctionCall represents the model choosing to call a function.
 	FunctionCall *FunctionCall `json:"function_call,omitempty"`
+
+	// ToolCalls represents the model choosing to call tools. The model may
+	// call several tools in one message.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 }
 
 func (m AIChatMessage) GetType() ChatMessageType { return ChatMessageTypeAI }
@@ -63,10 +72,32 @@ func (m AIChatMessage) GetContent() string       { return m.Content }
 // HumanChatMessage is a message sent by a human.
 type HumanChatMessage struct {
 	Content string
+
+	// Parts are content parts following Content, such as images for the
+	// models accepting them.
+	Parts []ContentPart `json:"parts,omitempty"`
 }
 
 func (m HumanChatMessage) GetType() ChatMessageType { return ChatMessageTypeHuman }
-func (m HumanChatMessage) GetContent() string       { return m.Content }
+
+// GetContent returns the content of the message followed by the text of its
+// text parts.
+func (m HumanChatMessage) GetContent() string {
+	if len(m.Parts) == 0 {
+		return m.Content
+	}
+	return textOfParts(m.Content, m.Parts)
+}
+
+// GetParts returns the content of the message as a text part followed by the
+// parts of the message.
+func (m HumanChatMessage) GetParts() []ContentPart {
+	parts := make([]ContentPart, 0, len(m.Parts)+1)
+	if m.Content != "" {
+		parts = append(parts, TextContent{Text: m.Content})
+	}
+	return append(parts, m.Parts...)
+}
 
 // SystemChatMessage is a chat message representing information that should be instructions to the AI system.
 type SystemChatMessage struct {
@@ -103,6 +134,26 @@ func (m FunctionChatMessage) GetType() ChatMessageType { return ChatMessageTypeF
 func (m FunctionChatMessage) GetContent() string       { return m.Content }
 func (m FunctionChatMessage) GetName() string          { return m.Name }
 
+// ToolCall is a call to a tool made by the model.
+type ToolCall struct {
+	// ID is the ID of the tool call, which the result of the call refers to.
+	ID string `json:"id"`
+	// Type is the type of the tool. Only "function" is supported.
+	Type string `json:"type"`
+	// FunctionCall is the function called by the model.
+	FunctionCall *FunctionCall `json:"function,omitempty"`
+}
+
+// ToolChatMessage is a chat message representing the result of a tool call.
+type ToolChatMessage struct {
+	// ID is the ID of the tool call this message is the result of.
+	ID      string `json:"tool_call_id"`
+	Content string `json:"content"`
+}
+
+func (m ToolChatMessage) GetType() ChatMessageType { return ChatMessageTypeTool }
+func (m ToolChatMessage) GetContent() string       { return m.Content }
+
 // ChatGeneration is the output of a single chat generation.
 type ChatGeneration struct {
 	Generation
@@ -131,6 +182,13 @@ func GetBufferString(messages []ChatMessage, humanPrefix string, aiPrefix string
 			}
 			msg = fmt.Sprintf("%s %s", msg, string(j))
 		}
+		if m, ok := m.(AIChatMessage); ok && len(m.ToolCalls) > 0 {
+			j, err := json.Marshal(m.ToolCalls)
+			if err != nil {
+				return "", err
+			}
+			msg = fmt.Sprintf("%s %s", msg, string(j))
+		}
 		result = append(result, msg)
 	}
 	return strings.Join(result, "\n"), nil
@@ -153,6 +211,8 @@ func getMessageRole(m ChatMessage, humanPrefix, aiPrefix string) (string, error)
 		role = cgm.Role
 	case ChatMessageTypeFunction:
 		role = "Function"
+	case ChatMessageTypeTool:
+		role = "Tool"
 	default:
 		return "", ErrUnexpectedChatMessageType
 	}
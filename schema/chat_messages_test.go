@@ -37,6 +37,21 @@ func TestGetBufferString(t *testing.T) {
 			expected:    "Human: Hello, how are you?\nAI: I'm doing great!\nSystem: Please be polite.\nModerator: Keep the conversation on topic.", //nolint:lll
 			expectError: false,
 		},
+		{
+			name: "Tool calls",
+			messages: []schema.ChatMessage{
+				schema.AIChatMessage{ToolCalls: []schema.ToolCall{{
+					ID:           "call_1",
+					Type:         "function",
+					FunctionCall: &schema.FunctionCall{Name: "get_weather", Arguments: `{"location":"Paris"}`},
+				}}},
+				schema.ToolChatMessage{ID: "call_1", Content: "sunny"},
+			},
+			humanPrefix: "Human",
+			aiPrefix:    "AI",
+			expected:    `AI:  [{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"location\":\"Paris\"}"}}]` + "\nTool: sunny", //nolint:lll
+			expectError: false,
+		},
 		{
 			name: "Unsupported message type",
 			messages: []schema.ChatMessage{
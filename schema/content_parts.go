@@ -0,0 +1,100 @@
+package schema
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidDataURL is returned when a data URL cannot be parsed.
+var ErrInvalidDataURL = errors.New("invalid data URL")
+
+// ContentPart is a part of the content of a multimodal message. It is one of
+// TextContent, ImageURLContent or BinaryContent.
+type ContentPart interface {
+	isContentPart()
+}
+
+// Statically assert that the types implement the interface.
+var (
+	_ ContentPart = TextContent{}
+	_ ContentPart = ImageURLContent{}
+	_ ContentPart = BinaryContent{}
+)
+
+// TextContent is a text part of a message.
+type TextContent struct {
+	Text string `json:"text"`
+}
+
+func (TextContent) isContentPart() {}
+
+// ImageURLContent is an image part of a message referenced by its URL, which
+// may be a data URL.
+type ImageURLContent struct {
+	URL string `json:"url"`
+	// Detail is the level of detail of the image for the models supporting it:
+	// "low", "high" or "auto".
+	Detail string `json:"detail,omitempty"`
+}
+
+func (ImageURLContent) isContentPart() {}
+
+// BinaryContent is an inline part of a message, such as an image.
+type BinaryContent struct {
+	MIMEType string `json:"mime_type"`
+	Data     []byte `json:"data"`
+}
+
+func (BinaryContent) isContentPart() {}
+
+// DataURL returns the content as a base64 encoded data URL.
+func (c BinaryContent) DataURL() string {
+	return fmt.Sprintf("data:%s;base64,%s", c.MIMEType, base64.StdEncoding.EncodeToString(c.Data))
+}
+
+// ParseDataURL parses a base64 encoded data URL into a BinaryContent.
+func ParseDataURL(url string) (BinaryContent, error) {
+	header, data, ok := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+	if !ok || !strings.HasPrefix(url, "data:") || !strings.HasSuffix(header, ";base64") {
+		return BinaryContent{}, ErrInvalidDataURL
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return BinaryContent{}, fmt.Errorf("%w: %w", ErrInvalidDataURL, err)
+	}
+	return BinaryContent{
+		MIMEType: strings.TrimSuffix(header, ";base64"),
+		Data:     decoded,
+	}, nil
+}
+
+// MultiPart is an interface for messages made of content parts.
+type MultiPart interface {
+	GetParts() []ContentPart
+}
+
+// HasMedia reports whether the parts contain anything else than text.
+func HasMedia(parts []ContentPart) bool {
+	for _, part := range parts {
+		if _, ok := part.(TextContent); !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// textOfParts returns the text parts of the content joined by newlines.
+func textOfParts(content string, parts []ContentPart) string {
+	texts := make([]string, 0, len(parts)+1)
+	if content != "" {
+		texts = append(texts, content)
+	}
+	for _, part := range parts {
+		if text, ok := part.(TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
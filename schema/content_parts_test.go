@@ -0,0 +1,49 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/schema"
+)
+
+func TestHumanChatMessageParts(t *testing.T) {
+	t.Parallel()
+
+	msg := schema.HumanChatMessage{
+		Content: "What is in this image?",
+		Parts: []schema.ContentPart{
+			schema.ImageURLContent{URL: "https://example.com/cat.png"},
+			schema.TextContent{Text: "Answer briefly."},
+		},
+	}
+	assert.Equal(t, "What is in this image?\nAnswer briefly.", msg.GetContent())
+	assert.Equal(t, []schema.ContentPart{
+		schema.TextContent{Text: "What is in this image?"},
+		schema.ImageURLContent{URL: "https://example.com/cat.png"},
+		schema.TextContent{Text: "Answer briefly."},
+	}, msg.GetParts())
+	assert.True(t, schema.HasMedia(msg.GetParts()))
+
+	plain := schema.HumanChatMessage{Content: "Hello"}
+	assert.Equal(t, "Hello", plain.GetContent())
+	assert.False(t, schema.HasMedia(plain.GetParts()))
+}
+
+func TestDataURL(t *testing.T) {
+	t.Parallel()
+
+	content := schema.BinaryContent{MIMEType: "image/png", Data: []byte{0x89, 'P', 'N', 'G'}}
+	url := content.DataURL()
+	assert.Equal(t, "data:image/png;base64,iVBORw==", url)
+
+	parsed, err := schema.ParseDataURL(url)
+	require.NoError(t, err)
+	assert.Equal(t, content, parsed)
+
+	_, err = schema.ParseDataURL("https://example.com/cat.png")
+	require.ErrorIs(t, err, schema.ErrInvalidDataURL)
+	_, err = schema.ParseDataURL("data:text/plain,hello")
+	require.ErrorIs(t, err, schema.ErrInvalidDataURL)
+}
@@ -1,5 +1,7 @@
 package textsplitter
 
+import "github.com/tmc/langchaingo/llms"
+
 // Options is a struct that contains options for a text splitter.
 type Options struct {
 	ChunkSize         int
@@ -10,6 +12,7 @@ type Options struct {
 	AllowedSpecial    []string
 	DisallowedSpecial []string
 	SecondSplitter    TextSplitter
+	Tokenizer         llms.Tokenizer
 }
 
 // DefaultOptions returns the default options for all text splitter.
@@ -84,3 +87,11 @@ func WithSecondSplitter(secondSplitter TextSplitter) Option {
 		o.SecondSplitter = secondSplitter
 	}
 }
+
+// WithTokenizer sets the tokenizer of a token splitter, instead of the one of
+// the model.
+func WithTokenizer(tokenizer llms.Tokenizer) Option {
+	return func(o *Options) {
+		o.Tokenizer = tokenizer
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/pkoukk/tiktoken-go"
+	"github.com/tmc/langchaingo/llms"
 )
 
 const (
@@ -22,6 +23,9 @@ type TokenSplitter struct {
 	EncodingName      string
 	AllowedSpecial    []string
 	DisallowedSpecial []string
+	// Tokenizer is the tokenizer to use. If nil, the tokenizer registered for
+	// the model with llms.RegisterTokenizer is used, and tiktoken otherwise.
+	Tokenizer llms.Tokenizer
 }
 
 func NewTokenSplitter(opts ...Option) TokenSplitter {
@@ -37,6 +41,7 @@ func NewTokenSplitter(opts ...Option) TokenSplitter {
 		EncodingName:      options.EncodingName,
 		AllowedSpecial:    options.AllowedSpecial,
 		DisallowedSpecial: options.DisallowedSpecial,
+		Tokenizer:         options.Tokenizer,
 	}
 
 	return s
@@ -44,25 +49,41 @@ func NewTokenSplitter(opts ...Option) TokenSplitter {
 
 // SplitText splits a text into multiple text.
 func (s TokenSplitter) SplitText(text string) ([]string, error) {
-	// Get the tokenizer
-	var tk *tiktoken.Tiktoken
+	tk, err := s.tokenizer()
+	if err != nil {
+		return nil, err
+	}
+	texts := s.splitText(text, tk)
+
+	return texts, nil
+}
+
+// tokenizer returns the tokenizer of the splitter, the one registered for the
+// model or the tiktoken encoding.
+func (s TokenSplitter) tokenizer() (llms.Tokenizer, error) {
+	if s.Tokenizer != nil {
+		return s.Tokenizer, nil
+	}
+	if tk, ok := llms.LookupTokenizer(s.ModelName); ok {
+		return tk, nil
+	}
+
+	var e *tiktoken.Tiktoken
 	var err error
 	if s.EncodingName != "" {
-		tk, err = tiktoken.GetEncoding(s.EncodingName)
+		e, err = tiktoken.GetEncoding(s.EncodingName)
 	} else {
-		tk, err = tiktoken.EncodingForModel(s.ModelName)
+		e, err = tiktoken.EncodingForModel(s.ModelName)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("tiktoken.GetEncoding: %w", err)
 	}
-	texts := s.splitText(text, tk)
-
-	return texts, nil
+	return llms.NewTiktokenTokenizer(e).WithSpecialTokens(s.AllowedSpecial, s.DisallowedSpecial), nil
 }
 
-func (s TokenSplitter) splitText(text string, tk *tiktoken.Tiktoken) []string {
+func (s TokenSplitter) splitText(text string, tk llms.Tokenizer) []string {
 	splits := make([]string, 0)
-	inputIds := tk.Encode(text, s.AllowedSpecial, s.DisallowedSpecial)
+	inputIds := tk.Encode(text)
 
 	startIdx := 0
 	curIdx := len(inputIds)
@@ -1,9 +1,11 @@
 package textsplitter
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tmc/langchaingo/schema"
 )
 
@@ -85,3 +87,35 @@ Bye!
 		assert.Equal(t, tc.expectedDocs, docs)
 	}
 }
+
+// wordTokenizer is a tokenizer with a token per word.
+type wordTokenizer struct{}
+
+func (wordTokenizer) Encode(text string) []int {
+	ids := make([]int, 0)
+	for _, word := range strings.Fields(text) {
+		ids = append(ids, len(word))
+	}
+	return ids
+}
+
+func (wordTokenizer) Decode(ids []int) string {
+	words := make([]string, 0, len(ids))
+	for _, id := range ids {
+		words = append(words, strings.Repeat("x", id))
+	}
+	return strings.Join(words, " ")
+}
+
+func (t wordTokenizer) Count(text string) int {
+	return len(t.Encode(text))
+}
+
+func TestTokenSplitterTokenizer(t *testing.T) {
+	t.Parallel()
+
+	splitter := NewTokenSplitter(WithTokenizer(wordTokenizer{}), WithChunkSize(2), WithChunkOverlap(0))
+	texts, err := splitter.SplitText("a bb ccc dddd e")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x xx", "xxx xxxx", "x"}, texts)
+}